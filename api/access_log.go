@@ -0,0 +1,159 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+)
+
+type (
+	// AccessLogConfig contains parameters of the access log.
+	AccessLogConfig struct {
+		// SampleRatio is the share of successful requests to log,
+		// requests failed with 4xx and 5xx codes are always logged.
+		SampleRatio float64
+		// Redact is the list of fields to hide, see AccessLogRedactable.
+		Redact []string
+	}
+
+	accessLog struct {
+		log         *zap.Logger
+		sampleRatio float64
+		redact      map[string]struct{}
+	}
+
+	accessLogWriter struct {
+		http.ResponseWriter
+
+		statusCode int
+		bytes      int64
+	}
+
+	accessLogReader struct {
+		io.ReadCloser
+
+		bytes int64
+	}
+)
+
+// Access log fields which can be redacted.
+const (
+	AccessLogBucket      = "bucket"
+	AccessLogKey         = "key"
+	AccessLogAccessKeyID = "access_key_id"
+	AccessLogOwnerID     = "owner_id"
+	AccessLogSourceIP    = "source_ip"
+	AccessLogUserAgent   = "user_agent"
+
+	redactedValue = "REDACTED"
+)
+
+// AccessLogRedactable is the list of access log fields which can be redacted.
+var AccessLogRedactable = []string{
+	AccessLogBucket,
+	AccessLogKey,
+	AccessLogAccessKeyID,
+	AccessLogOwnerID,
+	AccessLogSourceIP,
+	AccessLogUserAgent,
+}
+
+// AccessLog returns middleware writing one entry to l for every request.
+func AccessLog(l *zap.Logger, cfg *AccessLogConfig) (mux.MiddlewareFunc, error) {
+	al := &accessLog{
+		log:         l,
+		sampleRatio: cfg.SampleRatio,
+		redact:      make(map[string]struct{}, len(cfg.Redact)),
+	}
+
+	for _, field := range cfg.Redact {
+		if !isRedactable(field) {
+			return nil, fmt.Errorf("access log field %q can't be redacted", field)
+		}
+		al.redact[field] = struct{}{}
+	}
+
+	return al.middleware, nil
+}
+
+func isRedactable(field string) bool {
+	for _, f := range AccessLogRedactable {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *accessLog) middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+
+		body := &accessLogReader{ReadCloser: r.Body}
+		r.Body = body
+		lw := &accessLogWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+		h.ServeHTTP(lw, r)
+
+		if lw.statusCode < http.StatusBadRequest && !a.sampled() {
+			return
+		}
+
+		// access key and owner are set by authentication on the same ReqInfo
+		reqInfo := GetReqInfo(r.Context())
+		a.log.Info("",
+			zap.String("operation", reqInfo.API),
+			zap.String("method", r.Method),
+			a.field(AccessLogBucket, reqInfo.BucketName),
+			a.field(AccessLogKey, reqInfo.ObjectName),
+			zap.Int("status", lw.statusCode),
+			zap.String("request_id", reqInfo.RequestID),
+			a.field(AccessLogAccessKeyID, reqInfo.AccessKeyID),
+			a.field(AccessLogOwnerID, reqInfo.OwnerID),
+			a.field(AccessLogSourceIP, reqInfo.RemoteHost),
+			a.field(AccessLogUserAgent, reqInfo.UserAgent),
+			zap.Int64("bytes_received", body.bytes),
+			zap.Int64("bytes_sent", lw.bytes),
+			zap.Float64("latency_ms", float64(time.Since(start).Microseconds())/1000))
+	})
+}
+
+func (a *accessLog) sampled() bool {
+	return a.sampleRatio >= 1 || rand.Float64() < a.sampleRatio
+}
+
+func (a *accessLog) field(name, value string) zap.Field {
+	if _, ok := a.redact[name]; ok && value != "" {
+		value = redactedValue
+	}
+	return zap.String(name, value)
+}
+
+func (w *accessLogWriter) WriteHeader(code int) {
+	w.statusCode = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *accessLogWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush calls the underlying Flush.
+func (w *accessLogWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *accessLogReader) Read(p []byte) (int, error) {
+	n, err := r.ReadCloser.Read(p)
+	r.bytes += int64(n)
+	return n, err
+}
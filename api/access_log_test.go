@@ -0,0 +1,80 @@
+package api
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestAccessLog(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+
+	al, err := AccessLog(zap.New(core), &AccessLogConfig{
+		SampleRatio: 1,
+		Redact:      []string{AccessLogSourceIP},
+	})
+	require.NoError(t, err)
+
+	r := mux.NewRouter()
+	r.Use(setRequestID, al)
+	r.Methods(http.MethodPut).Path("/{bucket}/{object:.+}").Name("PutObject").
+		HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			reqInfo := GetReqInfo(r.Context())
+			reqInfo.AccessKeyID = "access-key"
+			reqInfo.OwnerID = "owner"
+
+			_, err := io.Copy(io.Discard, r.Body)
+			require.NoError(t, err)
+			w.WriteHeader(http.StatusCreated)
+			_, err = w.Write([]byte("done"))
+			require.NoError(t, err)
+		})
+
+	req := httptest.NewRequest(http.MethodPut, "/bucket/dir/object", strings.NewReader("payload"))
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Equal(t, 1, logs.Len())
+	fields := logs.All()[0].ContextMap()
+	require.Equal(t, "PutObject", fields["operation"])
+	require.Equal(t, "bucket", fields["bucket"])
+	require.Equal(t, "dir/object", fields["key"])
+	require.Equal(t, int64(http.StatusCreated), fields["status"])
+	require.Equal(t, "access-key", fields["access_key_id"])
+	require.Equal(t, "owner", fields["owner_id"])
+	require.Equal(t, redactedValue, fields["source_ip"])
+	require.Equal(t, int64(len("payload")), fields["bytes_received"])
+	require.Equal(t, int64(len("done")), fields["bytes_sent"])
+	require.NotEmpty(t, fields["request_id"])
+}
+
+func TestAccessLogSampling(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+
+	al, err := AccessLog(zap.New(core), &AccessLogConfig{SampleRatio: 0})
+	require.NoError(t, err)
+
+	status := http.StatusOK
+	h := al(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/bucket", nil))
+	require.Zero(t, logs.Len())
+
+	// failed requests are logged regardless of sampling
+	status = http.StatusForbidden
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/bucket", nil))
+	require.Equal(t, 1, logs.Len())
+}
+
+func TestAccessLogRedactUnknown(t *testing.T) {
+	_, err := AccessLog(zap.NewNop(), &AccessLogConfig{Redact: []string{"status"}})
+	require.Error(t, err)
+}
@@ -15,10 +15,10 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws/credentials"
-	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neofs-api-go/pkg/object"
 	apiErrors "github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
 	"github.com/nspcc-dev/neofs-sdk-go/pool"
@@ -34,13 +34,33 @@ var postPolicyCredentialRegexp = regexp.MustCompile(`(?P<access_key_id>[^/]+)/(?
 type (
 	// Center is a user authentication interface.
 	Center interface {
-		Authenticate(request *http.Request) (*accessbox.Box, error)
+		Authenticate(request *http.Request) (*Box, error)
+	}
+
+	// Box contains access box and ID of the access key the request was
+	// authenticated with.
+	Box struct {
+		AccessBox   *accessbox.Box
+		AccessKeyID string
 	}
 
 	center struct {
 		reg     *regexpSubmatcher
 		postReg *regexpSubmatcher
 		cli     tokens.Credentials
+		certs   *CertMapping
+		key     *keys.PublicKey
+		signV2  bool
+	}
+
+	// Config contains optional authentication settings.
+	Config struct {
+		// ClientCerts enables authentication by verified client certificates.
+		ClientCerts *CertMapping
+		// RetiredKeys are used to decrypt access boxes issued before rotation of the gateway key.
+		RetiredKeys []*keys.PrivateKey
+		// SignatureV2 enables authentication of legacy clients with AWS Signature Version 2.
+		SignatureV2 bool
 	}
 
 	// Params stores node connection parameters.
@@ -80,13 +100,25 @@ func (p prs) Seek(_ int64, _ int) (int64, error) {
 
 var _ io.ReadSeeker = prs(0)
 
-// New creates an instance of AuthCenter.
-func New(conns pool.Pool, key *keys.PrivateKey) Center {
-	return &center{
-		cli:     tokens.New(conns, key),
+// New creates an instance of AuthCenter. Config is optional.
+func New(conns pool.Pool, key *keys.PrivateKey, cfg *Config) Center {
+	if cfg == nil {
+		cfg = new(Config)
+	}
+
+	c := &center{
+		cli:     tokens.New(conns, key, cfg.RetiredKeys...),
 		reg:     &regexpSubmatcher{re: authorizationFieldRegexp},
 		postReg: &regexpSubmatcher{re: postPolicyCredentialRegexp},
+		certs:   cfg.ClientCerts,
+		signV2:  cfg.SignatureV2,
+	}
+
+	if key != nil {
+		c.key = key.PublicKey()
 	}
+
+	return c
 }
 
 func (c *center) parseAuthHeader(header string) (*authHeader, error) {
@@ -113,17 +145,31 @@ func (c *center) parseAuthHeader(header string) (*authHeader, error) {
 }
 
 func (a *authHeader) getAddress() (*object.Address, error) {
+	return addressFromAccessKeyID(a.AccessKeyID)
+}
+
+func addressFromAccessKeyID(accessKeyID string) (*object.Address, error) {
 	address := object.NewAddress()
-	if err := address.Parse(strings.ReplaceAll(a.AccessKeyID, "0", "/")); err != nil {
+	if err := address.Parse(strings.ReplaceAll(accessKeyID, "0", "/")); err != nil {
 		return nil, apiErrors.GetAPIError(apiErrors.ErrInvalidAccessKeyID)
 	}
 	return address, nil
 }
 
-func (c *center) Authenticate(r *http.Request) (*accessbox.Box, error) {
-	queryValues := r.URL.Query()
-	if queryValues.Get("X-Amz-Algorithm") == "AWS4-HMAC-SHA256" {
-		return nil, errors.New("pre-signed form of request is not supported")
+func (c *center) Authenticate(r *http.Request) (*Box, error) {
+	if address := c.clientCertAddress(r); address != nil {
+		return c.getBox(r.Context(), address)
+	}
+
+	if algorithm := r.URL.Query().Get(amzAlgorithm); algorithm != "" {
+		if algorithm != "AWS4-HMAC-SHA256" {
+			return nil, apiErrors.GetAPIError(apiErrors.ErrInvalidQuerySignatureAlgo)
+		}
+		return c.checkPresigned(r)
+	}
+
+	if isPresignedV2(r) {
+		return c.checkPresignedV2(r)
 	}
 
 	authHeaderField := r.Header["Authorization"]
@@ -134,6 +180,10 @@ func (c *center) Authenticate(r *http.Request) (*accessbox.Box, error) {
 		return nil, ErrNoAuthorizationHeader
 	}
 
+	if isSignV2Header(authHeaderField[0]) {
+		return c.checkSignV2(r, authHeaderField[0])
+	}
+
 	authHeader, err := c.parseAuthHeader(authHeaderField[0])
 	if err != nil {
 		return nil, err
@@ -149,20 +199,55 @@ func (c *center) Authenticate(r *http.Request) (*accessbox.Box, error) {
 		return nil, err
 	}
 
-	box, err := c.cli.GetBox(r.Context(), address)
+	box, err := c.getBox(r.Context(), address)
 	if err != nil {
 		return nil, err
 	}
 
 	clonedRequest := cloneRequest(r, authHeader)
-	if err = c.checkSign(authHeader, box, clonedRequest, signatureDateTime); err != nil {
+	if err = c.checkSign(authHeader, box.AccessBox, clonedRequest, signatureDateTime); err != nil {
 		return nil, err
 	}
 
 	return box, nil
 }
 
-func (c *center) checkFormData(r *http.Request) (*accessbox.Box, error) {
+func (c *center) getBox(ctx context.Context, address *object.Address) (*Box, error) {
+	box, err := c.cli.GetBox(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	accessKeyID := strings.ReplaceAll(address.String(), "/", "0")
+	if box.Replaced {
+		metrics.ObserveRetiredKeyBox(metrics.ReplacedBoxKey, accessKeyID)
+	} else if gateKey := box.Gate.GateKey; gateKey != nil && c.key != nil && !gateKey.Equal(c.key) {
+		metrics.ObserveRetiredKeyBox(hex.EncodeToString(gateKey.Bytes()), accessKeyID)
+	}
+
+	return &Box{
+		AccessBox:   box,
+		AccessKeyID: accessKeyID,
+	}, nil
+}
+
+// clientCertAddress returns address of access box mapped to the verified
+// client certificate, nil means header authentication should be used.
+func (c *center) clientCertAddress(r *http.Request) *object.Address {
+	if c.certs == nil {
+		return nil
+	}
+
+	cert := verifiedClientCert(r)
+	if cert == nil {
+		return nil
+	}
+
+	address, _ := c.certs.Address(cert)
+	return address
+}
+
+func (c *center) checkFormData(r *http.Request) (*Box, error) {
 	if err := r.ParseMultipartForm(maxFormSizeMemory); err != nil {
 		return nil, apiErrors.GetAPIError(apiErrors.ErrInvalidArgument)
 	}
@@ -186,17 +271,17 @@ func (c *center) checkFormData(r *http.Request) (*accessbox.Box, error) {
 		return nil, fmt.Errorf("failed to parse x-amz-date field: %w", err)
 	}
 
-	address := object.NewAddress()
-	if err = address.Parse(strings.ReplaceAll(submatches["access_key_id"], "0", "/")); err != nil {
-		return nil, apiErrors.GetAPIError(apiErrors.ErrInvalidAccessKeyID)
+	address, err := addressFromAccessKeyID(submatches["access_key_id"])
+	if err != nil {
+		return nil, err
 	}
 
-	box, err := c.cli.GetBox(r.Context(), address)
+	box, err := c.getBox(r.Context(), address)
 	if err != nil {
 		return nil, err
 	}
 
-	secret := box.Gate.AccessKey
+	secret := box.AccessBox.Gate.AccessKey
 	service, region := submatches["service"], submatches["region"]
 
 	signature := signStr(secret, service, region, signatureDateTime, policy)
@@ -224,11 +309,8 @@ func cloneRequest(r *http.Request, authHeader *authHeader) *http.Request {
 
 func (c *center) checkSign(authHeader *authHeader, box *accessbox.Box, request *http.Request, signatureDateTime time.Time) error {
 	awsCreds := credentials.NewStaticCredentials(authHeader.AccessKeyID, box.Gate.AccessKey, "")
-	signer := v4.NewSigner(awsCreds)
-	signer.DisableURIPathEscaping = true
-
 	// body not required
-	if _, err := signer.Sign(request, nil, authHeader.Service, authHeader.Region, signatureDateTime); err != nil {
+	if _, err := newSigner(awsCreds).Sign(request, nil, authHeader.Service, authHeader.Region, signatureDateTime); err != nil {
 		return fmt.Errorf("failed to sign temporary HTTP request: %w", err)
 	}
 
@@ -0,0 +1,123 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+)
+
+type (
+	// CertMapping maps client certificates to addresses of access boxes
+	// by subject alternative name or by SHA-256 fingerprint.
+	CertMapping struct {
+		bySAN         map[string]*object.Address
+		byFingerprint map[string]*object.Address
+	}
+
+	// CertMappingEntry is an entry of certificate mapping file.
+	// Either SAN or Fingerprint must be set.
+	CertMappingEntry struct {
+		SAN         string `json:"san,omitempty"`
+		Fingerprint string `json:"fingerprint,omitempty"`
+		AccessKeyID string `json:"access_key_id"`
+	}
+)
+
+// ReadCertMapping reads JSON list of CertMappingEntry from the file.
+func ReadCertMapping(path string) (*CertMapping, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read certificate mapping file: %w", err)
+	}
+
+	var entries []CertMappingEntry
+	if err = json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("could not parse certificate mapping file: %w", err)
+	}
+
+	return NewCertMapping(entries)
+}
+
+// NewCertMapping creates CertMapping from the list of entries.
+func NewCertMapping(entries []CertMappingEntry) (*CertMapping, error) {
+	m := &CertMapping{
+		bySAN:         make(map[string]*object.Address),
+		byFingerprint: make(map[string]*object.Address),
+	}
+
+	for i, e := range entries {
+		address := object.NewAddress()
+		if err := address.Parse(strings.ReplaceAll(e.AccessKeyID, "0", "/")); err != nil {
+			return nil, fmt.Errorf("invalid access key id in entry %d: %w", i, err)
+		}
+
+		switch {
+		case e.SAN != "" && e.Fingerprint != "":
+			return nil, fmt.Errorf("both san and fingerprint are set in entry %d", i)
+		case e.SAN != "":
+			m.bySAN[strings.ToLower(e.SAN)] = address
+		case e.Fingerprint != "":
+			m.byFingerprint[normalizeFingerprint(e.Fingerprint)] = address
+		default:
+			return nil, fmt.Errorf("neither san nor fingerprint is set in entry %d", i)
+		}
+	}
+
+	return m, nil
+}
+
+func normalizeFingerprint(fp string) string {
+	return strings.ToLower(strings.ReplaceAll(fp, ":", ""))
+}
+
+// Fingerprint returns hex-encoded SHA-256 hash of the certificate.
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// Address returns address of access box mapped to the certificate.
+// Fingerprint has priority over subject alternative names.
+func (m *CertMapping) Address(cert *x509.Certificate) (*object.Address, bool) {
+	if address, ok := m.byFingerprint[Fingerprint(cert)]; ok {
+		return address, true
+	}
+
+	for _, san := range certSANs(cert) {
+		if address, ok := m.bySAN[strings.ToLower(san)]; ok {
+			return address, true
+		}
+	}
+
+	return nil, false
+}
+
+func certSANs(cert *x509.Certificate) []string {
+	sans := make([]string, 0, len(cert.DNSNames)+len(cert.EmailAddresses)+len(cert.URIs)+len(cert.IPAddresses))
+	sans = append(sans, cert.DNSNames...)
+	sans = append(sans, cert.EmailAddresses...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+
+	return sans
+}
+
+// verifiedClientCert returns client certificate if it was verified during TLS handshake.
+func verifiedClientCert(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return nil
+	}
+
+	return r.TLS.VerifiedChains[0][0]
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	objecttest "github.com/nspcc-dev/neofs-api-go/pkg/object/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertMapping(t *testing.T) {
+	bySAN, byFP := objecttest.Address(), objecttest.Address()
+	spiffe, err := url.Parse("spiffe://cluster.local/ns/default/sa/backup")
+	require.NoError(t, err)
+
+	cert := &x509.Certificate{
+		Raw:      []byte("certificate"),
+		DNSNames: []string{"Backup.cluster.local"},
+		URIs:     []*url.URL{spiffe},
+	}
+	other := &x509.Certificate{Raw: []byte("other"), DNSNames: []string{"unknown.cluster.local"}}
+	fp := Fingerprint(cert)
+
+	accessKeyID := func(s string) string { return strings.ReplaceAll(s, "/", "0") }
+
+	t.Run("san", func(t *testing.T) {
+		m, err := NewCertMapping([]CertMappingEntry{
+			{SAN: "spiffe://cluster.local/ns/default/sa/backup", AccessKeyID: accessKeyID(bySAN.String())},
+		})
+		require.NoError(t, err)
+
+		address, ok := m.Address(cert)
+		require.True(t, ok)
+		require.Equal(t, bySAN.String(), address.String())
+
+		_, ok = m.Address(other)
+		require.False(t, ok)
+	})
+
+	t.Run("fingerprint has priority", func(t *testing.T) {
+		m, err := NewCertMapping([]CertMappingEntry{
+			{SAN: "backup.cluster.local", AccessKeyID: accessKeyID(bySAN.String())},
+			{Fingerprint: strings.ToUpper(fp[:2]) + ":" + fp[2:], AccessKeyID: byFP.String()},
+		})
+		require.NoError(t, err)
+
+		address, ok := m.Address(cert)
+		require.True(t, ok)
+		require.Equal(t, byFP.String(), address.String())
+	})
+
+	t.Run("invalid entries", func(t *testing.T) {
+		for _, e := range []CertMappingEntry{
+			{AccessKeyID: bySAN.String()},
+			{SAN: "a", Fingerprint: fp, AccessKeyID: bySAN.String()},
+			{SAN: "a", AccessKeyID: "invalid"},
+		} {
+			_, err := NewCertMapping([]CertMappingEntry{e})
+			require.Error(t, err)
+		}
+	})
+
+	t.Run("only verified certificates are used", func(t *testing.T) {
+		m, err := NewCertMapping([]CertMappingEntry{{SAN: "backup.cluster.local", AccessKeyID: bySAN.String()}})
+		require.NoError(t, err)
+		c := &center{certs: m}
+
+		r := httptest.NewRequest("GET", "/bucket", nil)
+		require.Nil(t, c.clientCertAddress(r))
+
+		r.TLS = &tls.ConnectionState{PeerCertificates: []*x509.Certificate{cert}}
+		require.Nil(t, c.clientCertAddress(r))
+
+		r.TLS.VerifiedChains = [][]*x509.Certificate{{cert}}
+		require.Equal(t, bySAN.String(), c.clientCertAddress(r).String())
+
+		r.TLS.VerifiedChains = [][]*x509.Certificate{{other}}
+		require.Nil(t, c.clientCertAddress(r))
+	})
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	apiErrors "github.com/nspcc-dev/neofs-s3-gw/api/errors"
+)
+
+// PresignData contains parameters of a pre-signed request.
+type PresignData struct {
+	Service  string
+	Region   string
+	Lifetime time.Duration
+	SignTime time.Time
+}
+
+const (
+	amzAlgorithm     = "X-Amz-Algorithm"
+	amzCredential    = "X-Amz-Credential"
+	amzDate          = "X-Amz-Date"
+	amzExpires       = "X-Amz-Expires"
+	amzSignedHeaders = "X-Amz-SignedHeaders"
+	amzSignature     = "X-Amz-Signature"
+
+	// MaxPresignLifetime is the longest lifetime of a pre-signed request.
+	MaxPresignLifetime = 7 * 24 * time.Hour
+	// presignClockSkew is the allowed difference between clocks of the client and the gateway.
+	presignClockSkew = 15 * time.Minute
+)
+
+// newSigner returns SigV4 signer configured the way the gateway checks signatures.
+func newSigner(creds *credentials.Credentials) *v4.Signer {
+	signer := v4.NewSigner(creds)
+	signer.DisableURIPathEscaping = true
+	return signer
+}
+
+// PresignRequest signs the request with query parameters, the signature
+// is added to the request URL.
+func PresignRequest(creds *credentials.Credentials, req *http.Request, data PresignData) error {
+	_, err := newSigner(creds).Presign(req, nil, data.Service, data.Region, data.Lifetime, data.SignTime)
+	return err
+}
+
+// checkPresigned authenticates the request signed with query parameters.
+func (c *center) checkPresigned(r *http.Request) (*Box, error) {
+	query := r.URL.Query()
+
+	submatches := c.postReg.getSubmatches(query.Get(amzCredential))
+	if len(submatches) != 4 {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrInvalidQueryParams)
+	}
+
+	signTime, err := time.Parse("20060102T150405Z", query.Get(amzDate))
+	if err != nil {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrMalformedDate)
+	}
+
+	expires, err := strconv.ParseInt(query.Get(amzExpires), 10, 64)
+	switch {
+	case err != nil:
+		return nil, apiErrors.GetAPIError(apiErrors.ErrMalformedExpires)
+	case expires < 0:
+		return nil, apiErrors.GetAPIError(apiErrors.ErrNegativeExpires)
+	case expires > int64(MaxPresignLifetime/time.Second):
+		return nil, apiErrors.GetAPIError(apiErrors.ErrMaximumExpires)
+	}
+	lifetime := time.Duration(expires) * time.Second
+
+	now := time.Now()
+	if signTime.After(now.Add(presignClockSkew)) {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrRequestNotReadyYet)
+	}
+	if now.After(signTime.Add(lifetime)) {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrExpiredPresignRequest)
+	}
+
+	header := &authHeader{
+		AccessKeyID:  submatches["access_key_id"],
+		Service:      submatches["service"],
+		Region:       submatches["region"],
+		SignatureV4:  query.Get(amzSignature),
+		SignedFields: strings.Split(query.Get(amzSignedHeaders), ";"),
+		Date:         submatches["date"],
+	}
+
+	address, err := header.getAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	box, err := c.getBox(r.Context(), address)
+	if err != nil {
+		return nil, err
+	}
+
+	// the signer adds these parameters again
+	clonedRequest := cloneRequest(r, header)
+	for _, key := range []string{amzAlgorithm, amzCredential, amzDate, amzExpires, amzSignedHeaders, amzSignature} {
+		query.Del(key)
+	}
+	clonedRequest.URL.RawQuery = query.Encode()
+
+	awsCreds := credentials.NewStaticCredentials(header.AccessKeyID, box.AccessBox.Gate.AccessKey, "")
+	err = PresignRequest(awsCreds, clonedRequest, PresignData{
+		Service:  header.Service,
+		Region:   header.Region,
+		Lifetime: lifetime,
+		SignTime: signTime,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to pre-sign temporary HTTP request: %w", err)
+	}
+
+	if header.SignatureV4 != clonedRequest.URL.Query().Get(amzSignature) {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrSignatureDoesNotMatch)
+	}
+
+	return box, nil
+}
@@ -0,0 +1,108 @@
+package auth
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"github.com/nspcc-dev/neofs-api-go/pkg/owner"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/stretchr/testify/require"
+)
+
+type credentialsMock struct {
+	boxes map[string]*accessbox.Box
+}
+
+func (m credentialsMock) GetBox(_ context.Context, addr *object.Address) (*accessbox.Box, error) {
+	box, ok := m.boxes[addr.String()]
+	if !ok {
+		return nil, errors.GetAPIError(errors.ErrInvalidAccessKeyID)
+	}
+	return box, nil
+}
+
+func (m credentialsMock) GetAccessBox(context.Context, *object.Address) (*accessbox.AccessBox, error) {
+	panic("implement me")
+}
+
+func (m credentialsMock) Put(context.Context, *cid.ID, *owner.ID, *accessbox.AccessBox, ...*keys.PublicKey) (*object.Address, error) {
+	panic("implement me")
+}
+
+func (m credentialsMock) PutReplacement(context.Context, *object.Address, *owner.ID, *accessbox.AccessBox, ...*keys.PublicKey) (*object.Address, error) {
+	panic("implement me")
+}
+
+func TestCheckPresigned(t *testing.T) {
+	const (
+		accessKeyID = "vWqF8cMDRbJcvnPLALoQGnABPPhw8NyYMcGsfDPfZJM0HrgjonN8CgFvCZ3kh9BUXw4W2tJ5E7EAGhueSF122HB"
+		secret      = "66be461c3cd429941c55daf42fad2b8153e5a2016ba89c9494d97677cc9d3872"
+	)
+
+	c := New(nil, nil, nil).(*center)
+	c.cli = credentialsMock{boxes: map[string]*accessbox.Box{
+		"vWqF8cMDRbJcvnPLALoQGnABPPhw8NyYMcGsfDPfZJM/HrgjonN8CgFvCZ3kh9BUXw4W2tJ5E7EAGhueSF122HB": {
+			Gate: &accessbox.GateData{AccessKey: secret},
+		},
+	}}
+
+	presign := func(t *testing.T, secret string, signTime time.Time, lifetime time.Duration) *url.URL {
+		req := httptest.NewRequest("GET", "http://localhost:8084/bucket/path/to/object?versionId=1", nil)
+		err := PresignRequest(credentials.NewStaticCredentials(accessKeyID, secret, ""), req, PresignData{
+			Service:  "s3",
+			Region:   "us-east-1",
+			Lifetime: lifetime,
+			SignTime: signTime,
+		})
+		require.NoError(t, err)
+		return req.URL
+	}
+
+	authenticate := func(u *url.URL) error {
+		_, err := c.Authenticate(httptest.NewRequest("GET", u.String(), nil))
+		return err
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		u := presign(t, secret, time.Now(), time.Minute)
+		box, err := c.Authenticate(httptest.NewRequest("GET", u.String(), nil))
+		require.NoError(t, err)
+		require.Equal(t, accessKeyID, box.AccessKeyID)
+	})
+
+	t.Run("wrong secret", func(t *testing.T) {
+		u := presign(t, "wrong", time.Now(), time.Minute)
+		require.Equal(t, errors.GetAPIError(errors.ErrSignatureDoesNotMatch), authenticate(u))
+	})
+
+	t.Run("modified", func(t *testing.T) {
+		u := presign(t, secret, time.Now(), time.Minute)
+		q := u.Query()
+		q.Set("versionId", "2")
+		u.RawQuery = q.Encode()
+		require.Equal(t, errors.GetAPIError(errors.ErrSignatureDoesNotMatch), authenticate(u))
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		u := presign(t, secret, time.Now().Add(-time.Hour), time.Minute)
+		require.Equal(t, errors.GetAPIError(errors.ErrExpiredPresignRequest), authenticate(u))
+	})
+
+	t.Run("not ready", func(t *testing.T) {
+		u := presign(t, secret, time.Now().Add(time.Hour), time.Minute)
+		require.Equal(t, errors.GetAPIError(errors.ErrRequestNotReadyYet), authenticate(u))
+	})
+
+	t.Run("too long", func(t *testing.T) {
+		u := presign(t, secret, time.Now(), MaxPresignLifetime+time.Second)
+		require.Equal(t, errors.GetAPIError(errors.ErrMaximumExpires), authenticate(u))
+	})
+}
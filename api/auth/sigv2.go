@@ -0,0 +1,220 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	apiErrors "github.com/nspcc-dev/neofs-s3-gw/api/errors"
+)
+
+const (
+	signV2Algorithm = "AWS"
+
+	amzAccessKeyIDV2 = "AWSAccessKeyId"
+	amzExpiresV2     = "Expires"
+	amzSignatureV2   = "Signature"
+)
+
+// resourceListV2 contains query parameters included in the canonical
+// resource of SigV2, it must be sorted.
+var resourceListV2 = []string{
+	"acl",
+	"cors",
+	"delete",
+	"encryption",
+	"legal-hold",
+	"lifecycle",
+	"location",
+	"logging",
+	"notification",
+	"partNumber",
+	"policy",
+	"requestPayment",
+	"response-cache-control",
+	"response-content-disposition",
+	"response-content-encoding",
+	"response-content-language",
+	"response-content-type",
+	"response-expires",
+	"retention",
+	"select",
+	"select-type",
+	"tagging",
+	"torrent",
+	"uploadId",
+	"uploads",
+	"versionId",
+	"versioning",
+	"versions",
+	"website",
+}
+
+// isSignV2Header checks if the authorization header is of SigV2.
+func isSignV2Header(header string) bool {
+	return strings.HasPrefix(header, signV2Algorithm+" ")
+}
+
+// isPresignedV2 checks if the request is signed with SigV2 query parameters.
+func isPresignedV2(r *http.Request) bool {
+	query := r.URL.Query()
+	return query.Get(amzAccessKeyIDV2) != "" && query.Get(amzSignatureV2) != ""
+}
+
+// checkSignV2 authenticates the request with SigV2 authorization header.
+func (c *center) checkSignV2(r *http.Request, header string) (*Box, error) {
+	if !c.signV2 {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrSignatureVersionNotSupported)
+	}
+
+	credential := strings.TrimPrefix(header, signV2Algorithm+" ")
+	sep := strings.IndexByte(credential, ':')
+	if sep <= 0 || sep == len(credential)-1 {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrAuthorizationHeaderMalformed)
+	}
+	accessKeyID, signature := credential[:sep], credential[sep+1:]
+
+	dateHeader := r.Header.Get("Date")
+	signDate := r.Header.Get(amzDate)
+	if signDate == "" {
+		signDate = dateHeader
+	}
+	if signDate == "" {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrMissingDateHeader)
+	}
+	signTime, err := http.ParseTime(signDate)
+	if err != nil {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrMalformedDate)
+	}
+	if skew := time.Since(signTime); skew > presignClockSkew || skew < -presignClockSkew {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrRequestTimeTooSkewed)
+	}
+
+	// x-amz-date is signed among x-amz-* headers instead of Date
+	if r.Header.Get(amzDate) != "" {
+		dateHeader = ""
+	}
+
+	return c.verifySignV2(r, accessKeyID, signature, dateHeader)
+}
+
+// checkPresignedV2 authenticates the request signed with SigV2 query parameters.
+func (c *center) checkPresignedV2(r *http.Request) (*Box, error) {
+	if !c.signV2 {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrSignatureVersionNotSupported)
+	}
+
+	query := r.URL.Query()
+
+	expires := query.Get(amzExpiresV2)
+	if expires == "" {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrInvalidQueryParams)
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrMalformedExpires)
+	}
+	if time.Now().Unix() > expiresAt {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrExpiredPresignRequest)
+	}
+
+	return c.verifySignV2(r, query.Get(amzAccessKeyIDV2), query.Get(amzSignatureV2), expires)
+}
+
+func (c *center) verifySignV2(r *http.Request, accessKeyID, signature, date string) (*Box, error) {
+	address, err := addressFromAccessKeyID(accessKeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	box, err := c.getBox(r.Context(), address)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := signV2(box.AccessBox.Gate.AccessKey, stringToSignV2(r, date))
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrSignatureDoesNotMatch)
+	}
+
+	return box, nil
+}
+
+func signV2(secret, stringToSign string) string {
+	hash := hmac.New(sha1.New, []byte(secret))
+	hash.Write([]byte(stringToSign))
+	return base64.StdEncoding.EncodeToString(hash.Sum(nil))
+}
+
+// stringToSignV2 makes the string to sign of SigV2, date is a value of Date
+// header or Expires query parameter.
+func stringToSignV2(r *http.Request, date string) string {
+	return r.Method + "\n" +
+		r.Header.Get("Content-MD5") + "\n" +
+		r.Header.Get("Content-Type") + "\n" +
+		date + "\n" +
+		canonicalAmzHeadersV2(r.Header) +
+		canonicalResourceV2(r)
+}
+
+func canonicalAmzHeadersV2(header http.Header) string {
+	values := make(map[string][]string)
+	for key, vals := range header {
+		key = strings.ToLower(key)
+		if !strings.HasPrefix(key, "x-amz-") {
+			continue
+		}
+		for _, v := range vals {
+			values[key] = append(values[key], strings.TrimSpace(v))
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, key := range keys {
+		sb.WriteString(key + ":" + strings.Join(values[key], ",") + "\n")
+	}
+	return sb.String()
+}
+
+// canonicalResourceV2 makes the canonical resource of SigV2: the path of
+// path-style request (the bucket is prepended to the path of virtual-hosted
+// style request) with the subresources.
+func canonicalResourceV2(r *http.Request) string {
+	resource := r.URL.EscapedPath()
+	if route := mux.CurrentRoute(r); route != nil {
+		if tmpl, err := route.GetHostTemplate(); err == nil && tmpl != "" {
+			resource = "/" + mux.Vars(r)["bucket"] + resource
+		}
+	}
+
+	query := r.URL.Query()
+	var subresources []string
+	for _, key := range resourceListV2 {
+		vals, ok := query[key]
+		if !ok {
+			continue
+		}
+		if len(vals) == 0 || vals[0] == "" {
+			subresources = append(subresources, key)
+		} else {
+			subresources = append(subresources, key+"="+vals[0])
+		}
+	}
+
+	if len(subresources) > 0 {
+		resource += "?" + strings.Join(subresources, "&")
+	}
+	return resource
+}
@@ -0,0 +1,97 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStringToSignV2(t *testing.T) {
+	// example from AWS documentation
+	req := httptest.NewRequest("GET", "http://localhost:8084/johnsmith/photos/puppy.jpg", nil)
+	req.Header.Set("Date", "Tue, 27 Mar 2007 19:36:42 +0000")
+
+	str := stringToSignV2(req, req.Header.Get("Date"))
+	require.Equal(t, "GET\n\n\nTue, 27 Mar 2007 19:36:42 +0000\n/johnsmith/photos/puppy.jpg", str)
+	require.Equal(t, "bWq2s1WEIj+Ydj0vQ697zp+IXMU=", signV2("wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY", str))
+
+	req = httptest.NewRequest("PUT", "http://localhost:8084/bucket/object?versionId=1&prefix=a&acl", nil)
+	req.Header.Set("Content-Type", "text/plain")
+	req.Header.Set("X-Amz-Meta-B", " 2 ")
+	req.Header.Add("X-Amz-Meta-A", "1")
+	req.Header.Add("X-Amz-Meta-A", "3")
+	require.Equal(t, "PUT\n\ntext/plain\n\nx-amz-meta-a:1,3\nx-amz-meta-b:2\n/bucket/object?acl&versionId=1",
+		stringToSignV2(req, ""))
+}
+
+func TestCheckSignV2(t *testing.T) {
+	const (
+		accessKeyID = "vWqF8cMDRbJcvnPLALoQGnABPPhw8NyYMcGsfDPfZJM0HrgjonN8CgFvCZ3kh9BUXw4W2tJ5E7EAGhueSF122HB"
+		secret      = "66be461c3cd429941c55daf42fad2b8153e5a2016ba89c9494d97677cc9d3872"
+		target      = "http://localhost:8084/bucket/object?tagging"
+	)
+
+	c := New(nil, nil, &Config{SignatureV2: true}).(*center)
+	c.cli = credentialsMock{boxes: map[string]*accessbox.Box{
+		"vWqF8cMDRbJcvnPLALoQGnABPPhw8NyYMcGsfDPfZJM/HrgjonN8CgFvCZ3kh9BUXw4W2tJ5E7EAGhueSF122HB": {
+			Gate: &accessbox.GateData{AccessKey: secret},
+		},
+	}}
+
+	signed := func(secret string, date time.Time) *http.Request {
+		req := httptest.NewRequest("GET", target, nil)
+		req.Header.Set("Date", date.UTC().Format(http.TimeFormat))
+		signature := signV2(secret, stringToSignV2(req, req.Header.Get("Date")))
+		req.Header.Set("Authorization", "AWS "+accessKeyID+":"+signature)
+		return req
+	}
+
+	presigned := func(secret string, expires time.Time) *http.Request {
+		exp := strconv.FormatInt(expires.Unix(), 10)
+		signature := signV2(secret, stringToSignV2(httptest.NewRequest("GET", target, nil), exp))
+		query := url.Values{amzAccessKeyIDV2: {accessKeyID}, amzExpiresV2: {exp}, amzSignatureV2: {signature}}
+		return httptest.NewRequest("GET", target+"&"+query.Encode(), nil)
+	}
+
+	t.Run("header", func(t *testing.T) {
+		box, err := c.Authenticate(signed(secret, time.Now()))
+		require.NoError(t, err)
+		require.Equal(t, accessKeyID, box.AccessKeyID)
+	})
+
+	t.Run("header with wrong secret", func(t *testing.T) {
+		_, err := c.Authenticate(signed("wrong", time.Now()))
+		require.Equal(t, errors.GetAPIError(errors.ErrSignatureDoesNotMatch), err)
+	})
+
+	t.Run("header with skewed date", func(t *testing.T) {
+		_, err := c.Authenticate(signed(secret, time.Now().Add(-time.Hour)))
+		require.Equal(t, errors.GetAPIError(errors.ErrRequestTimeTooSkewed), err)
+	})
+
+	t.Run("query", func(t *testing.T) {
+		box, err := c.Authenticate(presigned(secret, time.Now().Add(time.Minute)))
+		require.NoError(t, err)
+		require.Equal(t, accessKeyID, box.AccessKeyID)
+	})
+
+	t.Run("expired query", func(t *testing.T) {
+		_, err := c.Authenticate(presigned(secret, time.Now().Add(-time.Minute)))
+		require.Equal(t, errors.GetAPIError(errors.ErrExpiredPresignRequest), err)
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		c.signV2 = false
+		_, err := c.Authenticate(signed(secret, time.Now()))
+		require.Equal(t, errors.GetAPIError(errors.ErrSignatureVersionNotSupported), err)
+		_, err = c.Authenticate(presigned(secret, time.Now().Add(time.Minute)))
+		require.Equal(t, errors.GetAPIError(errors.ErrSignatureVersionNotSupported), err)
+	})
+}
@@ -0,0 +1,230 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"go.uber.org/zap"
+)
+
+type (
+	// Authorizer provides HTTP middleware asking an external policy whether
+	// the authenticated request is allowed.
+	Authorizer interface {
+		Middleware(http.Handler) http.Handler
+	}
+
+	// AuthzEvaluator makes authorization decisions.
+	AuthzEvaluator interface {
+		Evaluate(ctx context.Context, req *AuthzRequest) (*AuthzDecision, error)
+	}
+
+	// AuthzRequest is a document describing the request to authorize.
+	AuthzRequest struct {
+		Operation   string            `json:"operation"`
+		Method      string            `json:"method"`
+		Bucket      string            `json:"bucket,omitempty"`
+		Object      string            `json:"object,omitempty"`
+		OwnerID     string            `json:"owner_id,omitempty"`
+		AccessKeyID string            `json:"access_key_id,omitempty"`
+		SourceIP    string            `json:"source_ip"`
+		Headers     map[string]string `json:"headers,omitempty"`
+	}
+
+	// AuthzDecision is a result of the authorization.
+	AuthzDecision struct {
+		Allow  bool   `json:"allow"`
+		Reason string `json:"reason,omitempty"`
+	}
+
+	// AuthzConfig contains authorization settings.
+	AuthzConfig struct {
+		Evaluator AuthzEvaluator
+		// CacheTTL is a lifetime of cached decisions, zero disables the cache.
+		CacheTTL  time.Duration
+		CacheSize int
+		// FailOpen allows requests if the evaluator fails.
+		FailOpen bool
+		// Headers are the names of request headers included in the document.
+		Headers []string
+		// TrustedProxies can set the source IP in forwarding headers.
+		TrustedProxies TrustedProxies
+	}
+
+	authorizer struct {
+		cfg   AuthzConfig
+		cache gcache.Cache
+		log   *zap.Logger
+	}
+
+	webhookEvaluator struct {
+		url    string
+		client *http.Client
+	}
+
+	// webhookResponse is a decision either as is or wrapped into
+	// the result like OPA data API does.
+	webhookResponse struct {
+		AuthzDecision
+		Result *AuthzDecision `json:"result"`
+	}
+)
+
+const (
+	// DefaultAuthzCacheSize is a default number of cached authorization decisions.
+	DefaultAuthzCacheSize = 10000
+	// maxWebhookResponseSize limits the size of webhook response body.
+	maxWebhookResponseSize = 64 * 1024
+)
+
+// NewAuthorizer returns Authorizer using the evaluator of the config.
+func NewAuthorizer(cfg *AuthzConfig, log *zap.Logger) Authorizer {
+	a := &authorizer{cfg: *cfg, log: log}
+	if cfg.CacheTTL > 0 {
+		size := cfg.CacheSize
+		if size <= 0 {
+			size = DefaultAuthzCacheSize
+		}
+		a.cache = gcache.New(size).LRU().Build()
+	}
+
+	return a
+}
+
+// Middleware rejects requests denied by the evaluator with AccessDenied
+// error carrying the reason.
+func (a *authorizer) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqInfo := GetReqInfo(r.Context())
+
+		decision, err := a.authorize(r.Context(), a.document(r, reqInfo))
+		if err != nil {
+			if !a.cfg.FailOpen {
+				a.log.Error("authorization failed, request is denied", zap.Error(err))
+				WriteErrorResponse(w, reqInfo, errors.GetAPIErrorWithError(errors.ErrAccessDenied,
+					fmt.Errorf("authorization service is unavailable")))
+				return
+			}
+			a.log.Warn("authorization failed, request is allowed", zap.Error(err))
+			decision = &AuthzDecision{Allow: true}
+		}
+
+		if !decision.Allow {
+			reason := decision.Reason
+			if reason == "" {
+				reason = "denied by authorization policy"
+			}
+			WriteErrorResponse(w, reqInfo, errors.GetAPIErrorWithError(errors.ErrAccessDenied, fmt.Errorf("%s", reason)))
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (a *authorizer) document(r *http.Request, reqInfo *ReqInfo) *AuthzRequest {
+	reqInfo.RLock()
+	doc := &AuthzRequest{
+		Operation:   reqInfo.API,
+		Method:      r.Method,
+		Bucket:      reqInfo.BucketName,
+		Object:      reqInfo.ObjectName,
+		OwnerID:     reqInfo.OwnerID,
+		AccessKeyID: reqInfo.AccessKeyID,
+	}
+	reqInfo.RUnlock()
+	doc.SourceIP = a.cfg.TrustedProxies.ClientIP(r)
+
+	for _, name := range a.cfg.Headers {
+		if value := r.Header.Get(name); value != "" {
+			if doc.Headers == nil {
+				doc.Headers = make(map[string]string)
+			}
+			doc.Headers[http.CanonicalHeaderKey(name)] = value
+		}
+	}
+
+	return doc
+}
+
+// authorize returns the cached decision or asks the evaluator. Failures are
+// not cached.
+func (a *authorizer) authorize(ctx context.Context, doc *AuthzRequest) (*AuthzDecision, error) {
+	if a.cache == nil {
+		return a.cfg.Evaluator.Evaluate(ctx, doc)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	key := sha256.Sum256(data)
+
+	if entry, err := a.cache.Get(key); err == nil {
+		if decision, ok := entry.(*AuthzDecision); ok {
+			return decision, nil
+		}
+	}
+
+	decision, err := a.cfg.Evaluator.Evaluate(ctx, doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if err = a.cache.SetWithExpire(key, decision, a.cfg.CacheTTL); err != nil {
+		a.log.Warn("couldn't cache authorization decision", zap.Error(err))
+	}
+
+	return decision, nil
+}
+
+// NewWebhookEvaluator returns AuthzEvaluator posting the document as
+// `{"input": <document>}` to the URL and expecting the decision in response.
+func NewWebhookEvaluator(url string, timeout time.Duration) AuthzEvaluator {
+	return &webhookEvaluator{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}
+}
+
+// Evaluate implements AuthzEvaluator interface.
+func (e *webhookEvaluator) Evaluate(ctx context.Context, doc *AuthzRequest) (*AuthzDecision, error) {
+	body, err := json.Marshal(map[string]*AuthzRequest{"input": doc})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("authorization webhook responded with status %d", resp.StatusCode)
+	}
+
+	var res webhookResponse
+	if err = json.NewDecoder(io.LimitReader(resp.Body, maxWebhookResponseSize)).Decode(&res); err != nil {
+		return nil, fmt.Errorf("couldn't decode authorization webhook response: %w", err)
+	}
+
+	if res.Result != nil {
+		return res.Result, nil
+	}
+	return &res.AuthzDecision, nil
+}
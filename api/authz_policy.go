@@ -0,0 +1,201 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	// authzPolicy is a local authorization policy, the first matching rule
+	// makes the decision.
+	authzPolicy struct {
+		Default  string      `yaml:"default"`
+		Timezone string      `yaml:"timezone"`
+		Rules    []authzRule `yaml:"rules"`
+
+		location *time.Location
+		now      func() time.Time
+	}
+
+	// authzRule matches requests by all the set conditions.
+	authzRule struct {
+		Effect     string   `yaml:"effect"`
+		Reason     string   `yaml:"reason"`
+		Operations []string `yaml:"operations"`
+		Methods    []string `yaml:"methods"`
+		Buckets    []string `yaml:"buckets"`
+		Owners     []string `yaml:"owners"`
+		AccessKeys []string `yaml:"access_keys"`
+		// SourceIPs are IP addresses or CIDR networks.
+		SourceIPs []string `yaml:"source_ips"`
+		// Weekdays are the first three letters of English names (Mon, Tue...).
+		Weekdays []string `yaml:"weekdays"`
+		// Hours is a time range like 09:00-18:00, it can cross the midnight.
+		Hours string `yaml:"hours"`
+
+		networks []*net.IPNet
+		weekdays map[time.Weekday]struct{}
+		from, to int
+	}
+)
+
+const (
+	authzEffectAllow = "allow"
+	authzEffectDeny  = "deny"
+)
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseAuthzPolicy parses YAML policy file and returns AuthzEvaluator using it.
+func ParseAuthzPolicy(data []byte) (AuthzEvaluator, error) {
+	p := &authzPolicy{now: time.Now, location: time.Local}
+	if err := yaml.UnmarshalStrict(data, p); err != nil {
+		return nil, fmt.Errorf("couldn't parse authorization policy: %w", err)
+	}
+
+	if p.Default == "" {
+		p.Default = authzEffectAllow
+	}
+	if !validEffect(p.Default) {
+		return nil, fmt.Errorf("unknown default effect '%s'", p.Default)
+	}
+
+	if p.Timezone != "" {
+		loc, err := time.LoadLocation(p.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone: %w", err)
+		}
+		p.location = loc
+	}
+
+	for i := range p.Rules {
+		if err := p.Rules[i].prepare(); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+
+	return p, nil
+}
+
+func validEffect(effect string) bool {
+	return effect == authzEffectAllow || effect == authzEffectDeny
+}
+
+func (r *authzRule) prepare() error {
+	if !validEffect(r.Effect) {
+		return fmt.Errorf("unknown effect '%s'", r.Effect)
+	}
+
+	var err error
+	if r.networks, err = parseNetworks(r.SourceIPs); err != nil {
+		return fmt.Errorf("invalid source IPs: %w", err)
+	}
+
+	if len(r.Weekdays) > 0 {
+		r.weekdays = make(map[time.Weekday]struct{}, len(r.Weekdays))
+		for _, name := range r.Weekdays {
+			day, ok := weekdayNames[strings.ToLower(name)]
+			if !ok {
+				return fmt.Errorf("unknown weekday '%s'", name)
+			}
+			r.weekdays[day] = struct{}{}
+		}
+	}
+
+	if r.Hours != "" {
+		bounds := strings.Split(r.Hours, "-")
+		if len(bounds) != 2 {
+			return fmt.Errorf("invalid hours '%s'", r.Hours)
+		}
+		if r.from, err = parseMinutes(bounds[0]); err != nil {
+			return err
+		}
+		if r.to, err = parseMinutes(bounds[1]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func parseMinutes(s string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("invalid time '%s'", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// Evaluate implements AuthzEvaluator interface.
+func (p *authzPolicy) Evaluate(_ context.Context, doc *AuthzRequest) (*AuthzDecision, error) {
+	now := p.now().In(p.location)
+
+	for i := range p.Rules {
+		if r := &p.Rules[i]; r.matches(doc, now) {
+			return &AuthzDecision{Allow: r.Effect == authzEffectAllow, Reason: r.Reason}, nil
+		}
+	}
+
+	return &AuthzDecision{Allow: p.Default == authzEffectAllow}, nil
+}
+
+func (r *authzRule) matches(doc *AuthzRequest, now time.Time) bool {
+	if !matchesAny(r.Operations, doc.Operation) || !matchesAny(r.Methods, doc.Method) ||
+		!matchesAny(r.Buckets, doc.Bucket) || !matchesAny(r.Owners, doc.OwnerID) ||
+		!matchesAny(r.AccessKeys, doc.AccessKeyID) {
+		return false
+	}
+
+	if len(r.networks) > 0 {
+		ip := net.ParseIP(doc.SourceIP)
+		if ip == nil {
+			return false
+		}
+		var found bool
+		for _, network := range r.networks {
+			if found = network.Contains(ip); found {
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	if r.weekdays != nil {
+		if _, ok := r.weekdays[now.Weekday()]; !ok {
+			return false
+		}
+	}
+
+	if r.Hours != "" {
+		minutes := now.Hour()*60 + now.Minute()
+		if r.from <= r.to {
+			return minutes >= r.from && minutes < r.to
+		}
+		return minutes >= r.from || minutes < r.to
+	}
+
+	return true
+}
+
+// matchesAny checks if the value is in the list, empty list matches everything.
+func matchesAny(list []string, value string) bool {
+	if len(list) == 0 {
+		return true
+	}
+	for _, v := range list {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,187 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type authzEvaluatorMock struct {
+	calls    int
+	last     *AuthzRequest
+	decision *AuthzDecision
+	err      error
+}
+
+func (m *authzEvaluatorMock) Evaluate(_ context.Context, doc *AuthzRequest) (*AuthzDecision, error) {
+	m.calls++
+	m.last = doc
+	return m.decision, m.err
+}
+
+func TestAuthorizer(t *testing.T) {
+	do := func(az Authorizer, owner string) *httptest.ResponseRecorder {
+		h := az.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		r := httptest.NewRequest(http.MethodDelete, "/bucket/object", nil)
+		r = r.WithContext(SetReqInfo(r.Context(), &ReqInfo{API: "DeleteObject", OwnerID: owner, URL: r.URL}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("deny with reason and cache", func(t *testing.T) {
+		ev := &authzEvaluatorMock{decision: &AuthzDecision{Reason: "outside business hours"}}
+		az := NewAuthorizer(&AuthzConfig{Evaluator: ev, CacheTTL: time.Minute}, zap.NewNop())
+
+		w := do(az, "owner1")
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Contains(t, w.Body.String(), "outside business hours")
+
+		do(az, "owner1")
+		require.Equal(t, 1, ev.calls)
+		do(az, "owner2")
+		require.Equal(t, 2, ev.calls)
+	})
+
+	t.Run("allow", func(t *testing.T) {
+		ev := &authzEvaluatorMock{decision: &AuthzDecision{Allow: true}}
+		az := NewAuthorizer(&AuthzConfig{Evaluator: ev}, zap.NewNop())
+		require.Equal(t, http.StatusOK, do(az, "owner").Code)
+		require.Equal(t, http.StatusOK, do(az, "owner").Code)
+		require.Equal(t, 2, ev.calls)
+	})
+
+	t.Run("fail closed", func(t *testing.T) {
+		ev := &authzEvaluatorMock{err: fmt.Errorf("connection refused")}
+		az := NewAuthorizer(&AuthzConfig{Evaluator: ev, CacheTTL: time.Minute}, zap.NewNop())
+		w := do(az, "owner")
+		require.Equal(t, http.StatusForbidden, w.Code)
+		require.Contains(t, w.Body.String(), "authorization service is unavailable")
+
+		do(az, "owner")
+		require.Equal(t, 2, ev.calls)
+	})
+
+	t.Run("fail open", func(t *testing.T) {
+		ev := &authzEvaluatorMock{err: fmt.Errorf("connection refused")}
+		az := NewAuthorizer(&AuthzConfig{Evaluator: ev, FailOpen: true}, zap.NewNop())
+		require.Equal(t, http.StatusOK, do(az, "owner").Code)
+	})
+
+	t.Run("source ip", func(t *testing.T) {
+		proxies, err := ParseTrustedProxies([]string{"10.0.0.1"})
+		require.NoError(t, err)
+		ev := &authzEvaluatorMock{decision: &AuthzDecision{Allow: true}}
+		az := NewAuthorizer(&AuthzConfig{Evaluator: ev, TrustedProxies: proxies}, zap.NewNop())
+		h := az.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+		sourceIP := func(remoteAddr string) string {
+			r := httptest.NewRequest(http.MethodGet, "/bucket", nil)
+			r.RemoteAddr = remoteAddr
+			r.Header.Set(xForwardedFor, "203.0.113.1")
+			r = r.WithContext(SetReqInfo(r.Context(), &ReqInfo{URL: r.URL}))
+			h.ServeHTTP(httptest.NewRecorder(), r)
+			return ev.last.SourceIP
+		}
+
+		require.Equal(t, "192.0.2.1", sourceIP("192.0.2.1:1234"))
+		require.Equal(t, "203.0.113.1", sourceIP("10.0.0.1:1234"))
+	})
+}
+
+func TestWebhookEvaluator(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Input AuthzRequest `json:"input"`
+		}
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+
+		switch body.Input.Bucket {
+		case "plain":
+			_, _ = w.Write([]byte(`{"allow": false, "reason": "plain"}`))
+		case "opa":
+			_, _ = w.Write([]byte(`{"result": {"allow": true}}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer srv.Close()
+
+	ev := NewWebhookEvaluator(srv.URL, time.Second)
+
+	decision, err := ev.Evaluate(context.Background(), &AuthzRequest{Bucket: "plain"})
+	require.NoError(t, err)
+	require.Equal(t, &AuthzDecision{Reason: "plain"}, decision)
+
+	decision, err = ev.Evaluate(context.Background(), &AuthzRequest{Bucket: "opa"})
+	require.NoError(t, err)
+	require.Equal(t, &AuthzDecision{Allow: true}, decision)
+
+	_, err = ev.Evaluate(context.Background(), &AuthzRequest{Bucket: "other"})
+	require.Error(t, err)
+}
+
+func TestAuthzPolicy(t *testing.T) {
+	ev, err := ParseAuthzPolicy([]byte(`
+default: allow
+timezone: UTC
+rules:
+  - effect: deny
+    source_ips: [203.0.113.0/24, 2001:db8::1]
+    reason: bad reputation
+  - effect: allow
+    operations: [DeleteObject]
+    weekdays: [Mon, Tue, Wed, Thu, Fri]
+    hours: 09:00-18:00
+  - effect: deny
+    operations: [DeleteObject]
+    reason: deletes are allowed only in business hours
+`))
+	require.NoError(t, err)
+
+	p := ev.(*authzPolicy)
+	evaluate := func(now string, doc *AuthzRequest) *AuthzDecision {
+		p.now = func() time.Time {
+			tm, err := time.Parse(time.RFC3339, now)
+			require.NoError(t, err)
+			return tm
+		}
+		decision, err := p.Evaluate(context.Background(), doc)
+		require.NoError(t, err)
+		return decision
+	}
+
+	const monday, saturday = "2021-08-02T10:00:00Z", "2021-08-07T10:00:00Z"
+
+	require.Equal(t, &AuthzDecision{Reason: "bad reputation"},
+		evaluate(monday, &AuthzRequest{Operation: "GetObject", SourceIP: "203.0.113.7"}))
+	require.Equal(t, &AuthzDecision{Reason: "bad reputation"},
+		evaluate(monday, &AuthzRequest{Operation: "GetObject", SourceIP: "2001:db8::1"}))
+	require.Equal(t, &AuthzDecision{Allow: true},
+		evaluate(monday, &AuthzRequest{Operation: "DeleteObject", SourceIP: "192.0.2.1"}))
+	require.Equal(t, &AuthzDecision{Reason: "deletes are allowed only in business hours"},
+		evaluate(saturday, &AuthzRequest{Operation: "DeleteObject", SourceIP: "192.0.2.1"}))
+	require.Equal(t, &AuthzDecision{Reason: "deletes are allowed only in business hours"},
+		evaluate("2021-08-02T18:00:00Z", &AuthzRequest{Operation: "DeleteObject", SourceIP: "192.0.2.1"}))
+	require.Equal(t, &AuthzDecision{Allow: true},
+		evaluate(saturday, &AuthzRequest{Operation: "GetObject", SourceIP: "192.0.2.1"}))
+
+	for _, policy := range []string{
+		"default: maybe",
+		"rules: [{effect: permit}]",
+		"rules: [{effect: deny, source_ips: [bad]}]",
+		"rules: [{effect: deny, weekdays: [Someday]}]",
+		"rules: [{effect: deny, hours: '9-18'}]",
+		"unknown: field",
+	} {
+		_, err = ParseAuthzPolicy([]byte(policy))
+		require.Error(t, err, policy)
+	}
+}
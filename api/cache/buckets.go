@@ -13,6 +13,7 @@ type (
 		Get(key string) *api.BucketInfo
 		Put(bkt *api.BucketInfo) error
 		Delete(key string) bool
+		Stats() Stats
 	}
 
 	// GetBucketCache contains cache with objects and lifetime of cache entries.
@@ -53,3 +54,8 @@ func (o *GetBucketCache) Put(bkt *api.BucketInfo) error {
 func (o *GetBucketCache) Delete(key string) bool {
 	return o.cache.Remove(key)
 }
+
+// Stats returns cache usage statistics.
+func (o *GetBucketCache) Stats() Stats {
+	return statsOf(o.cache)
+}
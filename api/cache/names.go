@@ -14,6 +14,7 @@ type ObjectsNameCache interface {
 	Get(key string) *object.Address
 	Put(key string, address *object.Address) error
 	Delete(key string) bool
+	Stats() Stats
 }
 
 type (
@@ -55,3 +56,8 @@ func (o *NameCache) Put(key string, address *object.Address) error {
 func (o *NameCache) Delete(key string) bool {
 	return o.cache.Remove(key)
 }
+
+// Stats returns cache usage statistics.
+func (o *NameCache) Stats() Stats {
+	return statsOf(o.cache)
+}
@@ -12,6 +12,7 @@ type ObjectsCache interface {
 	Get(address *object.Address) *object.Object
 	Put(obj object.Object) error
 	Delete(address *object.Address) bool
+	Stats() Stats
 }
 
 const (
@@ -60,3 +61,8 @@ func (o *ObjectHeadersCache) Put(obj object.Object) error {
 func (o *ObjectHeadersCache) Delete(address *object.Address) bool {
 	return o.cache.Remove(address.String())
 }
+
+// Stats returns cache usage statistics.
+func (o *ObjectHeadersCache) Stats() Stats {
+	return statsOf(o.cache)
+}
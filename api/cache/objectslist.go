@@ -29,6 +29,7 @@ type (
 		Get(key ObjectsListKey) []*object.ID
 		Put(key ObjectsListKey, oids []*object.ID) error
 		CleanCacheEntriesContainingObject(objectName string, cid *cid.ID)
+		Stats() Stats
 	}
 )
 
@@ -111,3 +112,8 @@ func CreateObjectsListCacheKey(cid *cid.ID, prefix string) ObjectsListKey {
 
 	return p
 }
+
+// Stats returns cache usage statistics.
+func (l *ListObjectsCache) Stats() Stats {
+	return statsOf(l.cache)
+}
@@ -0,0 +1,18 @@
+package cache
+
+import "github.com/bluele/gcache"
+
+// Stats contains cache usage statistics.
+type Stats struct {
+	Size      int    `json:"size"`
+	HitCount  uint64 `json:"hit_count"`
+	MissCount uint64 `json:"miss_count"`
+}
+
+func statsOf(gc gcache.Cache) Stats {
+	return Stats{
+		Size:      gc.Len(false),
+		HitCount:  gc.HitCount(),
+		MissCount: gc.MissCount(),
+	}
+}
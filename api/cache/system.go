@@ -15,6 +15,7 @@ type (
 		Get(key string) *object.Object
 		Put(key string, obj *object.Object) error
 		Delete(key string) bool
+		Stats() Stats
 	}
 
 	// SysCache contains cache with objects and lifetime of cache entries.
@@ -55,3 +56,8 @@ func (o *SysCache) Put(key string, obj *object.Object) error {
 func (o *SysCache) Delete(key string) bool {
 	return o.cache.Remove(key)
 }
+
+// Stats returns cache usage statistics.
+func (o *SysCache) Stats() Stats {
+	return statsOf(o.cache)
+}
@@ -0,0 +1,55 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+)
+
+// TrustedProxies contains networks of the proxies which are trusted to pass
+// the client address in X-Forwarded-For, X-Real-IP and Forwarded headers.
+type TrustedProxies []*net.IPNet
+
+// ParseTrustedProxies parses addresses and networks of the trusted proxies.
+func ParseTrustedProxies(list []string) (TrustedProxies, error) {
+	return parseNetworks(list)
+}
+
+// parseNetworks parses networks in CIDR notation, single addresses are
+// turned into networks of one address.
+func parseNetworks(list []string) ([]*net.IPNet, error) {
+	res := make([]*net.IPNet, 0, len(list))
+	for _, src := range list {
+		if ip := net.ParseIP(src); ip != nil {
+			res = append(res, &net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(src)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address '%s'", src)
+		}
+		res = append(res, network)
+	}
+
+	return res, nil
+}
+
+// ClientIP returns the address of the client. Forwarding headers are
+// respected only if the request comes from a trusted proxy, otherwise any
+// client could set them to pretend to be another one.
+func (t TrustedProxies) ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		for _, network := range t {
+			if network.Contains(ip) {
+				return GetSourceIP(r)
+			}
+		}
+	}
+
+	return host
+}
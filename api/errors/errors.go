@@ -210,6 +210,7 @@ const (
 	ErrAdminBucketQuotaExceeded
 	ErrAdminNoSuchQuotaConfiguration
 	ErrAdminBucketQuotaDisabled
+	ErrQuotaExceeded
 
 	ErrHealNotImplemented
 	ErrHealNoSuchProcess
@@ -1279,6 +1280,12 @@ var errorCodes = errorCodeMap{
 		Description:    "Quota specified but disk usage crawl is disabled on MinIO server",
 		HTTPStatusCode: http.StatusBadRequest,
 	},
+	ErrQuotaExceeded: {
+		ErrCode:        ErrQuotaExceeded,
+		Code:           "QuotaExceeded",
+		Description:    "The request would exceed the size or object count quota of the bucket",
+		HTTPStatusCode: http.StatusForbidden,
+	},
 	ErrInsecureClientRequest: {
 		ErrCode:        ErrInsecureClientRequest,
 		Code:           "XMinioInsecureClientRequest",
@@ -1945,6 +1952,12 @@ func GetAPIError(code ErrorCode) Error {
 	return errorCodes.toAPIErr(ErrInternalError)
 }
 
+// GetAPIErrorWithError provides API Error for input API error code with
+// the reason appended to the description.
+func GetAPIErrorWithError(code ErrorCode, err error) Error {
+	return errorCodes.toAPIErrWithErr(code, err)
+}
+
 // ObjectError - error that linked to specific object.
 type ObjectError struct {
 	Err     error
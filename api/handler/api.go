@@ -2,6 +2,7 @@ package handler
 
 import (
 	"errors"
+	"sync"
 
 	"github.com/nspcc-dev/neofs-api-go/pkg/netmap"
 	"github.com/nspcc-dev/neofs-s3-gw/api"
@@ -18,7 +19,10 @@ type (
 
 	// Config contains data which handler need to keep.
 	Config struct {
+		mu            sync.RWMutex
 		DefaultPolicy *netmap.PlacementPolicy
+		// QuotaAdmins are access key IDs allowed to set bucket quotas.
+		QuotaAdmins map[string]struct{}
 	}
 )
 
@@ -42,3 +46,17 @@ func New(log *zap.Logger, obj layer.Client, cfg *Config) (api.Handler, error) {
 		cfg: cfg,
 	}, nil
 }
+
+// SetDefaultPolicy replaces the default placement policy of new containers.
+func (c *Config) SetDefaultPolicy(p *netmap.PlacementPolicy) {
+	c.mu.Lock()
+	c.DefaultPolicy = p
+	c.mu.Unlock()
+}
+
+func (c *Config) defaultPolicy() *netmap.PlacementPolicy {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.DefaultPolicy
+}
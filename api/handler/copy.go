@@ -90,20 +90,26 @@ func (h *handler) CopyObjectHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if metadata == nil {
+		metadata = make(map[string]string, len(info.Headers)+1)
+		for key, val := range info.Headers {
+			if !layer.IsSystemHeader(key) {
+				metadata[key] = val
+			}
+		}
 		if len(info.ContentType) > 0 {
-			info.Headers[api.ContentType] = info.ContentType
+			metadata[api.ContentType] = info.ContentType
 		}
-		metadata = info.Headers
 	} else if contentType := r.Header.Get(api.ContentType); len(contentType) > 0 {
 		metadata[api.ContentType] = contentType
 	}
 
 	params := &layer.CopyObjectParams{
-		SrcObject: info,
-		DstBucket: reqInfo.BucketName,
-		DstObject: reqInfo.ObjectName,
-		SrcSize:   info.Size,
-		Header:    metadata,
+		SrcObject:  info,
+		DstBucket:  reqInfo.BucketName,
+		DstObject:  reqInfo.ObjectName,
+		SrcSize:    info.Size,
+		Header:     metadata,
+		Conditions: parsePutConditions(r.Header),
 	}
 
 	additional := []zap.Field{zap.String("src_bucket_name", srcBucket), zap.String("src_object_name", srcObject)}
@@ -3,6 +3,7 @@ package handler
 import (
 	"encoding/xml"
 	"net/http"
+	"strconv"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
@@ -25,6 +26,13 @@ type ObjectIdentifier struct {
 	VersionID  string `xml:"VersionId,omitempty"`
 }
 
+// DeletedObject carries key name and version of the deleted object.
+type DeletedObject struct {
+	ObjectIdentifier
+	DeleteMarker          bool   `xml:"DeleteMarker,omitempty"`
+	DeleteMarkerVersionID string `xml:"DeleteMarkerVersionId,omitempty"`
+}
+
 // DeleteError structure.
 type DeleteError struct {
 	Code      string
@@ -38,7 +46,7 @@ type DeleteObjectsResponse struct {
 	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ DeleteResult" json:"-"`
 
 	// Collection of all deleted objects
-	DeletedObjects []ObjectIdentifier `xml:"Deleted,omitempty"`
+	DeletedObjects []DeletedObject `xml:"Deleted,omitempty"`
 
 	// Collection of errors deleting certain objects.
 	Errors []DeleteError `xml:"Error,omitempty"`
@@ -70,6 +78,11 @@ func (h *handler) DeleteObjectHandler(w http.ResponseWriter, r *http.Request) {
 		// 	Description:    err.Error(),
 		// 	HTTPStatusCode: http.StatusInternalServerError,
 		// }, r.URL)
+	} else if deleted := versionedObject[0]; deleted.DeleteMarkVersion != "" {
+		w.Header().Set(api.AmzDeleteMarker, strconv.FormatBool(true))
+		w.Header().Set(api.AmzVersionID, deleted.DeleteMarkVersion)
+	} else if deleted.VersionID != "" {
+		w.Header().Set(api.AmzVersionID, deleted.VersionID)
 	}
 
 	w.WriteHeader(http.StatusNoContent)
@@ -113,7 +126,7 @@ func (h *handler) DeleteMultipleObjectsHandler(w http.ResponseWriter, r *http.Re
 
 	response := &DeleteObjectsResponse{
 		Errors:         make([]DeleteError, 0, len(toRemove)),
-		DeletedObjects: make([]ObjectIdentifier, 0, len(toRemove)),
+		DeletedObjects: make([]DeletedObject, 0, len(toRemove)),
 	}
 
 	if err := h.checkBucketOwner(r, reqInfo.BucketName); err != nil {
@@ -155,7 +168,11 @@ func (h *handler) DeleteMultipleObjectsHandler(w http.ResponseWriter, r *http.Re
 	}
 
 	for _, val := range removed {
-		response.DeletedObjects = append(response.DeletedObjects, ObjectIdentifier{ObjectName: val.Name, VersionID: val.VersionID})
+		response.DeletedObjects = append(response.DeletedObjects, DeletedObject{
+			ObjectIdentifier:      ObjectIdentifier{ObjectName: val.Name, VersionID: val.VersionID},
+			DeleteMarker:          val.DeleteMarkVersion != "",
+			DeleteMarkerVersionID: val.DeleteMarkVersion,
+		})
 	}
 
 	if err := api.EncodeToResponse(w, response); err != nil {
@@ -72,14 +72,22 @@ func writeHeaders(h http.Header, info *api.ObjectInfo, tagSetLength int) {
 	h.Set(api.LastModified, info.Created.UTC().Format(http.TimeFormat))
 	h.Set(api.ContentLength, strconv.FormatInt(info.Size, 10))
 	h.Set(api.ETag, info.HashSum)
-	h.Set(api.AmzVersionID, info.ID.String())
+	h.Set(api.AmzVersionID, info.Version())
 	h.Set(api.AmzTaggingCount, strconv.Itoa(tagSetLength))
 
 	for key, val := range info.Headers {
-		h[api.MetadataPrefix+key] = []string{val}
+		if !layer.IsSystemHeader(key) {
+			h[api.MetadataPrefix+key] = []string{val}
+		}
 	}
 }
 
+func writeDeleteMarkerHeaders(h http.Header, info *api.ObjectInfo) {
+	h.Set(api.AmzDeleteMarker, strconv.FormatBool(true))
+	h.Set(api.AmzVersionID, info.Version())
+	h.Set(api.LastModified, info.Created.UTC().Format(http.TimeFormat))
+}
+
 func (h *handler) GetObjectHandler(w http.ResponseWriter, r *http.Request) {
 	var (
 		err    error
@@ -111,6 +119,12 @@ func (h *handler) GetObjectHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if info.IsDeleteMarker {
+		writeDeleteMarkerHeaders(w.Header(), info)
+		h.logAndSendError(w, "object version is a delete marker", reqInfo, errors.GetAPIError(errors.ErrMethodNotAllowed))
+		return
+	}
+
 	if err = checkPreconditions(info, args.Conditional); err != nil {
 		h.logAndSendError(w, "precondition failed", reqInfo, err)
 		return
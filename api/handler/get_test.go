@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
@@ -144,3 +145,19 @@ func TestPreconditions(t *testing.T) {
 		})
 	}
 }
+
+func TestWriteHeadersVersionID(t *testing.T) {
+	oid := object.NewID()
+
+	h := make(http.Header)
+	writeHeaders(h, &api.ObjectInfo{ID: oid}, 0)
+	require.Equal(t, oid.String(), h.Get(api.AmzVersionID))
+
+	h = make(http.Header)
+	writeHeaders(h, &api.ObjectInfo{ID: oid, NullVersion: true}, 0)
+	require.Equal(t, api.NullVersionID, h.Get(api.AmzVersionID))
+
+	h = make(http.Header)
+	writeDeleteMarkerHeaders(h, &api.ObjectInfo{ID: oid, NullVersion: true})
+	require.Equal(t, api.NullVersionID, h.Get(api.AmzVersionID))
+}
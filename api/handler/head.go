@@ -47,6 +47,12 @@ func (h *handler) HeadObjectHandler(w http.ResponseWriter, r *http.Request) {
 		h.logAndSendError(w, "could not fetch object info", reqInfo, err)
 		return
 	}
+
+	if info.IsDeleteMarker {
+		writeDeleteMarkerHeaders(w.Header(), info)
+		h.logAndSendError(w, "object version is a delete marker", reqInfo, errors.GetAPIError(errors.ErrMethodNotAllowed))
+		return
+	}
 	tagSet, err := h.obj.GetObjectTagging(r.Context(), info)
 	if err != nil && !errors.IsS3Error(err, errors.ErrNoSuchKey) {
 		h.logAndSendError(w, "could not get object tag set", reqInfo, err)
@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-api-go/pkg/container"
+	"github.com/nspcc-dev/neofs-api-go/pkg/token"
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/memory"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestObjectHeadersWithoutSystemMetadata(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	ctx := context.WithValue(context.Background(), api.BoxData, &accessbox.Box{
+		Gate: &accessbox.GateData{BearerToken: token.NewBearerToken(), GateKey: key.PublicKey()},
+	})
+	p, err := memory.NewPool(key)
+	require.NoError(t, err)
+	_, err = p.PutContainer(ctx, container.New(container.WithAttribute(container.AttributeName, "bucket")))
+	require.NoError(t, err)
+
+	obj := layer.NewLayer(zap.NewNop(), p, &layer.Config{
+		Caches: &layer.CacheConfig{
+			Size:                cache.DefaultObjectsCacheSize,
+			Lifetime:            cache.DefaultObjectsCacheLifetime,
+			ListObjectsLifetime: cache.DefaultObjectsListCacheLifetime,
+			ListObjectsSize:     cache.DefaultObjectsListCacheSize,
+		},
+		SessionFallback: layer.SessionFallbackGateway,
+	})
+	h := &handler{log: zap.NewNop(), obj: obj, cfg: new(Config)}
+
+	_, err = obj.PutBucketVersioning(ctx, &layer.PutVersioningParams{
+		Bucket:   "bucket",
+		Settings: &layer.BucketSettings{VersioningStatus: layer.VersioningEnabled},
+	})
+	require.NoError(t, err)
+	for _, content := range []string{"first", "second"} {
+		_, err = obj.PutObject(ctx, &layer.PutObjectParams{
+			Bucket: "bucket",
+			Object: "object",
+			Size:   int64(len(content)),
+			Reader: bytes.NewReader([]byte(content)),
+			Header: map[string]string{"Color": "red"},
+		})
+		require.NoError(t, err)
+	}
+
+	request := func(method, object string, header http.Header) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/bucket/"+object, nil)
+		for key, val := range header {
+			r.Header[key] = val
+		}
+		w := httptest.NewRecorder()
+		r = r.WithContext(api.SetReqInfo(ctx, api.NewReqInfo(w, r, api.ObjectRequest{Bucket: "bucket", Object: object})))
+
+		switch method {
+		case http.MethodHead:
+			h.HeadObjectHandler(w, r)
+		case http.MethodGet:
+			h.GetObjectHandler(w, r)
+		case http.MethodPut:
+			h.CopyObjectHandler(w, r)
+		}
+		require.Equal(t, http.StatusOK, w.Code)
+		return w
+	}
+
+	requireUserMetadata := func(t *testing.T, header http.Header) {
+		for key := range header {
+			require.False(t, strings.HasPrefix(key, api.MetadataPrefix+"S3-"), key)
+			require.False(t, strings.HasPrefix(key, api.MetadataPrefix+"__NEOFS__"), key)
+		}
+		require.Equal(t, "red", header.Get(api.MetadataPrefix+"Color"))
+	}
+
+	t.Run("head", func(t *testing.T) {
+		requireUserMetadata(t, request(http.MethodHead, "object", nil).Header())
+	})
+	t.Run("get", func(t *testing.T) {
+		requireUserMetadata(t, request(http.MethodGet, "object", nil).Header())
+	})
+	t.Run("copy", func(t *testing.T) {
+		request(http.MethodPut, "copy", http.Header{"X-Amz-Copy-Source": []string{"/bucket/object"}})
+		requireUserMetadata(t, request(http.MethodHead, "copy", nil).Header())
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/nspcc-dev/neofs-api-go/pkg/object"
@@ -102,6 +103,69 @@ func encodeV2(p *layer.ListObjectsParamsV2, list *layer.ListObjectsInfoV2) *List
 	return res
 }
 
+// ListObjectsV2MHandler handles objects listing requests for API version 2 with metadata.
+// It's a MinIO extension which allows getting objects metadata without heading every object.
+func (h *handler) ListObjectsV2MHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+	params, err := parseListObjectsArgsV2(reqInfo)
+	if err != nil {
+		h.logAndSendError(w, "failed to parse arguments", reqInfo, err)
+		return
+	}
+
+	if err = h.checkBucketOwner(r, reqInfo.BucketName); err != nil {
+		h.logAndSendError(w, "expected owner doesn't match", reqInfo, err)
+		return
+	}
+
+	list, err := h.obj.ListObjectsV2(r.Context(), params)
+	if err != nil {
+		h.logAndSendError(w, "something went wrong", reqInfo, err)
+		return
+	}
+
+	tagSets, err := h.obj.GetObjectsTagging(r.Context(), list.Objects)
+	if err != nil {
+		h.logAndSendError(w, "could not get objects tagging", reqInfo, err)
+		return
+	}
+
+	response := encodeV2(params, list)
+	for i, obj := range list.Objects {
+		fillObjectMetadata(&response.Contents[i], obj, tagSets[i])
+	}
+
+	if err = api.EncodeToResponse(w, response); err != nil {
+		h.logAndSendError(w, "something went wrong", reqInfo, err)
+	}
+}
+
+func fillObjectMetadata(dst *Object, obj *api.ObjectInfo, tagSet map[string]string) {
+	dst.UserMetadata = make(StringMap, len(obj.Headers)+1)
+	for key, val := range obj.Headers {
+		if !layer.IsSystemHeader(key) {
+			dst.UserMetadata[api.MetadataPrefix+key] = val
+		}
+	}
+	if len(obj.ContentType) > 0 {
+		dst.UserMetadata[strings.ToLower(api.ContentType)] = obj.ContentType
+	}
+
+	if len(tagSet) != 0 {
+		tags := make(url.Values, len(tagSet))
+		for key, val := range tagSet {
+			tags.Set(key, val)
+		}
+		dst.UserTags = tags.Encode()
+	}
+
+	dst.Internal = &ObjectInternalInfo{
+		ContainerID:   obj.CID.String(),
+		ObjectID:      obj.ID.String(),
+		CreationEpoch: obj.CreationEpoch,
+	}
+}
+
 func parseListObjectsArgsV1(reqInfo *api.ReqInfo) (*layer.ListObjectsParamsV1, error) {
 	var (
 		res         layer.ListObjectsParamsV1
@@ -253,7 +317,7 @@ func parseListObjectVersionsRequest(reqInfo *api.ReqInfo) (*layer.ListObjectVers
 	}
 
 	res.Prefix = queryValues.Get("prefix")
-	res.KeyMarker = queryValues.Get("marker")
+	res.KeyMarker = queryValues.Get("key-marker")
 	res.Delimiter = queryValues.Get("delimiter")
 	res.Encode = queryValues.Get("encoding-type")
 	res.VersionIDMarker = queryValues.Get("version-id-marker")
@@ -290,7 +354,6 @@ func encodeListObjectVersionsToResponse(info *layer.ListObjectVersionsInfo, buck
 			ETag:      ver.Object.HashSum,
 		})
 	}
-	// this loop is not starting till versioning is not implemented
 	for _, del := range info.DeleteMarker {
 		res.DeleteMarker = append(res.DeleteMarker, DeleteMarkerEntry{
 			IsLatest:     del.IsLatest,
@@ -1,8 +1,14 @@
 package handler
 
 import (
+	"encoding/xml"
 	"testing"
 
+	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"github.com/nspcc-dev/neofs-api-go/pkg/owner"
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
 	"github.com/stretchr/testify/require"
 )
 
@@ -35,3 +41,54 @@ func TestParseContinuationToken(t *testing.T) {
 		require.Equal(t, tokenStr, token)
 	})
 }
+
+func TestFillObjectMetadata(t *testing.T) {
+	var (
+		res Object
+		oid = object.NewID()
+		cnr = cid.New()
+	)
+
+	obj := &api.ObjectInfo{
+		ID:            oid,
+		CID:           cnr,
+		ContentType:   "text/plain",
+		CreationEpoch: 10,
+		Headers: map[string]string{
+			"color":           "red",
+			"S3-Versions-add": "some-version",
+		},
+	}
+
+	fillObjectMetadata(&res, obj, map[string]string{"key": "val ue"})
+	require.Equal(t, StringMap{
+		api.MetadataPrefix + "color": "red",
+		"content-type":               "text/plain",
+	}, res.UserMetadata)
+	require.Equal(t, "key=val+ue", res.UserTags)
+	require.Equal(t, &ObjectInternalInfo{
+		ContainerID:   cnr.String(),
+		ObjectID:      oid.String(),
+		CreationEpoch: 10,
+	}, res.Internal)
+
+	data, err := xml.Marshal(res)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "<X-Amz-Meta-color>red</X-Amz-Meta-color>")
+	require.Contains(t, string(data), "<content-type>text/plain</content-type>")
+}
+
+func TestEncodeListObjectVersionsNull(t *testing.T) {
+	versioned := &api.ObjectInfo{ID: object.NewID(), Name: "obj", Owner: owner.NewID()}
+	null := &api.ObjectInfo{ID: object.NewID(), Name: "obj", Owner: owner.NewID(), NullVersion: true}
+	nullMarker := &api.ObjectInfo{ID: object.NewID(), Name: "obj", Owner: owner.NewID(), NullVersion: true, IsDeleteMarker: true}
+
+	res := encodeListObjectVersionsToResponse(&layer.ListObjectVersionsInfo{
+		Version:      []*layer.ObjectVersionInfo{{Object: versioned}, {Object: null}},
+		DeleteMarker: []*layer.ObjectVersionInfo{{Object: nullMarker, IsLatest: true}},
+	}, "bucket")
+
+	require.Equal(t, versioned.ID.String(), res.Version[0].VersionID)
+	require.Equal(t, api.NullVersionID, res.Version[1].VersionID)
+	require.Equal(t, api.NullVersionID, res.DeleteMarker[0].VersionID)
+}
@@ -194,11 +194,12 @@ func (h *handler) PutObjectHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	params := &layer.PutObjectParams{
-		Bucket: reqInfo.BucketName,
-		Object: reqInfo.ObjectName,
-		Reader: r.Body,
-		Size:   r.ContentLength,
-		Header: metadata,
+		Bucket:     reqInfo.BucketName,
+		Object:     reqInfo.ObjectName,
+		Reader:     r.Body,
+		Size:       r.ContentLength,
+		Header:     metadata,
+		Conditions: parsePutConditions(r.Header),
 	}
 
 	info, err := h.obj.PutObject(r.Context(), params)
@@ -235,7 +236,7 @@ func (h *handler) PutObjectHandler(w http.ResponseWriter, r *http.Request) {
 
 	if versioning, err := h.obj.GetBucketVersioning(r.Context(), reqInfo.BucketName); err != nil {
 		h.log.Warn("couldn't get bucket versioning", zap.String("bucket name", reqInfo.BucketName), zap.Error(err))
-	} else if versioning.VersioningEnabled {
+	} else if versioning.VersioningEnabled() {
 		w.Header().Set(api.AmzVersionID, info.Version())
 	}
 
@@ -333,7 +334,7 @@ func (h *handler) PostObject(w http.ResponseWriter, r *http.Request) {
 
 	if versioning, err := h.obj.GetBucketVersioning(r.Context(), reqInfo.BucketName); err != nil {
 		h.log.Warn("couldn't get bucket versioning", zap.String("bucket name", reqInfo.BucketName), zap.Error(err))
-	} else if versioning.VersioningEnabled {
+	} else if versioning.VersioningEnabled() {
 		w.Header().Set(api.AmzVersionID, info.Version())
 	}
 
@@ -419,7 +420,7 @@ func containsACLHeaders(r *http.Request) bool {
 		r.Header.Get(api.AmzGrantFullControl) != "" || r.Header.Get(api.AmzGrantWrite) != ""
 }
 
-func (h *handler) getNewEAclTable(r *http.Request, objInfo *layer.ObjectInfo) (*eacl.Table, error) {
+func (h *handler) getNewEAclTable(r *http.Request, objInfo *api.ObjectInfo) (*eacl.Table, error) {
 	var newEaclTable *eacl.Table
 	objectACL, err := parseACLHeaders(r)
 	if err != nil {
@@ -485,6 +486,18 @@ func parseTaggingHeader(header http.Header) (map[string]string, error) {
 	return tagSet, nil
 }
 
+func parsePutConditions(headers http.Header) *layer.PutConditions {
+	cond := &layer.PutConditions{
+		IfMatch:     headers.Get(api.IfMatch),
+		IfNoneMatch: headers.Get(api.IfNoneMatch),
+	}
+	if len(cond.IfMatch) == 0 && len(cond.IfNoneMatch) == 0 {
+		return nil
+	}
+
+	return cond
+}
+
 func parseMetadata(r *http.Request) map[string]string {
 	res := make(map[string]string)
 	for k, v := range r.Header {
@@ -541,7 +554,7 @@ func (h *handler) CreateBucketHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	if p.Policy == nil {
-		p.Policy = h.cfg.DefaultPolicy
+		p.Policy = h.cfg.defaultPolicy()
 	}
 
 	cid, err := h.obj.CreateBucket(r.Context(), &p)
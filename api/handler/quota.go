@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/xml"
+	"net/http"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+)
+
+// PutBucketQuotaHandler sets the quota of the bucket, only quota admins are allowed to do it.
+func (h *handler) PutBucketQuotaHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	if _, ok := h.cfg.QuotaAdmins[reqInfo.AccessKeyID]; !ok || reqInfo.AccessKeyID == "" {
+		h.logAndSendError(w, "only quota admins can set bucket quota", reqInfo, errors.GetAPIError(errors.ErrAccessDenied))
+		return
+	}
+
+	quota := new(BucketQuota)
+	if err := xml.NewDecoder(r.Body).Decode(quota); err != nil {
+		h.logAndSendError(w, "couldn't decode bucket quota", reqInfo, errors.GetAPIError(errors.ErrMalformedXML))
+		return
+	}
+
+	p := &layer.PutBucketQuotaParams{
+		Bucket: reqInfo.BucketName,
+		Quota: layer.BucketQuota{
+			MaxBytes:   quota.MaxSize,
+			MaxObjects: quota.MaxObjects,
+		},
+	}
+
+	if err := h.obj.PutBucketQuota(r.Context(), p); err != nil {
+		h.logAndSendError(w, "couldn't put bucket quota", reqInfo, err)
+	}
+}
+
+// GetBucketQuotaHandler returns the quota of the bucket and its usage.
+func (h *handler) GetBucketQuotaHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+
+	bktInfo, err := h.obj.GetBucketInfo(r.Context(), reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "could not get bucket info", reqInfo, err)
+		return
+	}
+	if err = checkOwner(bktInfo, r.Header.Get(api.AmzExpectedBucketOwner)); err != nil {
+		h.logAndSendError(w, "expected owner doesn't match", reqInfo, err)
+		return
+	}
+
+	info, err := h.obj.GetBucketQuota(r.Context(), reqInfo.BucketName)
+	if err != nil {
+		h.logAndSendError(w, "couldn't get bucket quota", reqInfo, err)
+		return
+	}
+
+	if err = api.EncodeToResponse(w, &BucketQuota{
+		MaxSize:    info.Quota.MaxBytes,
+		MaxObjects: info.Quota.MaxObjects,
+		Size:       info.Usage.Bytes,
+		Objects:    info.Usage.Objects,
+	}); err != nil {
+		h.logAndSendError(w, "something went wrong", reqInfo, err)
+	}
+}
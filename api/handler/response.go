@@ -108,6 +108,18 @@ type Object struct {
 
 	// The class of storage used to store the object.
 	StorageClass string `xml:"StorageClass,omitempty"`
+
+	// Fields below are filled only in ListObjectsV2M response.
+	UserMetadata StringMap           `xml:"UserMetadata,omitempty"`
+	UserTags     string              `xml:"UserTags,omitempty"`
+	Internal     *ObjectInternalInfo `xml:"Internal,omitempty"`
+}
+
+// ObjectInternalInfo contains NeoFS specific object info for ListObjectsV2M response.
+type ObjectInternalInfo struct {
+	ContainerID   string `xml:"ContainerID"`
+	ObjectID      string `xml:"ObjectID"`
+	CreationEpoch uint64 `xml:"CreationEpoch"`
 }
 
 // ObjectVersionResponse container for object version in the response of ListBucketObjectVersionsHandler.
@@ -169,6 +181,16 @@ type VersioningConfiguration struct {
 	MfaDelete string   `xml:"MfaDelete,omitempty"`
 }
 
+// BucketQuota contains quota of the bucket and its usage, NeoFS extension.
+// Usage is ignored when the quota is set.
+type BucketQuota struct {
+	XMLName    xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ BucketQuota"`
+	MaxSize    uint64   `xml:"MaxSize"`
+	MaxObjects uint64   `xml:"MaxObjects"`
+	Size       uint64   `xml:"Size,omitempty"`
+	Objects    uint64   `xml:"Objects,omitempty"`
+}
+
 // Tagging contains tag set.
 type Tagging struct {
 	XMLName xml.Name `xml:"http://s3.amazonaws.com/doc/2006-03-01/ Tagging"`
@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+)
+
+type (
+	// SearchRequest is a body of NeoFS search extension request.
+	SearchRequest struct {
+		XMLName xml.Name       `xml:"SearchRequest" json:"-"`
+		Filters []SearchFilter `xml:"Filter" json:"Filters"`
+	}
+
+	// SearchFilter is a filter on user metadata of an object.
+	SearchFilter struct {
+		Key   string `xml:"Key" json:"Key"`
+		Value string `xml:"Value" json:"Value"`
+		Match string `xml:"Match,omitempty" json:"Match,omitempty"`
+	}
+)
+
+// SearchObjectsHandler handles search of objects by user metadata. It's a NeoFS extension
+// of S3 API, filters are passed in request body and results are returned in ListObjectsV2 format.
+func (h *handler) SearchObjectsHandler(w http.ResponseWriter, r *http.Request) {
+	reqInfo := api.GetReqInfo(r.Context())
+	params, err := parseListObjectsArgsV2(reqInfo)
+	if err != nil {
+		h.logAndSendError(w, "failed to parse arguments", reqInfo, err)
+		return
+	}
+
+	filters, err := readSearchFilters(r.Body, r.Header.Get(api.ContentType))
+	if err != nil {
+		h.logAndSendError(w, "could not read search filters", reqInfo, err)
+		return
+	}
+
+	if err = h.checkBucketOwner(r, reqInfo.BucketName); err != nil {
+		h.logAndSendError(w, "expected owner doesn't match", reqInfo, err)
+		return
+	}
+
+	list, err := h.obj.SearchObjects(r.Context(), &layer.SearchObjectsParams{
+		ListObjectsParamsV2: *params,
+		Filters:             filters,
+	})
+	if err != nil {
+		h.logAndSendError(w, "could not search objects", reqInfo, err)
+		return
+	}
+
+	if err = api.EncodeToResponse(w, encodeV2(params, list)); err != nil {
+		h.logAndSendError(w, "something went wrong", reqInfo, err)
+	}
+}
+
+func readSearchFilters(reader io.Reader, contentType string) ([]layer.SearchFilter, error) {
+	req := &SearchRequest{}
+	if strings.HasPrefix(contentType, "application/json") {
+		if err := json.NewDecoder(reader).Decode(req); err != nil {
+			return nil, errors.GetAPIError(errors.ErrInvalidArgument)
+		}
+	} else if err := xml.NewDecoder(reader).Decode(req); err != nil {
+		return nil, errors.GetAPIError(errors.ErrMalformedXML)
+	}
+
+	if len(req.Filters) == 0 {
+		return nil, errors.GetAPIError(errors.ErrInvalidArgument)
+	}
+
+	filters := make([]layer.SearchFilter, 0, len(req.Filters))
+	for _, f := range req.Filters {
+		match := layer.SearchMatchType(f.Match)
+		switch match {
+		case "", layer.SearchMatchStringEqual, layer.SearchMatchStringNotEqual, layer.SearchMatchCommonPrefix:
+		default:
+			return nil, errors.GetAPIError(errors.ErrInvalidArgument)
+		}
+		if f.Key == "" {
+			return nil, errors.GetAPIError(errors.ErrInvalidArgument)
+		}
+
+		filters = append(filters, layer.SearchFilter{
+			Key:   f.Key,
+			Value: f.Value,
+			Match: match,
+		})
+	}
+
+	return filters, nil
+}
@@ -19,9 +19,14 @@ func (h *handler) PutBucketVersioningHandler(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if configuration.Status != layer.VersioningEnabled && configuration.Status != layer.VersioningSuspended {
+		h.logAndSendError(w, "invalid versioning status", reqInfo, errors.GetAPIError(errors.ErrIllegalVersioningConfigurationException))
+		return
+	}
+
 	p := &layer.PutVersioningParams{
 		Bucket:   reqInfo.BucketName,
-		Settings: &layer.BucketSettings{VersioningEnabled: configuration.Status == "Enabled"},
+		Settings: &layer.BucketSettings{VersioningStatus: configuration.Status},
 	}
 
 	bktInfo, err := h.obj.GetBucketInfo(r.Context(), reqInfo.BucketName)
@@ -76,10 +81,9 @@ func formVersioningConfiguration(settings *layer.BucketSettings) *VersioningConf
 	if settings == nil {
 		return res
 	}
-	if settings.VersioningEnabled {
-		res.Status = "Enabled"
-	} else {
-		res.Status = "Suspended"
+	// Status isn't returned if versioning has never been enabled for the bucket.
+	if settings.VersioningEnabled() || settings.VersioningSuspended() {
+		res.Status = settings.VersioningStatus
 	}
 	return res
 }
@@ -5,6 +5,7 @@ const (
 	MetadataPrefix       = "X-Amz-Meta-"
 	AmzMetadataDirective = "X-Amz-Metadata-Directive"
 	AmzVersionID         = "X-Amz-Version-Id"
+	AmzDeleteMarker      = "X-Amz-Delete-Marker"
 	AmzTaggingCount      = "X-Amz-Tagging-Count"
 	AmzTagging           = "X-Amz-Tagging"
 
@@ -0,0 +1,155 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+)
+
+type (
+	// InFlight tracks requests being served and rejects new ones
+	// once the gateway is drained.
+	InFlight struct {
+		mu       sync.Mutex
+		requests map[*ReqInfo]time.Time
+		draining bool
+		// idle is closed when there are no requests being served.
+		idle chan struct{}
+	}
+
+	// InFlightRequest describes a request being served.
+	InFlightRequest struct {
+		RequestID   string    `json:"request_id"`
+		API         string    `json:"operation"`
+		Bucket      string    `json:"bucket,omitempty"`
+		Object      string    `json:"key,omitempty"`
+		AccessKeyID string    `json:"access_key_id,omitempty"`
+		SourceIP    string    `json:"source_ip"`
+		Started     time.Time `json:"started"`
+	}
+)
+
+// NewInFlight returns InFlight which accepts requests.
+func NewInFlight() *InFlight {
+	idle := make(chan struct{})
+	close(idle)
+
+	return &InFlight{
+		requests: make(map[*ReqInfo]time.Time),
+		idle:     idle,
+	}
+}
+
+// Middleware tracks requests, new requests are rejected with
+// ServiceUnavailable error while draining.
+func (f *InFlight) Middleware(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqInfo := GetReqInfo(r.Context())
+
+		if !f.add(reqInfo) {
+			// let the client reconnect to another instance
+			w.Header().Set("Connection", "close")
+			WriteErrorResponse(w, reqInfo, errors.GetAPIError(errors.ErrServerNotInitialized))
+			return
+		}
+		defer f.remove(reqInfo)
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (f *InFlight) add(reqInfo *ReqInfo) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.draining {
+		return false
+	}
+
+	if len(f.requests) == 0 {
+		f.idle = make(chan struct{})
+	}
+	f.requests[reqInfo] = time.Now()
+
+	return true
+}
+
+func (f *InFlight) remove(reqInfo *ReqInfo) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	delete(f.requests, reqInfo)
+	if len(f.requests) == 0 {
+		close(f.idle)
+	}
+}
+
+// List returns requests being served, the oldest go first.
+func (f *InFlight) List() []InFlightRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	res := make([]InFlightRequest, 0, len(f.requests))
+	for reqInfo, started := range f.requests {
+		reqInfo.RLock()
+		res = append(res, InFlightRequest{
+			RequestID:   reqInfo.RequestID,
+			API:         reqInfo.API,
+			Bucket:      reqInfo.BucketName,
+			Object:      reqInfo.ObjectName,
+			AccessKeyID: reqInfo.AccessKeyID,
+			SourceIP:    reqInfo.RemoteHost,
+			Started:     started,
+		})
+		reqInfo.RUnlock()
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Started.Before(res[j].Started)
+	})
+
+	return res
+}
+
+// Count returns the number of requests being served.
+func (f *InFlight) Count() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.requests)
+}
+
+// Drain stops accepting new requests and waits for the ones being served
+// until ctx is done.
+func (f *InFlight) Drain(ctx context.Context) error {
+	f.mu.Lock()
+	f.draining = true
+	idle := f.idle
+	f.mu.Unlock()
+
+	select {
+	case <-idle:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Resume accepts new requests again.
+func (f *InFlight) Resume() {
+	f.mu.Lock()
+	f.draining = false
+	f.mu.Unlock()
+}
+
+// Draining checks if new requests are rejected.
+func (f *InFlight) Draining() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.draining
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInFlightDrain(t *testing.T) {
+	f := NewInFlight()
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	h := f.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	}))
+
+	do := func() *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/bucket/object", nil)
+		r = r.WithContext(SetReqInfo(r.Context(), &ReqInfo{API: "GetObject", BucketName: "bucket", URL: r.URL}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	done := make(chan struct{})
+	go func() {
+		require.Equal(t, http.StatusOK, do().Code)
+		close(done)
+	}()
+	<-started
+
+	list := f.List()
+	require.Len(t, list, 1)
+	require.Equal(t, "GetObject", list[0].API)
+	require.Equal(t, "bucket", list[0].Bucket)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.ErrorIs(t, f.Drain(ctx), context.DeadlineExceeded)
+	require.True(t, f.Draining())
+
+	w := do()
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+	require.Equal(t, "close", w.Header().Get("Connection"))
+
+	close(release)
+	<-done
+	require.NoError(t, f.Drain(context.Background()))
+	require.Zero(t, f.Count())
+
+	f.Resume()
+	require.False(t, f.Draining())
+}
@@ -10,6 +10,10 @@ import (
 
 const bktVersionSettingsObject = ".s3-versioning-settings"
 
+// NullVersionID is S3 version ID of the objects put when bucket versioning
+// wasn't enabled.
+const NullVersionID = "null"
+
 type (
 	// BucketInfo stores basic bucket data.
 	BucketInfo struct {
@@ -22,9 +26,13 @@ type (
 
 	// ObjectInfo holds S3 object data.
 	ObjectInfo struct {
-		ID    *object.ID
-		CID   *cid.ID
-		IsDir bool
+		ID             *object.ID
+		CID            *cid.ID
+		IsDir          bool
+		IsDeleteMarker bool
+		// NullVersion is set for objects put when bucket versioning wasn't
+		// enabled, including the ones put before versioning was supported.
+		NullVersion bool
 
 		Bucket        string
 		Name          string
@@ -46,8 +54,14 @@ func (b *BucketInfo) SystemObjectKey(obj string) string {
 	return b.Name + obj
 }
 
-// Version returns object version from ObjectInfo.
-func (o *ObjectInfo) Version() string { return o.ID.String() }
+// Version returns S3 version ID of the object, it's "null" for the null
+// version and the object ID otherwise.
+func (o *ObjectInfo) Version() string {
+	if o.NullVersion {
+		return NullVersionID
+	}
+	return o.ID.String()
+}
 
 // NiceName returns object name for cache.
 func (o *ObjectInfo) NiceName() string { return o.Bucket + "/" + o.Name }
@@ -62,4 +76,4 @@ func (o *ObjectInfo) Address() *object.Address {
 }
 
 // TagsObject returns name of system object for tags.
-func (o *ObjectInfo) TagsObject() string { return ".tagset." + o.Name + "." + o.Version() }
+func (o *ObjectInfo) TagsObject() string { return ".tagset." + o.Name + "." + o.ID.String() }
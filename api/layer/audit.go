@@ -0,0 +1,32 @@
+package layer
+
+import (
+	"context"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/audit"
+)
+
+// auditRecord adds the event of the successful mutation to the audit trail
+// filling the request details from the context.
+func (n *layer) auditRecord(ctx context.Context, ev audit.Event) {
+	if n.audit == nil {
+		return
+	}
+
+	reqInfo := api.GetReqInfo(ctx)
+	reqInfo.RLock()
+	ev.Operation = reqInfo.API
+	ev.RequestID = reqInfo.RequestID
+	ev.AccessKeyID = reqInfo.AccessKeyID
+	if ev.Bucket == "" {
+		ev.Bucket = reqInfo.BucketName
+	}
+	reqInfo.RUnlock()
+
+	if own := n.Owner(ctx); own != nil {
+		ev.Owner = own.String()
+	}
+
+	n.audit.Record(ev)
+}
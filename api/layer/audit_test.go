@@ -0,0 +1,61 @@
+package layer
+
+import (
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/audit"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type auditMock struct {
+	events []audit.Event
+}
+
+func (a *auditMock) Record(ev audit.Event) {
+	a.events = append(a.events, ev)
+}
+
+func (a *auditMock) actions() []string {
+	res := make([]string, 0, len(a.events))
+	for _, ev := range a.events {
+		res = append(res, ev.Action)
+	}
+	return res
+}
+
+func TestAuditReplacedObjects(t *testing.T) {
+	tc := prepareContext(t)
+	trail := &auditMock{}
+	tc.layer = NewLayer(zap.NewNop(), tc.testPool, &Config{
+		Caches: &CacheConfig{
+			Size:                cache.DefaultObjectsCacheSize,
+			Lifetime:            cache.DefaultObjectsCacheLifetime,
+			ListObjectsLifetime: cache.DefaultObjectsListCacheLifetime,
+			ListObjectsSize:     cache.DefaultObjectsListCacheSize,
+		},
+		SessionFallback: SessionFallbackGateway,
+		Audit:           trail,
+	})
+
+	t.Run("object", func(t *testing.T) {
+		trail.events = nil
+		obj1 := tc.putObject([]byte("content 1"))
+		tc.putObject([]byte("content 2"))
+
+		require.Equal(t, []string{audit.ActionObjectPut, audit.ActionObjectPut, audit.ActionObjectDelete}, trail.actions())
+		require.Equal(t, obj1.ID.String(), trail.events[2].ObjectID)
+		require.Equal(t, tc.obj, trail.events[2].Object)
+	})
+
+	t.Run("system object", func(t *testing.T) {
+		trail.events = nil
+		require.NoError(t, tc.layer.PutBucketTagging(tc.ctx, tc.bkt, map[string]string{"key": "value1"}))
+		first := trail.events[0].ObjectID
+		require.NoError(t, tc.layer.PutBucketTagging(tc.ctx, tc.bkt, map[string]string{"key": "value2"}))
+
+		require.Equal(t, []string{audit.ActionSystemPut, audit.ActionSystemPut, audit.ActionSystemDelete}, trail.actions())
+		require.Equal(t, first, trail.events[2].ObjectID)
+	})
+}
@@ -9,10 +9,14 @@ import (
 	"time"
 
 	"github.com/nspcc-dev/neofs-api-go/pkg/acl/eacl"
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
 	"github.com/nspcc-dev/neofs-api-go/pkg/container"
 	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/nspcc-dev/neofs-api-go/pkg/session"
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/audit"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/neofs"
 	"github.com/nspcc-dev/neofs-sdk-go/pool"
 	"go.uber.org/zap"
 )
@@ -73,7 +77,7 @@ func (n *layer) containerInfo(ctx context.Context, cid *cid.ID) (*api.BucketInfo
 		}
 	}
 
-	if err := n.bucketCache.Put(info); err != nil {
+	if err := n.cache().bucketCache.Put(info); err != nil {
 		n.log.Warn("could not put bucket info into cache",
 			zap.Stringer("cid", cid),
 			zap.String("bucket_name", info.Name),
@@ -116,10 +120,24 @@ func (n *layer) containerList(ctx context.Context) ([]*api.BucketInfo, error) {
 }
 
 func (n *layer) createContainer(ctx context.Context, p *CreateBucketParams) (*cid.ID, error) {
-	var err error
+	// both tokens are checked before the container is created, so it isn't
+	// left without eACL
+	tkn, err := n.containerSession(ctx, opCreateContainer, nil)
+	if err != nil {
+		return nil, err
+	}
+	eaclTkn, err := n.containerSession(ctx, opSetContainerEACL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if !n.containerOwner(tkn).Equal(n.containerOwner(eaclTkn)) {
+		return nil, accessDenied("session tokens to %s and %s are issued by different owners",
+			opCreateContainer.name, opSetContainerEACL.name)
+	}
+
 	bktInfo := &api.BucketInfo{
 		Name:     p.Name,
-		Owner:    n.Owner(ctx),
+		Owner:    n.containerOwner(tkn),
 		Created:  time.Now(),
 		BasicACL: p.ACL,
 	}
@@ -129,7 +147,7 @@ func (n *layer) createContainer(ctx context.Context, p *CreateBucketParams) (*ci
 		container.WithAttribute(container.AttributeName, p.Name),
 		container.WithAttribute(container.AttributeTimestamp, strconv.FormatInt(bktInfo.Created.Unix(), 10)))
 
-	cnr.SetSessionToken(p.BoxData.Gate.SessionToken)
+	cnr.SetSessionToken(tkn)
 	cnr.SetOwnerID(bktInfo.Owner)
 
 	if bktInfo.CID, err = n.pool.PutContainer(ctx, cnr); err != nil {
@@ -140,11 +158,17 @@ func (n *layer) createContainer(ctx context.Context, p *CreateBucketParams) (*ci
 		return nil, err
 	}
 
-	if err = n.setContainerEACLTable(ctx, bktInfo.CID, p.EACL); err != nil {
+	n.auditRecord(ctx, audit.Event{
+		Action:    audit.ActionContainerPut,
+		Bucket:    bktInfo.Name,
+		Container: bktInfo.CID.String(),
+	})
+
+	if err = n.setContainerEACLTable(ctx, bktInfo.CID, p.EACL, eaclTkn); err != nil {
 		return nil, err
 	}
 
-	if err = n.bucketCache.Put(bktInfo); err != nil {
+	if err = n.cache().bucketCache.Put(bktInfo); err != nil {
 		n.log.Warn("couldn't put bucket info into cache",
 			zap.String("bucket name", bktInfo.Name),
 			zap.Stringer("bucket cid", bktInfo.CID),
@@ -154,9 +178,9 @@ func (n *layer) createContainer(ctx context.Context, p *CreateBucketParams) (*ci
 	return bktInfo.CID, nil
 }
 
-func (n *layer) setContainerEACLTable(ctx context.Context, cid *cid.ID, table *eacl.Table) error {
+func (n *layer) setContainerEACLTable(ctx context.Context, cid *cid.ID, table *eacl.Table, tkn *session.Token) error {
 	table.SetCID(cid)
-	if err := n.pool.SetEACL(ctx, table, n.SessionOpt(ctx)); err != nil {
+	if err := n.pool.SetEACL(ctx, table, client.WithSession(tkn)); err != nil {
 		return err
 	}
 
@@ -164,15 +188,16 @@ func (n *layer) setContainerEACLTable(ctx context.Context, cid *cid.ID, table *e
 		return err
 	}
 
+	n.auditRecord(ctx, audit.Event{
+		Action:    audit.ActionContainerEACL,
+		Container: cid.String(),
+	})
+
 	return nil
 }
 
 func (n *layer) GetContainerEACL(ctx context.Context, cid *cid.ID) (*eacl.Table, error) {
-	signedEacl, err := n.pool.GetEACL(ctx, cid)
-	if err != nil {
-		return nil, err
-	}
-	return signedEacl.EACL(), nil
+	return neofs.GetEACL(ctx, n.pool, cid)
 }
 
 type waitParams struct {
@@ -206,9 +231,9 @@ func (n *layer) waitEACLPresence(ctx context.Context, cid *cid.ID, table *eacl.T
 		case <-wdone:
 			return wctx.Err()
 		case <-ticker.C:
-			signedEacl, err := n.pool.GetEACL(ctx, cid)
+			table, err := neofs.GetEACL(ctx, n.pool, cid)
 			if err == nil {
-				got, err := signedEacl.EACL().Marshal()
+				got, err := table.Marshal()
 				if err == nil && bytes.Equal(exp, got) {
 					return nil
 				}
@@ -219,5 +244,19 @@ func (n *layer) waitEACLPresence(ctx context.Context, cid *cid.ID, table *eacl.T
 }
 
 func (n *layer) deleteContainer(ctx context.Context, cid *cid.ID) error {
-	return n.pool.DeleteContainer(ctx, cid, n.SessionOpt(ctx))
+	tkn, err := n.containerSession(ctx, opDeleteContainer, cid)
+	if err != nil {
+		return err
+	}
+
+	if err = n.pool.DeleteContainer(ctx, cid, client.WithSession(tkn)); err != nil {
+		return err
+	}
+
+	n.auditRecord(ctx, audit.Event{
+		Action:    audit.ActionContainerDelete,
+		Container: cid.String(),
+	})
+
+	return nil
 }
@@ -0,0 +1,150 @@
+package layer
+
+import (
+	"context"
+	"fmt"
+
+	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/nspcc-dev/neofs-api-go/pkg/owner"
+	"github.com/nspcc-dev/neofs-api-go/pkg/session"
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/neofs"
+)
+
+// SessionFallback defines how container operations are performed if
+// the request has no session token.
+type SessionFallback string
+
+const (
+	// SessionFallbackGateway makes the gateway perform container operations
+	// on its own behalf, new containers are owned by the gateway.
+	SessionFallbackGateway SessionFallback = "gateway"
+	// SessionFallbackDeny rejects container operations without session token.
+	SessionFallbackDeny SessionFallback = "deny"
+)
+
+// ValidSessionFallback checks if the fallback policy is known.
+func ValidSessionFallback(fallback SessionFallback) bool {
+	return fallback == SessionFallbackGateway || fallback == SessionFallbackDeny
+}
+
+// containerOperation is a container operation performed with a session token.
+type containerOperation struct {
+	name    string
+	verb    string
+	allowed func(*session.ContainerContext) bool
+}
+
+var (
+	opCreateContainer = containerOperation{
+		name:    "create bucket",
+		verb:    "PUT",
+		allowed: (*session.ContainerContext).IsForPut,
+	}
+	opDeleteContainer = containerOperation{
+		name:    "delete bucket",
+		verb:    "DELETE",
+		allowed: (*session.ContainerContext).IsForDelete,
+	}
+	opSetContainerEACL = containerOperation{
+		name:    "set bucket ACL",
+		verb:    "SETEACL",
+		allowed: (*session.ContainerContext).IsForSetEACL,
+	}
+)
+
+// sessionToken returns session token of the access box from the context
+// for the operation. If there is no token for it, the first one is returned
+// to report why it can't be used. Nil is returned if there are no tokens.
+func sessionToken(ctx context.Context, op containerOperation) *session.Token {
+	data, ok := ctx.Value(api.BoxData).(*accessbox.Box)
+	if !ok || data == nil || data.Gate == nil || len(data.Gate.SessionTokens) == 0 {
+		return nil
+	}
+
+	for _, tkn := range data.Gate.SessionTokens {
+		if sessionCtx, ok := tkn.Context().(*session.ContainerContext); ok && sessionCtx != nil && op.allowed(sessionCtx) {
+			return tkn
+		}
+	}
+	return data.Gate.SessionTokens[0]
+}
+
+// containerSession returns session token allowing the operation on the
+// container (id is nil for a new container). Nil token is returned if the
+// request has no session token and the gateway is allowed to act on its own.
+func (n *layer) containerSession(ctx context.Context, op containerOperation, id *cid.ID) (*session.Token, error) {
+	tkn := sessionToken(ctx, op)
+	if tkn == nil {
+		if n.sessionFallback == SessionFallbackDeny {
+			return nil, accessDenied("session token is required to %s", op.name)
+		}
+		return nil, nil
+	}
+
+	epoch, err := n.currentEpoch(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get current epoch: %w", err)
+	}
+
+	if err = checkContainerSession(tkn, op, id, epoch); err != nil {
+		return nil, err
+	}
+
+	return tkn, nil
+}
+
+// containerOwner returns owner of the new container, it's the owner of the
+// session token or the gateway itself.
+func (n *layer) containerOwner(tkn *session.Token) *owner.ID {
+	if tkn != nil && tkn.OwnerID() != nil {
+		return tkn.OwnerID()
+	}
+	return n.pool.OwnerID()
+}
+
+// checkContainerSession checks that the session token allows the operation
+// on the container in the epoch.
+func checkContainerSession(tkn *session.Token, op containerOperation, id *cid.ID, epoch uint64) error {
+	sessionCtx, ok := tkn.Context().(*session.ContainerContext)
+	if !ok || sessionCtx == nil {
+		return accessDenied("session token has no container context, it can't be used to %s", op.name)
+	}
+
+	if !op.allowed(sessionCtx) {
+		return accessDenied("session token doesn't allow to %s, verb %s is required", op.name, op.verb)
+	}
+
+	if bound := sessionCtx.Container(); bound != nil {
+		if id == nil {
+			return accessDenied("session token is bound to container %s, it can't be used to %s", bound, op.name)
+		}
+		if !bound.Equal(id) {
+			return accessDenied("session token is bound to container %s, not %s", bound, id)
+		}
+	}
+
+	switch {
+	case epoch < tkn.Nbf():
+		return accessDenied("session token is not valid before epoch %d, current epoch is %d", tkn.Nbf(), epoch)
+	case epoch > tkn.Exp():
+		return accessDenied("session token expired at epoch %d, current epoch is %d", tkn.Exp(), epoch)
+	}
+
+	return nil
+}
+
+func (n *layer) currentEpoch(ctx context.Context) (uint64, error) {
+	info, err := neofs.NetworkInfo(ctx, n.pool)
+	if err != nil {
+		return 0, err
+	}
+
+	return info.CurrentEpoch(), nil
+}
+
+func accessDenied(format string, args ...interface{}) error {
+	return errors.GetAPIErrorWithError(errors.ErrAccessDenied, fmt.Errorf(format, args...))
+}
@@ -0,0 +1,164 @@
+package layer
+
+import (
+	"context"
+	"crypto/sha256"
+	"math"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-api-go/pkg/acl/eacl"
+	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/nspcc-dev/neofs-api-go/pkg/session"
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCID(seed byte) *cid.ID {
+	id := cid.New()
+	id.SetSHA256(sha256.Sum256([]byte{seed}))
+	return id
+}
+
+func newContainerSessionToken(bound *cid.ID, verb func(*session.ContainerContext)) *session.Token {
+	sessionCtx := session.NewContainerContext()
+	verb(sessionCtx)
+	sessionCtx.ApplyTo(bound)
+
+	tkn := session.NewToken()
+	tkn.SetContext(sessionCtx)
+	tkn.SetNbf(10)
+	tkn.SetExp(20)
+	return tkn
+}
+
+func TestCheckContainerSession(t *testing.T) {
+	id, other := newTestCID(1), newTestCID(2)
+
+	for _, tc := range []struct {
+		name   string
+		tkn    *session.Token
+		op     containerOperation
+		id     *cid.ID
+		epoch  uint64
+		reason string
+	}{
+		{
+			name:  "create",
+			tkn:   newContainerSessionToken(nil, (*session.ContainerContext).ForPut),
+			op:    opCreateContainer,
+			epoch: 15,
+		},
+		{
+			name:  "delete bound container",
+			tkn:   newContainerSessionToken(id, (*session.ContainerContext).ForDelete),
+			op:    opDeleteContainer,
+			id:    id,
+			epoch: 20,
+		},
+		{
+			name:   "no container context",
+			tkn:    session.NewToken(),
+			op:     opCreateContainer,
+			epoch:  15,
+			reason: "session token has no container context",
+		},
+		{
+			name:   "wrong verb",
+			tkn:    newContainerSessionToken(nil, (*session.ContainerContext).ForPut),
+			op:     opSetContainerEACL,
+			id:     id,
+			epoch:  15,
+			reason: "verb SETEACL is required",
+		},
+		{
+			name:   "other container",
+			tkn:    newContainerSessionToken(other, (*session.ContainerContext).ForDelete),
+			op:     opDeleteContainer,
+			id:     id,
+			epoch:  15,
+			reason: "session token is bound to container " + other.String(),
+		},
+		{
+			name:   "create with bound token",
+			tkn:    newContainerSessionToken(id, (*session.ContainerContext).ForPut),
+			op:     opCreateContainer,
+			epoch:  15,
+			reason: "it can't be used to create bucket",
+		},
+		{
+			name:   "not valid yet",
+			tkn:    newContainerSessionToken(nil, (*session.ContainerContext).ForPut),
+			op:     opCreateContainer,
+			epoch:  9,
+			reason: "not valid before epoch 10",
+		},
+		{
+			name:   "expired",
+			tkn:    newContainerSessionToken(nil, (*session.ContainerContext).ForPut),
+			op:     opCreateContainer,
+			epoch:  21,
+			reason: "expired at epoch 20",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkContainerSession(tc.tkn, tc.op, tc.id, tc.epoch)
+			if tc.reason == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.True(t, errors.IsS3Error(err, errors.ErrAccessDenied), err)
+			require.Contains(t, err.Error(), tc.reason)
+		})
+	}
+}
+
+func TestContainerSessionFallback(t *testing.T) {
+	tc := prepareContext(t)
+
+	tkn, err := tc.layer.(*layer).containerSession(tc.ctx, opDeleteContainer, tc.bktID)
+	require.NoError(t, err)
+	require.Nil(t, tkn)
+
+	tc.layer.(*layer).sessionFallback = SessionFallbackDeny
+	err = tc.layer.DeleteBucket(tc.ctx, &DeleteBucketParams{Name: tc.bkt})
+	require.True(t, errors.IsS3Error(err, errors.ErrAccessDenied), err)
+	require.Contains(t, err.Error(), "session token is required to delete bucket")
+
+	_, err = tc.layer.CreateBucket(context.Background(), &CreateBucketParams{Name: "testbucket2"})
+	require.True(t, errors.IsS3Error(err, errors.ErrAccessDenied), err)
+}
+
+func TestCreateContainerSessions(t *testing.T) {
+	tc := prepareContext(t)
+
+	newToken := func(verb func(*session.ContainerContext)) *session.Token {
+		tkn := newContainerSessionToken(nil, verb)
+		tkn.SetNbf(0)
+		tkn.SetExp(math.MaxUint64)
+		return tkn
+	}
+	box := tc.ctx.Value(api.BoxData).(*accessbox.Box)
+	box.Gate.SessionTokens = []*session.Token{newToken((*session.ContainerContext).ForPut)}
+
+	create := func(name string) error {
+		_, err := tc.layer.CreateBucket(tc.ctx, &CreateBucketParams{Name: name, EACL: eacl.NewTable()})
+		return err
+	}
+	containers := func() int {
+		ids, err := tc.testPool.ListContainers(tc.ctx, tc.testPool.OwnerID())
+		require.NoError(t, err)
+		return len(ids)
+	}
+
+	// nothing is created if the eACL can't be set
+	err := create("testbucket2")
+	require.True(t, errors.IsS3Error(err, errors.ErrAccessDenied), err)
+	require.Contains(t, err.Error(), "verb SETEACL is required")
+	require.Equal(t, 1, containers())
+
+	box.Gate.SessionTokens = append(box.Gate.SessionTokens, newToken((*session.ContainerContext).ForSetEACL))
+	require.NoError(t, create("testbucket2"))
+	require.Equal(t, 2, containers())
+}
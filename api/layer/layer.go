@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/nspcc-dev/neofs-api-go/pkg/acl/eacl"
@@ -20,15 +21,40 @@ import (
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
 	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/audit"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/tracing"
 	"github.com/nspcc-dev/neofs-sdk-go/pool"
 	"go.uber.org/zap"
 )
 
 type (
 	layer struct {
-		pool        pool.Pool
-		log         *zap.Logger
+		pool   pool.Pool
+		log    *zap.Logger
+		mu     sync.RWMutex
+		caches *layerCaches
+		usage  *usageCounters
+
+		sessionFallback SessionFallback
+		audit           AuditTrail
+	}
+
+	// Config contains settings of the layer.
+	Config struct {
+		Caches          *CacheConfig
+		SessionFallback SessionFallback
+		// Audit receives events of the storage mutations, it's optional.
+		Audit AuditTrail
+	}
+
+	// AuditTrail records the storage mutations.
+	AuditTrail interface {
+		Record(ev audit.Event)
+	}
+
+	layerCaches struct {
 		listsCache  cache.ObjectsListCache
 		objCache    cache.ObjectsCache
 		namesCache  cache.ObjectsNameCache
@@ -77,11 +103,21 @@ type (
 
 	// PutObjectParams stores object put request parameters.
 	PutObjectParams struct {
-		Bucket string
-		Object string
-		Size   int64
-		Reader io.Reader
-		Header map[string]string
+		Bucket     string
+		Object     string
+		Size       int64
+		Reader     io.Reader
+		Header     map[string]string
+		Conditions *PutConditions
+	}
+
+	// PutConditions stores conditions which the current version of the object
+	// must satisfy to be replaced (If-Match and If-None-Match headers).
+	// There are no locks in NeoFS, so conditions are checked right before
+	// the put and concurrent puts with the same conditions can all succeed.
+	PutConditions struct {
+		IfMatch     string
+		IfNoneMatch string
 	}
 
 	// PutVersioningParams stores object copy request parameters.
@@ -92,16 +128,18 @@ type (
 
 	// BucketSettings stores settings such as versioning.
 	BucketSettings struct {
-		VersioningEnabled bool
+		VersioningStatus string
+		Quota            BucketQuota
 	}
 
 	// CopyObjectParams stores object copy request parameters.
 	CopyObjectParams struct {
-		SrcObject *api.ObjectInfo
-		DstBucket string
-		DstObject string
-		SrcSize   int64
-		Header    map[string]string
+		SrcObject  *api.ObjectInfo
+		DstBucket  string
+		DstObject  string
+		SrcSize    int64
+		Header     map[string]string
+		Conditions *PutConditions
 	}
 	// CreateBucketParams stores bucket create request parameters.
 	CreateBucketParams struct {
@@ -135,6 +173,9 @@ type (
 	VersionedObject struct {
 		Name      string
 		VersionID string
+		// DeleteMarkVersion is set on deletion if delete marker
+		// was created or the removed version was a delete marker.
+		DeleteMarkVersion string
 	}
 
 	// PutTaggingParams stores tag set params.
@@ -148,13 +189,24 @@ type (
 		Get(ctx context.Context, address *object.Address) (*object.Object, error)
 	}
 
+	// Caches provides access to the state of layer caches.
+	Caches interface {
+		CacheStats() map[string]cache.Stats
+		UpdateCaches(config *CacheConfig)
+	}
+
 	// Client provides S3 API client interface.
 	Client interface {
 		NeoFS
+		Caches
 
 		PutBucketVersioning(ctx context.Context, p *PutVersioningParams) (*api.ObjectInfo, error)
 		GetBucketVersioning(ctx context.Context, name string) (*BucketSettings, error)
 
+		PutBucketQuota(ctx context.Context, p *PutBucketQuotaParams) error
+		GetBucketQuota(ctx context.Context, name string) (*BucketQuotaInfo, error)
+		ReconcileUsage(ctx context.Context)
+
 		ListBuckets(ctx context.Context) ([]*api.BucketInfo, error)
 		GetBucketInfo(ctx context.Context, name string) (*api.BucketInfo, error)
 		GetBucketACL(ctx context.Context, name string) (*BucketACL, error)
@@ -165,6 +217,7 @@ type (
 		GetObject(ctx context.Context, p *GetObjectParams) error
 		GetObjectInfo(ctx context.Context, p *HeadObjectParams) (*api.ObjectInfo, error)
 		GetObjectTagging(ctx context.Context, p *api.ObjectInfo) (map[string]string, error)
+		GetObjectsTagging(ctx context.Context, objects []*api.ObjectInfo) ([]map[string]string, error)
 		GetBucketTagging(ctx context.Context, bucket string) (map[string]string, error)
 
 		PutObject(ctx context.Context, p *PutObjectParams) (*api.ObjectInfo, error)
@@ -176,6 +229,7 @@ type (
 		ListObjectsV1(ctx context.Context, p *ListObjectsParamsV1) (*ListObjectsInfoV1, error)
 		ListObjectsV2(ctx context.Context, p *ListObjectsParamsV2) (*ListObjectsInfoV2, error)
 		ListObjectVersions(ctx context.Context, p *ListObjectVersionsParams) (*ListObjectVersionsInfo, error)
+		SearchObjects(ctx context.Context, p *SearchObjectsParams) (*ListObjectsInfoV2, error)
 
 		DeleteObjects(ctx context.Context, bucket string, objects []*VersionedObject) []error
 		DeleteObjectTagging(ctx context.Context, p *api.ObjectInfo) error
@@ -186,18 +240,48 @@ type (
 const (
 	tagPrefix    = "S3-Tag-"
 	tagEmptyMark = "\\"
+
+	// tagsLookupWorkers limits the number of tag sets looked up at once.
+	tagsLookupWorkers = 16
+)
+
+// Bucket versioning statuses.
+const (
+	VersioningUnversioned = "Unversioned"
+	VersioningEnabled     = "Enabled"
+	VersioningSuspended   = "Suspended"
 )
 
+// VersioningEnabled checks if versioning is enabled for the bucket.
+func (s *BucketSettings) VersioningEnabled() bool {
+	return s.VersioningStatus == VersioningEnabled
+}
+
+// VersioningSuspended checks if versioning was enabled for the bucket and then suspended.
+func (s *BucketSettings) VersioningSuspended() bool {
+	return s.VersioningStatus == VersioningSuspended
+}
+
 func (t *VersionedObject) String() string {
 	return t.Name + ":" + t.VersionID
 }
 
 // NewLayer creates instance of layer. It checks credentials
 // and establishes gRPC connection with node.
-func NewLayer(log *zap.Logger, conns pool.Pool, config *CacheConfig) Client {
+func NewLayer(log *zap.Logger, conns pool.Pool, config *Config) Client {
 	return &layer{
-		pool:       conns,
-		log:        log,
+		pool:   conns,
+		log:    log,
+		caches: newLayerCaches(config.Caches),
+		usage:  newUsageCounters(),
+
+		sessionFallback: config.SessionFallback,
+		audit:           config.Audit,
+	}
+}
+
+func newLayerCaches(config *CacheConfig) *layerCaches {
+	return &layerCaches{
 		listsCache: cache.NewObjectsListCache(config.ListObjectsSize, config.ListObjectsLifetime),
 		objCache:   cache.New(config.Size, config.Lifetime),
 		//todo reconsider cache params
@@ -207,6 +291,35 @@ func NewLayer(log *zap.Logger, conns pool.Pool, config *CacheConfig) Client {
 	}
 }
 
+func (n *layer) cache() *layerCaches {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.caches
+}
+
+// UpdateCaches replaces all layer caches with the empty ones created
+// using the provided config.
+func (n *layer) UpdateCaches(config *CacheConfig) {
+	caches := newLayerCaches(config)
+
+	n.mu.Lock()
+	n.caches = caches
+	n.mu.Unlock()
+}
+
+// CacheStats returns usage statistics of all layer caches.
+func (n *layer) CacheStats() map[string]cache.Stats {
+	caches := n.cache()
+	return map[string]cache.Stats{
+		"objects":      caches.objCache.Stats(),
+		"list_objects": caches.listsCache.Stats(),
+		"names":        caches.namesCache.Stats(),
+		"buckets":      caches.bucketCache.Stats(),
+		"system":       caches.systemCache.Stats(),
+	}
+}
+
 // Owner returns owner id from BearerToken (context) or from client owner.
 func (n *layer) Owner(ctx context.Context) *owner.ID {
 	if data, ok := ctx.Value(api.BoxData).(*accessbox.Box); ok && data != nil && data.Gate != nil {
@@ -228,7 +341,7 @@ func (n *layer) BearerOpt(ctx context.Context) client.CallOption {
 // SessionOpt returns client.WithSession call option with token from context or with nil token.
 func (n *layer) SessionOpt(ctx context.Context) client.CallOption {
 	if data, ok := ctx.Value(api.BoxData).(*accessbox.Box); ok && data != nil && data.Gate != nil {
-		return client.WithSession(data.Gate.SessionToken)
+		return client.WithSession(data.Gate.SessionTokenForPut())
 	}
 
 	return client.WithSession(nil)
@@ -242,12 +355,28 @@ func (n *layer) Get(ctx context.Context, address *object.Address) (*object.Objec
 
 // GetBucketInfo returns bucket info by name.
 func (n *layer) GetBucketInfo(ctx context.Context, name string) (*api.BucketInfo, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.GetBucketInfo", tracing.AttrBucket.String(name))
+	defer span.End()
+
+	bktInfo, err := n.getBucketInfo(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// buckets of anonymous requests aren't counted by per-bucket metrics
+	if box, ok := ctx.Value(api.BoxData).(*accessbox.Box); ok && box != nil {
+		metrics.BucketResolved(ctx, name)
+	}
+	return bktInfo, nil
+}
+
+func (n *layer) getBucketInfo(ctx context.Context, name string) (*api.BucketInfo, error) {
 	name, err := url.QueryUnescape(name)
 	if err != nil {
 		return nil, err
 	}
 
-	if bktInfo := n.bucketCache.Get(name); bktInfo != nil {
+	if bktInfo := n.cache().bucketCache.Get(name); bktInfo != nil {
 		return bktInfo, nil
 	}
 
@@ -271,6 +400,9 @@ func (n *layer) GetBucketInfo(ctx context.Context, name string) (*api.BucketInfo
 
 // GetBucketACL returns bucket acl info by name.
 func (n *layer) GetBucketACL(ctx context.Context, name string) (*BucketACL, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.GetBucketACL", tracing.AttrBucket.String(name))
+	defer span.End()
+
 	inf, err := n.GetBucketInfo(ctx, name)
 	if err != nil {
 		return nil, err
@@ -289,22 +421,36 @@ func (n *layer) GetBucketACL(ctx context.Context, name string) (*BucketACL, erro
 
 // PutBucketACL put bucket acl by name.
 func (n *layer) PutBucketACL(ctx context.Context, param *PutBucketACLParams) error {
+	ctx, span := tracing.StartSpan(ctx, "layer.PutBucketACL", tracing.AttrBucket.String(param.Name))
+	defer span.End()
+
 	inf, err := n.GetBucketInfo(ctx, param.Name)
 	if err != nil {
 		return err
 	}
 
-	return n.setContainerEACLTable(ctx, inf.CID, param.EACL)
+	tkn, err := n.containerSession(ctx, opSetContainerEACL, inf.CID)
+	if err != nil {
+		return err
+	}
+
+	return n.setContainerEACLTable(ctx, inf.CID, param.EACL, tkn)
 }
 
 // ListBuckets returns all user containers. Name of the bucket is a container
 // id. Timestamp is omitted since it is not saved in neofs container.
 func (n *layer) ListBuckets(ctx context.Context) ([]*api.BucketInfo, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.ListBuckets")
+	defer span.End()
+
 	return n.containerList(ctx)
 }
 
 // GetObject from storage.
 func (n *layer) GetObject(ctx context.Context, p *GetObjectParams) error {
+	ctx, span := tracing.StartSpan(ctx, "layer.GetObject", tracing.AttrBucket.String(p.ObjectInfo.Bucket), tracing.AttrObject.String(p.ObjectInfo.Name))
+	defer span.End()
+
 	var err error
 
 	params := &getParams{
@@ -327,7 +473,7 @@ func (n *layer) GetObject(ctx context.Context, p *GetObjectParams) error {
 	}
 
 	if err != nil {
-		n.objCache.Delete(p.ObjectInfo.Address())
+		n.cache().objCache.Delete(p.ObjectInfo.Address())
 		return fmt.Errorf("couldn't get object, cid: %s : %w", p.ObjectInfo.CID, err)
 	}
 
@@ -336,6 +482,9 @@ func (n *layer) GetObject(ctx context.Context, p *GetObjectParams) error {
 
 // GetObjectInfo returns meta information about the object.
 func (n *layer) GetObjectInfo(ctx context.Context, p *HeadObjectParams) (*api.ObjectInfo, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.GetObjectInfo", tracing.AttrBucket.String(p.Bucket), tracing.AttrObject.String(p.Object))
+	defer span.End()
+
 	bkt, err := n.GetBucketInfo(ctx, p.Bucket)
 	if err != nil {
 		n.log.Error("could not fetch bucket info", zap.Error(err))
@@ -346,11 +495,21 @@ func (n *layer) GetObjectInfo(ctx context.Context, p *HeadObjectParams) (*api.Ob
 		return n.headLastVersionIfNotDeleted(ctx, bkt, p.Object)
 	}
 
+	if p.VersionID == unversionedObjectVersionID {
+		if n.getVersioningStatus(ctx, bkt) == VersioningUnversioned {
+			return n.headLastVersionIfNotDeleted(ctx, bkt, p.Object)
+		}
+		return n.headNullVersion(ctx, bkt, p.Object)
+	}
+
 	return n.headVersion(ctx, bkt, p.VersionID)
 }
 
 // PutObject into storage.
 func (n *layer) PutObject(ctx context.Context, p *PutObjectParams) (*api.ObjectInfo, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.PutObject", tracing.AttrBucket.String(p.Bucket), tracing.AttrObject.String(p.Object))
+	defer span.End()
+
 	bkt, err := n.GetBucketInfo(ctx, p.Bucket)
 	if err != nil {
 		return nil, err
@@ -361,6 +520,9 @@ func (n *layer) PutObject(ctx context.Context, p *PutObjectParams) (*api.ObjectI
 
 // GetObjectTagging from storage.
 func (n *layer) GetObjectTagging(ctx context.Context, oi *api.ObjectInfo) (map[string]string, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.GetObjectTagging", tracing.AttrBucket.String(oi.Bucket), tracing.AttrObject.String(oi.Name))
+	defer span.End()
+
 	bktInfo := &api.BucketInfo{
 		Name:  oi.Bucket,
 		CID:   oi.CID,
@@ -375,8 +537,63 @@ func (n *layer) GetObjectTagging(ctx context.Context, oi *api.ObjectInfo) (map[s
 	return formTagSet(objInfo), nil
 }
 
+// GetObjectsTagging returns tag sets of the objects of one bucket in the same
+// order. Tag sets are looked up by exact names, at most tagsLookupWorkers of
+// them at once.
+func (n *layer) GetObjectsTagging(ctx context.Context, objects []*api.ObjectInfo) ([]map[string]string, error) {
+	res := make([]map[string]string, len(objects))
+	if len(objects) == 0 {
+		return res, nil
+	}
+
+	ctx, span := tracing.StartSpan(ctx, "layer.GetObjectsTagging", tracing.AttrBucket.String(objects[0].Bucket))
+	defer span.End()
+
+	bktInfo := &api.BucketInfo{
+		Name:  objects[0].Bucket,
+		CID:   objects[0].CID,
+		Owner: objects[0].Owner,
+	}
+
+	var (
+		wg      sync.WaitGroup
+		errs    = make([]error, len(objects))
+		workers = make(chan struct{}, tagsLookupWorkers)
+	)
+
+	for i, obj := range objects {
+		wg.Add(1)
+		workers <- struct{}{}
+		go func(i int, name string) {
+			defer func() {
+				<-workers
+				wg.Done()
+			}()
+
+			objInfo, err := n.getSystemObject(ctx, bktInfo, name)
+			if err != nil && !errors.IsS3Error(err, errors.ErrNoSuchKey) {
+				errs[i] = err
+				return
+			}
+			res[i] = formTagSet(objInfo)
+		}(i, obj.TagsObject())
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
 // GetBucketTagging from storage.
 func (n *layer) GetBucketTagging(ctx context.Context, bucketName string) (map[string]string, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.GetBucketTagging", tracing.AttrBucket.String(bucketName))
+	defer span.End()
+
 	bktInfo, err := n.GetBucketInfo(ctx, bucketName)
 	if err != nil {
 		return nil, err
@@ -408,6 +625,9 @@ func formTagSet(objInfo *api.ObjectInfo) map[string]string {
 
 // PutObjectTagging into storage.
 func (n *layer) PutObjectTagging(ctx context.Context, p *PutTaggingParams) error {
+	ctx, span := tracing.StartSpan(ctx, "layer.PutObjectTagging", tracing.AttrBucket.String(p.ObjectInfo.Bucket), tracing.AttrObject.String(p.ObjectInfo.Name))
+	defer span.End()
+
 	bktInfo := &api.BucketInfo{
 		Name:  p.ObjectInfo.Bucket,
 		CID:   p.ObjectInfo.CID,
@@ -423,6 +643,9 @@ func (n *layer) PutObjectTagging(ctx context.Context, p *PutTaggingParams) error
 
 // PutBucketTagging into storage.
 func (n *layer) PutBucketTagging(ctx context.Context, bucketName string, tagSet map[string]string) error {
+	ctx, span := tracing.StartSpan(ctx, "layer.PutBucketTagging", tracing.AttrBucket.String(bucketName))
+	defer span.End()
+
 	bktInfo, err := n.GetBucketInfo(ctx, bucketName)
 	if err != nil {
 		return err
@@ -437,6 +660,9 @@ func (n *layer) PutBucketTagging(ctx context.Context, bucketName string, tagSet
 
 // DeleteObjectTagging from storage.
 func (n *layer) DeleteObjectTagging(ctx context.Context, p *api.ObjectInfo) error {
+	ctx, span := tracing.StartSpan(ctx, "layer.DeleteObjectTagging", tracing.AttrBucket.String(p.Bucket), tracing.AttrObject.String(p.Name))
+	defer span.End()
+
 	bktInfo, err := n.GetBucketInfo(ctx, p.Bucket)
 	if err != nil {
 		return err
@@ -446,7 +672,7 @@ func (n *layer) DeleteObjectTagging(ctx context.Context, p *api.ObjectInfo) erro
 
 func (n *layer) deleteSystemObject(ctx context.Context, bktInfo *api.BucketInfo, name string) error {
 	var oid *object.ID
-	if meta := n.systemCache.Get(bktInfo.SystemObjectKey(name)); meta != nil {
+	if meta := n.cache().systemCache.Get(bktInfo.SystemObjectKey(name)); meta != nil {
 		oid = meta.ID()
 	} else {
 		var err error
@@ -459,12 +685,27 @@ func (n *layer) deleteSystemObject(ctx context.Context, bktInfo *api.BucketInfo,
 		}
 	}
 
-	n.systemCache.Delete(bktInfo.SystemObjectKey(name))
-	return n.objectDelete(ctx, bktInfo.CID, oid)
+	n.cache().systemCache.Delete(bktInfo.SystemObjectKey(name))
+	if err := n.objectDelete(ctx, bktInfo.CID, oid); err != nil {
+		return err
+	}
+
+	n.auditRecord(ctx, audit.Event{
+		Action:    audit.ActionSystemDelete,
+		Bucket:    bktInfo.Name,
+		Container: bktInfo.CID.String(),
+		Object:    name,
+		ObjectID:  oid.String(),
+	})
+
+	return nil
 }
 
 // DeleteBucketTagging from storage.
 func (n *layer) DeleteBucketTagging(ctx context.Context, bucketName string) error {
+	ctx, span := tracing.StartSpan(ctx, "layer.DeleteBucketTagging", tracing.AttrBucket.String(bucketName))
+	defer span.End()
+
 	bktInfo, err := n.GetBucketInfo(ctx, bucketName)
 	if err != nil {
 		return err
@@ -474,11 +715,14 @@ func (n *layer) DeleteBucketTagging(ctx context.Context, bucketName string) erro
 }
 
 func (n *layer) putSystemObject(ctx context.Context, bktInfo *api.BucketInfo, objName string, metadata map[string]string, prefix string) (*object.Object, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.putSystemObject", tracing.AttrBucket.String(bktInfo.Name), tracing.AttrObject.String(objName))
+	defer span.End()
+
 	var (
 		err    error
 		oldOID *object.ID
 	)
-	if meta := n.systemCache.Get(bktInfo.SystemObjectKey(objName)); meta != nil {
+	if meta := n.cache().systemCache.Get(bktInfo.SystemObjectKey(objName)); meta != nil {
 		oldOID = meta.ID()
 	} else {
 		oldOID, err = n.objectFindID(ctx, &findParams{cid: bktInfo.CID, attr: objectSystemAttributeName, val: objName})
@@ -528,20 +772,38 @@ func (n *layer) putSystemObject(ctx context.Context, bktInfo *api.BucketInfo, ob
 	if err != nil {
 		return nil, err
 	}
-	if err = n.systemCache.Put(bktInfo.SystemObjectKey(objName), meta); err != nil {
+	if err = n.cache().systemCache.Put(bktInfo.SystemObjectKey(objName), meta); err != nil {
 		n.log.Error("couldn't cache system object", zap.Error(err))
 	}
+
+	n.auditRecord(ctx, audit.Event{
+		Action:    audit.ActionSystemPut,
+		Bucket:    bktInfo.Name,
+		Container: bktInfo.CID.String(),
+		Object:    objName,
+		ObjectID:  oid.String(),
+	})
 	if oldOID != nil {
 		if err = n.objectDelete(ctx, bktInfo.CID, oldOID); err != nil {
 			return nil, err
 		}
+		n.auditRecord(ctx, audit.Event{
+			Action:    audit.ActionSystemDelete,
+			Bucket:    bktInfo.Name,
+			Container: bktInfo.CID.String(),
+			Object:    objName,
+			ObjectID:  oldOID.String(),
+		})
 	}
 
 	return meta, nil
 }
 
 func (n *layer) getSystemObject(ctx context.Context, bkt *api.BucketInfo, objName string) (*api.ObjectInfo, error) {
-	if meta := n.systemCache.Get(bkt.SystemObjectKey(objName)); meta != nil {
+	ctx, span := tracing.StartSpan(ctx, "layer.getSystemObject", tracing.AttrBucket.String(bkt.Name), tracing.AttrObject.String(objName))
+	defer span.End()
+
+	if meta := n.cache().systemCache.Get(bkt.SystemObjectKey(objName)); meta != nil {
 		return objInfoFromMeta(bkt, meta), nil
 	}
 
@@ -554,7 +816,7 @@ func (n *layer) getSystemObject(ctx context.Context, bkt *api.BucketInfo, objNam
 	if err != nil {
 		return nil, err
 	}
-	if err = n.systemCache.Put(bkt.SystemObjectKey(objName), meta); err != nil {
+	if err = n.cache().systemCache.Put(bkt.SystemObjectKey(objName), meta); err != nil {
 		n.log.Error("couldn't cache system object", zap.Error(err))
 	}
 
@@ -563,6 +825,9 @@ func (n *layer) getSystemObject(ctx context.Context, bkt *api.BucketInfo, objNam
 
 // CopyObject from one bucket into another bucket.
 func (n *layer) CopyObject(ctx context.Context, p *CopyObjectParams) (*api.ObjectInfo, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.CopyObject", tracing.AttrBucket.String(p.DstBucket), tracing.AttrObject.String(p.DstObject))
+	defer span.End()
+
 	pr, pw := io.Pipe()
 
 	go func() {
@@ -576,69 +841,116 @@ func (n *layer) CopyObject(ctx context.Context, p *CopyObjectParams) (*api.Objec
 		}
 	}()
 
-	return n.PutObject(ctx, &PutObjectParams{
-		Bucket: p.DstBucket,
-		Object: p.DstObject,
-		Size:   p.SrcSize,
-		Reader: pr,
-		Header: p.Header,
+	objInfo, err := n.PutObject(ctx, &PutObjectParams{
+		Bucket:     p.DstBucket,
+		Object:     p.DstObject,
+		Size:       p.SrcSize,
+		Reader:     pr,
+		Header:     p.Header,
+		Conditions: p.Conditions,
 	})
+	if err != nil {
+		// the payload may be left unread (e.g. failed precondition or quota),
+		// so the reader is closed to release the goroutine writing into it
+		_ = pr.CloseWithError(err)
+		return nil, err
+	}
+
+	return objInfo, nil
 }
 
 // DeleteObject removes all objects with passed nice name.
 func (n *layer) deleteObject(ctx context.Context, bkt *api.BucketInfo, obj *VersionedObject) error {
+	ctx, span := tracing.StartSpan(ctx, "layer.deleteObject", tracing.AttrBucket.String(bkt.Name), tracing.AttrObject.String(obj.Name))
+	defer span.End()
+
 	var (
 		err error
 		ids []*object.ID
+		// infos of the objects being removed to release bucket usage
+		infos = make(map[string]*api.ObjectInfo)
 	)
 
-	versioningEnabled := n.isVersioningEnabled(ctx, bkt)
-	if !versioningEnabled && obj.VersionID != unversionedObjectVersionID && obj.VersionID != "" {
+	versioning := n.getVersioningStatus(ctx, bkt)
+	if versioning == VersioningUnversioned && obj.VersionID != unversionedObjectVersionID && obj.VersionID != "" {
 		return errors.GetAPIError(errors.ErrInvalidVersion)
 	}
 
-	if versioningEnabled {
+	if versioning != VersioningUnversioned {
+		versionID := obj.VersionID
+		if versionID == unversionedObjectVersionID {
+			nullVersion, err := n.headNullVersion(ctx, bkt, obj.Name)
+			if err != nil {
+				return err
+			}
+			versionID = nullVersion.ID.String()
+		}
+
 		p := &PutObjectParams{
 			Object: obj.Name,
 			Reader: bytes.NewReader(nil),
-			Header: map[string]string{versionsDeleteMarkAttr: obj.VersionID},
+			Header: map[string]string{versionsDeleteMarkAttr: versionID},
 		}
-		if len(obj.VersionID) != 0 {
-			id, err := n.checkVersionsExist(ctx, bkt, obj)
+		if len(versionID) != 0 {
+			version, err := n.checkVersionsExist(ctx, bkt, &VersionedObject{Name: obj.Name, VersionID: versionID})
 			if err != nil {
 				return err
 			}
-			ids = []*object.ID{id}
+			ids = []*object.ID{version.ID}
+			infos[version.ID.String()] = version
+			if version.IsDeleteMarker {
+				obj.DeleteMarkVersion = obj.VersionID
+			}
 
-			p.Header[versionsDelAttr] = obj.VersionID
+			p.Header[versionsDelAttr] = versionID
 		} else {
 			p.Header[versionsDeleteMarkAttr] = delMarkFullObject
 		}
-		if _, err = n.objectPut(ctx, bkt, p); err != nil {
+		deleteMark, err := n.objectPut(ctx, bkt, p)
+		if err != nil {
 			return err
 		}
+		if len(versionID) == 0 {
+			obj.DeleteMarkVersion = deleteMark.Version()
+		}
 	} else {
 		ids, err = n.objectSearch(ctx, &findParams{cid: bkt.CID, val: obj.Name})
 		if err != nil {
 			return err
 		}
+		for _, id := range ids {
+			if oi := n.objectUsageInfo(ctx, bkt, id); oi != nil {
+				infos[id.String()] = oi
+			}
+		}
 	}
 
 	for _, id := range ids {
 		if err = n.objectDelete(ctx, bkt.CID, id); err != nil {
 			return err
 		}
+		n.auditRecord(ctx, audit.Event{
+			Action:    audit.ActionObjectDelete,
+			Bucket:    bkt.Name,
+			Container: bkt.CID.String(),
+			Object:    obj.Name,
+			ObjectID:  id.String(),
+		})
+		n.releaseUsage(bkt, infos[id.String()])
 		if err = n.DeleteObjectTagging(ctx, &api.ObjectInfo{ID: id, Bucket: bkt.Name, Name: obj.Name}); err != nil {
 			return err
 		}
 	}
-	n.listsCache.CleanCacheEntriesContainingObject(obj.Name, bkt.CID)
+	n.cache().listsCache.CleanCacheEntriesContainingObject(obj.Name, bkt.CID)
 
 	return nil
 }
 
 // DeleteObjects from the storage.
 func (n *layer) DeleteObjects(ctx context.Context, bucket string, objects []*VersionedObject) []error {
+	ctx, span := tracing.StartSpan(ctx, "layer.DeleteObjects", tracing.AttrBucket.String(bucket))
+	defer span.End()
+
 	var errs = make([]error, 0, len(objects))
 
 	bkt, err := n.GetBucketInfo(ctx, bucket)
@@ -656,6 +968,9 @@ func (n *layer) DeleteObjects(ctx context.Context, bucket string, objects []*Ver
 }
 
 func (n *layer) CreateBucket(ctx context.Context, p *CreateBucketParams) (*cid.ID, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.CreateBucket", tracing.AttrBucket.String(p.Name))
+	defer span.End()
+
 	_, err := n.GetBucketInfo(ctx, p.Name)
 	if err != nil {
 		if errors.IsS3Error(err, errors.ErrNoSuchBucket) {
@@ -668,6 +983,9 @@ func (n *layer) CreateBucket(ctx context.Context, p *CreateBucketParams) (*cid.I
 }
 
 func (n *layer) DeleteBucket(ctx context.Context, p *DeleteBucketParams) error {
+	ctx, span := tracing.StartSpan(ctx, "layer.DeleteBucket", tracing.AttrBucket.String(p.Name))
+	defer span.End()
+
 	bucketInfo, err := n.GetBucketInfo(ctx, p.Name)
 	if err != nil {
 		return err
@@ -684,6 +1002,7 @@ func (n *layer) DeleteBucket(ctx context.Context, p *DeleteBucketParams) error {
 	if err = n.deleteContainer(ctx, bucketInfo.CID); err != nil {
 		return err
 	}
-	n.bucketCache.Delete(bucketInfo.Name)
+	n.cache().bucketCache.Delete(bucketInfo.Name)
+	n.usage.forget(bucketInfo.CID)
 	return nil
 }
@@ -17,6 +17,9 @@ import (
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
 	apiErrors "github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/audit"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/tracing"
+	"go.opentelemetry.io/otel/attribute"
 	"go.uber.org/zap"
 )
 
@@ -135,18 +138,21 @@ func (n *layer) objectRange(ctx context.Context, p *getParams) ([]byte, error) {
 
 // objectPut into NeoFS, took payload from io.Reader.
 func (n *layer) objectPut(ctx context.Context, bkt *api.BucketInfo, p *PutObjectParams) (*api.ObjectInfo, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.objectPut", tracing.AttrBucket.String(bkt.Name), tracing.AttrObject.String(p.Object))
+	defer span.End()
+
 	own := n.Owner(ctx)
 	obj, err := url.QueryUnescape(p.Object)
 	if err != nil {
 		return nil, err
 	}
 
-	versioningEnabled := n.isVersioningEnabled(ctx, bkt)
+	versioning := n.getVersioningStatus(ctx, bkt)
 	versions, err := n.headVersions(ctx, bkt, obj)
 	if err != nil && !apiErrors.IsS3Error(err, apiErrors.ErrNoSuchKey) {
 		return nil, err
 	}
-	idsToDeleteArr := updateCRDT2PSetHeaders(p, versions, versioningEnabled)
+	idsToDeleteArr := updateCRDT2PSetHeaders(p, versions, versioning)
 
 	r := p.Reader
 	if len(p.Header[api.ContentType]) == 0 {
@@ -158,15 +164,34 @@ func (n *layer) objectPut(ctx context.Context, bkt *api.BucketInfo, p *PutObject
 	}
 	rawObject := formRawObject(p, bkt.CID, own, obj)
 
+	// There are no locks in NeoFS, so conditions are checked as late as possible.
+	if err = checkPutConditions(p.Conditions, versions); err != nil {
+		return nil, err
+	}
+
+	// removal of objects must not be limited, so delete markers aren't checked
+	var quota *quotaReservation
+	isDeleteMarker := p.Header[versionsDeleteMarkAttr] != ""
+	if !isDeleteMarker {
+		if quota, err = n.reserveQuota(ctx, bkt, p.Size, versions, idsToDeleteArr); err != nil {
+			return nil, err
+		}
+		defer quota.release()
+		r = quota.reader(r)
+	}
+
 	ops := new(client.PutObjectParams).WithObject(rawObject.Object()).WithPayloadReader(r)
 	oid, err := n.pool.PutObject(ctx, ops, n.BearerOpt(ctx))
 	if err != nil {
+		if quota != nil && quota.exceeded {
+			return nil, apiErrors.GetAPIError(apiErrors.ErrQuotaExceeded)
+		}
 		return nil, err
 	}
 
 	if p.Header[versionsDeleteMarkAttr] == delMarkFullObject {
 		if last := versions.getLast(); last != nil {
-			n.objCache.Delete(last.Address())
+			n.cache().objCache.Delete(last.Address())
 		}
 	}
 
@@ -175,21 +200,74 @@ func (n *layer) objectPut(ctx context.Context, bkt *api.BucketInfo, p *PutObject
 		return nil, err
 	}
 
-	if err = n.objCache.Put(*meta); err != nil {
+	if err = n.cache().objCache.Put(*meta); err != nil {
 		n.log.Error("couldn't cache an object", zap.Error(err))
 	}
 
-	n.listsCache.CleanCacheEntriesContainingObject(p.Object, bkt.CID)
+	if !isDeleteMarker {
+		n.usage.add(bkt.CID, int64(meta.PayloadSize()), 1)
+	}
+
+	n.cache().listsCache.CleanCacheEntriesContainingObject(p.Object, bkt.CID)
+
+	n.auditRecord(ctx, audit.Event{
+		Action:    audit.ActionObjectPut,
+		Bucket:    bkt.Name,
+		Container: bkt.CID.String(),
+		Object:    obj,
+		ObjectID:  oid.String(),
+	})
+
+	n.deleteOldVersions(ctx, bkt, versions, versioning, idsToDeleteArr)
+
+	return &api.ObjectInfo{
+		ID:          oid,
+		CID:         bkt.CID,
+		NullVersion: isNullVersion(p.Header),
+
+		Owner:         own,
+		Bucket:        p.Bucket,
+		Name:          p.Object,
+		Size:          p.Size,
+		Created:       time.Now(),
+		CreationEpoch: meta.CreationEpoch(),
+		Headers:       p.Header,
+		ContentType:   p.Header[api.ContentType],
+		HashSum:       meta.PayloadChecksum().String(),
+	}, nil
+}
+
+// deleteOldVersions removes objects replaced by the new one and their tags.
+func (n *layer) deleteOldVersions(ctx context.Context, bkt *api.BucketInfo, versions *objectVersions, versioning string, ids []*object.ID) {
+	if len(ids) == 0 {
+		return
+	}
+
+	ctx, span := tracing.StartSpan(ctx, "layer.deleteOldVersions",
+		tracing.AttrBucket.String(bkt.Name), attribute.Int("s3.versions", len(ids)))
+	defer span.End()
 
-	for _, id := range idsToDeleteArr {
-		if err = n.objectDelete(ctx, bkt.CID, id); err != nil {
+	for _, id := range ids {
+		if err := n.objectDelete(ctx, bkt.CID, id); err != nil {
 			n.log.Warn("couldn't delete object",
 				zap.Stringer("version id", id),
 				zap.Error(err))
+		} else {
+			ev := audit.Event{
+				Action:    audit.ActionObjectDelete,
+				Bucket:    bkt.Name,
+				Container: bkt.CID.String(),
+				ObjectID:  id.String(),
+			}
+			if objVersion := versions.getVersion(id); objVersion != nil {
+				ev.Object = objVersion.Name
+			}
+			n.auditRecord(ctx, ev)
+			n.releaseUsage(bkt, versions.getVersion(id))
 		}
-		if !versioningEnabled {
+		if versioning != VersioningEnabled {
 			if objVersion := versions.getVersion(id); objVersion != nil {
-				if err = n.DeleteObjectTagging(ctx, objVersion); err != nil {
+				if err := n.DeleteObjectTagging(ctx, objVersion); err != nil {
 					n.log.Warn("couldn't delete object tagging",
 						zap.Stringer("version id", id),
 						zap.Error(err))
@@ -197,21 +275,32 @@ func (n *layer) objectPut(ctx context.Context, bkt *api.BucketInfo, p *PutObject
 			}
 		}
 	}
+}
 
-	return &api.ObjectInfo{
-		ID:  oid,
-		CID: bkt.CID,
+func checkPutConditions(cond *PutConditions, versions *objectVersions) error {
+	if cond == nil {
+		return nil
+	}
 
-		Owner:         own,
-		Bucket:        p.Bucket,
-		Name:          p.Object,
-		Size:          p.Size,
-		Created:       time.Now(),
-		CreationEpoch: meta.CreationEpoch(),
-		Headers:       p.Header,
-		ContentType:   p.Header[api.ContentType],
-		HashSum:       meta.PayloadChecksum().String(),
-	}, nil
+	var lastVersion *api.ObjectInfo
+	if versions != nil {
+		lastVersion = versions.getLast()
+	}
+
+	if len(cond.IfNoneMatch) > 0 && lastVersion != nil &&
+		(cond.IfNoneMatch == "*" || trimETag(cond.IfNoneMatch) == lastVersion.HashSum) {
+		return apiErrors.GetAPIError(apiErrors.ErrPreconditionFailed)
+	}
+	if len(cond.IfMatch) > 0 && (lastVersion == nil ||
+		cond.IfMatch != "*" && trimETag(cond.IfMatch) != lastVersion.HashSum) {
+		return apiErrors.GetAPIError(apiErrors.ErrPreconditionFailed)
+	}
+
+	return nil
+}
+
+func trimETag(etag string) string {
+	return strings.Trim(etag, "\"")
 }
 
 func formRawObject(p *PutObjectParams, bktID *cid.ID, own *owner.ID, obj string) *object.RawObject {
@@ -242,13 +331,22 @@ func formRawObject(p *PutObjectParams, bktID *cid.ID, own *owner.ID, obj string)
 	return raw
 }
 
-func updateCRDT2PSetHeaders(p *PutObjectParams, versions *objectVersions, versioningEnabled bool) []*object.ID {
+func updateCRDT2PSetHeaders(p *PutObjectParams, versions *objectVersions, versioning string) []*object.ID {
 	var idsToDeleteArr []*object.ID
+
+	// Marks of deletion of specific versions are technical objects,
+	// they never become a null version.
+	isVersionDeleteMark := len(p.Header[versionsDeleteMarkAttr]) != 0 && p.Header[versionsDeleteMarkAttr] != delMarkFullObject
+	// The attribute is set for every version, objects without it are put by
+	// older gateways and considered to be the null version.
+	p.Header[versionsNullAttr] = strconv.FormatBool(versioning != VersioningEnabled && !isVersionDeleteMark)
+
 	if versions == nil {
 		return idsToDeleteArr
 	}
 
-	if versioningEnabled {
+	switch {
+	case versioning == VersioningEnabled || isVersionDeleteMark:
 		if len(versions.addList) != 0 {
 			p.Header[versionsAddAttr] = versions.getAddHeader()
 		}
@@ -264,21 +362,41 @@ func updateCRDT2PSetHeaders(p *PutObjectParams, versions *objectVersions, versio
 		} else if len(deleted) != 0 {
 			p.Header[versionsDelAttr] = deleted
 		}
-	} else {
+	case versioning == VersioningSuspended:
+		// Real versions are kept, only the null version is replaced.
+		if len(versions.addList) != 0 {
+			p.Header[versionsAddAttr] = versions.getAddHeader()
+		}
+
+		deleted := append([]string(nil), versions.delList...)
+		for _, nullVersion := range versions.getNullVersions() {
+			// objects without versioning attributes can be the first versions
+			// put by older gateways into a bucket with enabled versioning,
+			// so they are kept
+			if nullVersion.Headers[versionsNullAttr] == "" {
+				continue
+			}
+			deleted = append(deleted, nullVersion.ID.String())
+			idsToDeleteArr = append(idsToDeleteArr, nullVersion.ID)
+		}
+		if len(deleted) != 0 {
+			p.Header[versionsDelAttr] = strings.Join(deleted, ",")
+		}
+	default:
 		versionsDeletedStr := versions.getDelHeader()
 		if len(versionsDeletedStr) != 0 {
 			versionsDeletedStr += ","
 		}
 
 		if lastVersion := versions.getLast(); lastVersion != nil {
-			p.Header[versionsDelAttr] = versionsDeletedStr + lastVersion.Version()
+			p.Header[versionsDelAttr] = versionsDeletedStr + lastVersion.ID.String()
 			idsToDeleteArr = append(idsToDeleteArr, lastVersion.ID)
 		} else if len(versionsDeletedStr) != 0 {
 			p.Header[versionsDelAttr] = versionsDeletedStr
 		}
 
 		for _, version := range versions.objects {
-			if contains(versions.delList, version.Version()) {
+			if contains(versions.delList, version.ID.String()) {
 				idsToDeleteArr = append(idsToDeleteArr, version.ID)
 			}
 		}
@@ -288,8 +406,11 @@ func updateCRDT2PSetHeaders(p *PutObjectParams, versions *objectVersions, versio
 }
 
 func (n *layer) headLastVersionIfNotDeleted(ctx context.Context, bkt *api.BucketInfo, objectName string) (*api.ObjectInfo, error) {
-	if address := n.namesCache.Get(bkt.Name + "/" + objectName); address != nil {
-		if headInfo := n.objCache.Get(address); headInfo != nil {
+	ctx, span := tracing.StartSpan(ctx, "layer.headLastVersionIfNotDeleted", tracing.AttrBucket.String(bkt.Name), tracing.AttrObject.String(objectName))
+	defer span.End()
+
+	if address := n.cache().namesCache.Get(bkt.Name + "/" + objectName); address != nil {
+		if headInfo := n.cache().objCache.Get(address); headInfo != nil {
 			return objInfoFromMeta(bkt, headInfo), nil
 		}
 	}
@@ -304,7 +425,7 @@ func (n *layer) headLastVersionIfNotDeleted(ctx context.Context, bkt *api.Bucket
 		return nil, apiErrors.GetAPIError(apiErrors.ErrNoSuchKey)
 	}
 
-	if err = n.namesCache.Put(lastVersion.NiceName(), lastVersion.Address()); err != nil {
+	if err = n.cache().namesCache.Put(lastVersion.NiceName(), lastVersion.Address()); err != nil {
 		n.log.Warn("couldn't put obj address to head cache",
 			zap.String("obj nice name", lastVersion.NiceName()),
 			zap.Error(err))
@@ -314,6 +435,9 @@ func (n *layer) headLastVersionIfNotDeleted(ctx context.Context, bkt *api.Bucket
 }
 
 func (n *layer) headVersions(ctx context.Context, bkt *api.BucketInfo, objectName string) (*objectVersions, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.headVersions", tracing.AttrBucket.String(bkt.Name), tracing.AttrObject.String(objectName))
+	defer span.End()
+
 	ids, err := n.objectSearch(ctx, &findParams{cid: bkt.CID, val: objectName})
 	if err != nil {
 		return nil, err
@@ -333,7 +457,7 @@ func (n *layer) headVersions(ctx context.Context, bkt *api.BucketInfo, objectNam
 				zap.Error(err))
 			continue
 		}
-		if err = n.objCache.Put(*meta); err != nil {
+		if err = n.cache().objCache.Put(*meta); err != nil {
 			n.log.Warn("couldn't put meta to objects cache",
 				zap.Stringer("object id", id),
 				zap.Stringer("bucket id", bkt.CID),
@@ -351,13 +475,38 @@ func (n *layer) headVersions(ctx context.Context, bkt *api.BucketInfo, objectNam
 	return versions, nil
 }
 
+// headNullVersion returns the null version of the object, i.e. the one
+// which was put when bucket versioning was not enabled.
+func (n *layer) headNullVersion(ctx context.Context, bkt *api.BucketInfo, objectName string) (*api.ObjectInfo, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.headNullVersion", tracing.AttrBucket.String(bkt.Name), tracing.AttrObject.String(objectName))
+	defer span.End()
+
+	versions, err := n.headVersions(ctx, bkt, objectName)
+	if err != nil {
+		if apiErrors.IsS3Error(err, apiErrors.ErrNoSuchKey) {
+			return nil, apiErrors.GetAPIError(apiErrors.ErrNoSuchVersion)
+		}
+		return nil, err
+	}
+
+	nullVersions := versions.getNullVersions()
+	if len(nullVersions) == 0 {
+		return nil, apiErrors.GetAPIError(apiErrors.ErrNoSuchVersion)
+	}
+
+	return nullVersions[len(nullVersions)-1], nil
+}
+
 func (n *layer) headVersion(ctx context.Context, bkt *api.BucketInfo, versionID string) (*api.ObjectInfo, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.headVersion", tracing.AttrBucket.String(bkt.Name), tracing.AttrVersionID.String(versionID))
+	defer span.End()
+
 	oid := object.NewID()
 	if err := oid.Parse(versionID); err != nil {
 		return nil, err
 	}
 
-	if headInfo := n.objCache.Get(newAddress(bkt.CID, oid)); headInfo != nil {
+	if headInfo := n.cache().objCache.Get(newAddress(bkt.CID, oid)); headInfo != nil {
 		return objInfoFromMeta(bkt, headInfo), nil
 	}
 
@@ -370,7 +519,7 @@ func (n *layer) headVersion(ctx context.Context, bkt *api.BucketInfo, versionID
 	}
 
 	objInfo := objectInfoFromMeta(bkt, meta, "", "")
-	if err = n.objCache.Put(*meta); err != nil {
+	if err = n.cache().objCache.Put(*meta); err != nil {
 		n.log.Warn("couldn't put obj to object cache",
 			zap.String("bucket name", objInfo.Bucket),
 			zap.Stringer("bucket cid", objInfo.CID),
@@ -387,12 +536,15 @@ func (n *layer) objectDelete(ctx context.Context, cid *cid.ID, oid *object.ID) e
 	address := newAddress(cid, oid)
 	dop := new(client.DeleteObjectParams)
 	dop.WithAddress(address)
-	n.objCache.Delete(address)
+	n.cache().objCache.Delete(address)
 	return n.pool.DeleteObject(ctx, dop, n.BearerOpt(ctx))
 }
 
 // ListObjectsV1 returns objects in a bucket for requests of Version 1.
 func (n *layer) ListObjectsV1(ctx context.Context, p *ListObjectsParamsV1) (*ListObjectsInfoV1, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.ListObjectsV1", tracing.AttrBucket.String(p.Bucket))
+	defer span.End()
+
 	var (
 		err        error
 		result     ListObjectsInfoV1
@@ -428,6 +580,9 @@ func (n *layer) ListObjectsV1(ctx context.Context, p *ListObjectsParamsV1) (*Lis
 
 // ListObjectsV2 returns objects in a bucket for requests of Version 2.
 func (n *layer) ListObjectsV2(ctx context.Context, p *ListObjectsParamsV2) (*ListObjectsInfoV2, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.ListObjectsV2", tracing.AttrBucket.String(p.Bucket))
+	defer span.End()
+
 	var (
 		err        error
 		result     ListObjectsInfoV2
@@ -442,8 +597,15 @@ func (n *layer) ListObjectsV2(ctx context.Context, p *ListObjectsParamsV2) (*Lis
 		return nil, err
 	}
 
+	return paginateObjectsV2(p, allObjects), nil
+}
+
+// paginateObjectsV2 applies ListObjectsV2 pagination params to the sorted list of objects.
+func paginateObjectsV2(p *ListObjectsParamsV2, allObjects []*api.ObjectInfo) *ListObjectsInfoV2 {
+	var result ListObjectsInfoV2
+
 	if len(allObjects) == 0 {
-		return &result, nil
+		return &result
 	}
 
 	if p.ContinuationToken != "" {
@@ -462,7 +624,7 @@ func (n *layer) ListObjectsV2(ctx context.Context, p *ListObjectsParamsV2) (*Lis
 
 	result.Prefixes, result.Objects = triageObjects(allObjects)
 
-	return &result, nil
+	return &result
 }
 
 func (n *layer) listSortedObjects(ctx context.Context, p allObjectParams) ([]*api.ObjectInfo, error) {
@@ -487,17 +649,20 @@ func (n *layer) listSortedObjects(ctx context.Context, p allObjectParams) ([]*ap
 }
 
 func (n *layer) getAllObjectsVersions(ctx context.Context, bkt *api.BucketInfo, prefix, delimiter string) (map[string]*objectVersions, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.getAllObjectsVersions", tracing.AttrBucket.String(bkt.Name))
+	defer span.End()
+
 	var err error
 
 	cacheKey := cache.CreateObjectsListCacheKey(bkt.CID, prefix)
-	ids := n.listsCache.Get(cacheKey)
+	ids := n.cache().listsCache.Get(cacheKey)
 
 	if ids == nil {
 		ids, err = n.objectSearch(ctx, &findParams{cid: bkt.CID, prefix: prefix})
 		if err != nil {
 			return nil, err
 		}
-		if err := n.listsCache.Put(cacheKey, ids); err != nil {
+		if err := n.cache().listsCache.Put(cacheKey, ids); err != nil {
 			n.log.Error("couldn't cache list of objects", zap.Error(err))
 		}
 	}
@@ -610,20 +775,20 @@ func (n *layer) listAllObjects(ctx context.Context, p ListObjectsParamsCommon) (
 	return allObjects, nil
 }
 
-func (n *layer) isVersioningEnabled(ctx context.Context, bktInfo *api.BucketInfo) bool {
+func (n *layer) getVersioningStatus(ctx context.Context, bktInfo *api.BucketInfo) string {
 	settings, err := n.getBucketSettings(ctx, bktInfo)
 	if err != nil {
 		n.log.Warn("couldn't get versioning settings object", zap.Error(err))
-		return false
+		return VersioningUnversioned
 	}
 
-	return settings.VersioningEnabled
+	return settings.VersioningStatus
 }
 
 func (n *layer) objectFromObjectsCacheOrNeoFS(ctx context.Context, cid *cid.ID, oid *object.ID) *object.Object {
 	var (
 		err  error
-		meta = n.objCache.Get(newAddress(cid, oid))
+		meta = n.cache().objCache.Get(newAddress(cid, oid))
 	)
 	if meta == nil {
 		meta, err = n.objectHead(ctx, cid, oid)
@@ -631,7 +796,7 @@ func (n *layer) objectFromObjectsCacheOrNeoFS(ctx context.Context, cid *cid.ID,
 			n.log.Warn("could not fetch object meta", zap.Error(err))
 			return nil
 		}
-		if err = n.objCache.Put(*meta); err != nil {
+		if err = n.cache().objCache.Put(*meta); err != nil {
 			n.log.Error("couldn't cache an object", zap.Error(err))
 		}
 	}
@@ -1,12 +1,16 @@
 package layer
 
 import (
+	"bytes"
 	"crypto/rand"
 	"crypto/sha256"
+	"runtime"
 	"testing"
+	"time"
 
 	"github.com/nspcc-dev/neofs-api-go/pkg/object"
 	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -112,3 +116,90 @@ func TestTrimAfterObjectID(t *testing.T) {
 		require.Nil(t, actual)
 	})
 }
+
+func TestConditionalPut(t *testing.T) {
+	tc := prepareContext(t)
+
+	put := func(cond *PutConditions) (*api.ObjectInfo, error) {
+		return tc.layer.PutObject(tc.ctx, &PutObjectParams{
+			Bucket:     tc.bkt,
+			Object:     tc.obj,
+			Reader:     bytes.NewReader([]byte("content")),
+			Header:     make(map[string]string),
+			Conditions: cond,
+		})
+	}
+
+	_, err := put(&PutConditions{IfMatch: "*"})
+	require.True(t, errors.IsS3Error(err, errors.ErrPreconditionFailed))
+
+	objInfo, err := put(&PutConditions{IfNoneMatch: "*"})
+	require.NoError(t, err)
+
+	_, err = put(&PutConditions{IfNoneMatch: "*"})
+	require.True(t, errors.IsS3Error(err, errors.ErrPreconditionFailed))
+
+	_, err = put(&PutConditions{IfMatch: "\"wrong-etag\""})
+	require.True(t, errors.IsS3Error(err, errors.ErrPreconditionFailed))
+
+	objInfo, err = put(&PutConditions{IfMatch: "\"" + objInfo.HashSum + "\""})
+	require.NoError(t, err)
+
+	goroutines := runtime.NumGoroutine()
+	_, err = tc.layer.CopyObject(tc.ctx, &CopyObjectParams{
+		SrcObject:  objInfo,
+		DstBucket:  tc.bkt,
+		DstObject:  tc.obj,
+		SrcSize:    objInfo.Size,
+		Header:     map[string]string{api.ContentType: "text/plain"},
+		Conditions: &PutConditions{IfNoneMatch: "*"},
+	})
+	require.True(t, errors.IsS3Error(err, errors.ErrPreconditionFailed))
+	// source object reader mustn't be left blocked
+	for i := 0; i < 100 && runtime.NumGoroutine() > goroutines; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.LessOrEqual(t, runtime.NumGoroutine(), goroutines)
+}
+
+func TestGetObjectsTagging(t *testing.T) {
+	tc := prepareContext(t)
+
+	put := func(name string, tagSet map[string]string) *api.ObjectInfo {
+		obj, err := tc.layer.PutObject(tc.ctx, &PutObjectParams{
+			Bucket: tc.bkt,
+			Object: name,
+			Reader: bytes.NewReader([]byte("content")),
+			Header: make(map[string]string),
+		})
+		require.NoError(t, err)
+		if tagSet != nil {
+			require.NoError(t, tc.layer.PutObjectTagging(tc.ctx, &PutTaggingParams{ObjectInfo: obj, TagSet: tagSet}))
+		}
+		return obj
+	}
+
+	tagged := put("dir/obj1", map[string]string{"key": "value"})
+	untagged := put("dir/obj2", nil)
+	retagged := put("obj3", map[string]string{"key": "old"})
+	require.NoError(t, tc.layer.PutObjectTagging(tc.ctx, &PutTaggingParams{
+		ObjectInfo: retagged,
+		TagSet:     map[string]string{"key": "new", "other": ""},
+	}))
+
+	tagSets, err := tc.layer.GetObjectsTagging(tc.ctx, []*api.ObjectInfo{tagged, untagged, retagged})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]string{
+		{"key": "value"},
+		nil,
+		{"key": "new", "other": ""},
+	}, tagSets)
+
+	tagSets, err = tc.layer.GetObjectsTagging(tc.ctx, []*api.ObjectInfo{untagged, tagged})
+	require.NoError(t, err)
+	require.Equal(t, []map[string]string{nil, {"key": "value"}}, tagSets)
+
+	tagSets, err = tc.layer.GetObjectsTagging(tc.ctx, nil)
+	require.NoError(t, err)
+	require.Empty(t, tagSets)
+}
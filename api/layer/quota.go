@@ -0,0 +1,419 @@
+package layer
+
+import (
+	"context"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/tracing"
+	"go.uber.org/zap"
+)
+
+type (
+	// BucketQuota limits total payload size and number of objects of all
+	// versions in the bucket, zero means no limit.
+	BucketQuota struct {
+		MaxBytes   uint64
+		MaxObjects uint64
+	}
+
+	// BucketUsage is the space used by all versions of the bucket objects.
+	BucketUsage struct {
+		Bytes   uint64
+		Objects uint64
+		// Reconciled is the time of the last full scan of the bucket.
+		Reconciled time.Time
+	}
+
+	// PutBucketQuotaParams stores quota setting request parameters.
+	PutBucketQuotaParams struct {
+		Bucket string
+		Quota  BucketQuota
+	}
+
+	// BucketQuotaInfo contains quota of the bucket and its usage.
+	BucketQuotaInfo struct {
+		Quota BucketQuota
+		Usage BucketUsage
+	}
+
+	// usageCounters tracks usage of the buckets with quota. Counters are
+	// updated on every put and delete and replaced by the result of the
+	// periodic scan of the bucket. They are local to the gateway, so changes
+	// made by other gateways are seen after the scan only.
+	usageCounters struct {
+		mu      sync.Mutex
+		buckets map[string]*bucketUsage
+	}
+
+	bucketUsage struct {
+		bkt   *api.BucketInfo
+		usage BucketUsage
+		// space reserved by the objects being put
+		reservedBytes   uint64
+		reservedObjects uint64
+	}
+
+	// quotaReservation is the space reserved in the bucket for the object
+	// being put. Payload bytes beyond the reserved ones are reserved while
+	// the payload is read, so objects of unknown size are limited too.
+	quotaReservation struct {
+		counters *usageCounters
+		id       *cid.ID
+		quota    BucketQuota
+		// credit is the space of the versions replaced by the object
+		credit   int64
+		bytes    int64
+		objects  int64
+		read     int64
+		exceeded bool
+	}
+
+	quotaReader struct {
+		r   io.Reader
+		res *quotaReservation
+	}
+)
+
+const (
+	attrSettingsQuotaBytes   = "S3-Settings-Quota-bytes"
+	attrSettingsQuotaObjects = "S3-Settings-Quota-objects"
+)
+
+func newUsageCounters() *usageCounters {
+	return &usageCounters{buckets: make(map[string]*bucketUsage)}
+}
+
+// Unlimited checks if the quota doesn't limit anything.
+func (q BucketQuota) Unlimited() bool {
+	return q.MaxBytes == 0 && q.MaxObjects == 0
+}
+
+func (c *usageCounters) get(id *cid.ID) (BucketUsage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if u, ok := c.buckets[id.String()]; ok {
+		return u.usage, true
+	}
+	return BucketUsage{}, false
+}
+
+func (c *usageCounters) tracked(id *cid.ID) bool {
+	_, ok := c.get(id)
+	return ok
+}
+
+func (c *usageCounters) set(bkt *api.BucketInfo, usage BucketUsage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if u, ok := c.buckets[bkt.CID.String()]; ok {
+		u.bkt, u.usage = bkt, usage
+		return
+	}
+	c.buckets[bkt.CID.String()] = &bucketUsage{bkt: bkt, usage: usage}
+}
+
+// reserve checks that the tracked bucket has the space for bytes and
+// objects taking into account the space reserved earlier and reserves it.
+func (c *usageCounters) reserve(id *cid.ID, quota BucketQuota, bytes, objects uint64) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	u, ok := c.buckets[id.String()]
+	if !ok {
+		// quota has been removed
+		return true
+	}
+	if quota.MaxBytes > 0 && bytes > 0 && u.usage.Bytes+u.reservedBytes+bytes > quota.MaxBytes ||
+		quota.MaxObjects > 0 && objects > 0 && u.usage.Objects+u.reservedObjects+objects > quota.MaxObjects {
+		return false
+	}
+	u.reservedBytes += bytes
+	u.reservedObjects += objects
+	return true
+}
+
+func (c *usageCounters) release(id *cid.ID, bytes, objects uint64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	u, ok := c.buckets[id.String()]
+	if !ok {
+		return
+	}
+	u.reservedBytes = addClamped(u.reservedBytes, -int64(bytes))
+	u.reservedObjects = addClamped(u.reservedObjects, -int64(objects))
+}
+
+// add changes the usage of the tracked bucket, others are ignored.
+func (c *usageCounters) add(id *cid.ID, bytes, objects int64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	u, ok := c.buckets[id.String()]
+	if !ok {
+		return
+	}
+	u.usage.Bytes = addClamped(u.usage.Bytes, bytes)
+	u.usage.Objects = addClamped(u.usage.Objects, objects)
+}
+
+func (c *usageCounters) forget(id *cid.ID) {
+	c.mu.Lock()
+	delete(c.buckets, id.String())
+	c.mu.Unlock()
+}
+
+func (c *usageCounters) list() []*api.BucketInfo {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	res := make([]*api.BucketInfo, 0, len(c.buckets))
+	for _, u := range c.buckets {
+		res = append(res, u.bkt)
+	}
+	return res
+}
+
+func addClamped(v uint64, delta int64) uint64 {
+	if delta < 0 && uint64(-delta) > v {
+		return 0
+	}
+	return uint64(int64(v) + delta)
+}
+
+// usageOf returns the space used by the object version, delete markers are not counted.
+func usageOf(oi *api.ObjectInfo) (int64, int64) {
+	if oi == nil || oi.Headers[versionsDeleteMarkAttr] != "" {
+		return 0, 0
+	}
+	return oi.Size, 1
+}
+
+func quotaToMetadata(q BucketQuota, metadata map[string]string) {
+	if q.MaxBytes > 0 {
+		metadata[attrSettingsQuotaBytes] = strconv.FormatUint(q.MaxBytes, 10)
+	}
+	if q.MaxObjects > 0 {
+		metadata[attrSettingsQuotaObjects] = strconv.FormatUint(q.MaxObjects, 10)
+	}
+}
+
+func quotaFromHeaders(headers map[string]string) BucketQuota {
+	var q BucketQuota
+	if v, err := strconv.ParseUint(headers[attrSettingsQuotaBytes], 10, 64); err == nil {
+		q.MaxBytes = v
+	}
+	if v, err := strconv.ParseUint(headers[attrSettingsQuotaObjects], 10, 64); err == nil {
+		q.MaxObjects = v
+	}
+	return q
+}
+
+// PutBucketQuota sets the quota of the bucket keeping the other settings.
+func (n *layer) PutBucketQuota(ctx context.Context, p *PutBucketQuotaParams) error {
+	ctx, span := tracing.StartSpan(ctx, "layer.PutBucketQuota", tracing.AttrBucket.String(p.Bucket))
+	defer span.End()
+
+	bktInfo, err := n.GetBucketInfo(ctx, p.Bucket)
+	if err != nil {
+		return err
+	}
+
+	settings, err := n.getBucketSettingsOrDefault(ctx, bktInfo)
+	if err != nil {
+		return err
+	}
+
+	settings.Quota = p.Quota
+	if _, err = n.putBucketSettings(ctx, bktInfo, settings); err != nil {
+		return err
+	}
+
+	if p.Quota.Unlimited() {
+		n.usage.forget(bktInfo.CID)
+	}
+
+	return nil
+}
+
+// GetBucketQuota returns the quota of the bucket and its current usage.
+func (n *layer) GetBucketQuota(ctx context.Context, bucket string) (*BucketQuotaInfo, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.GetBucketQuota", tracing.AttrBucket.String(bucket))
+	defer span.End()
+
+	bktInfo, err := n.GetBucketInfo(ctx, bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := n.getBucketSettingsOrDefault(ctx, bktInfo)
+	if err != nil {
+		return nil, err
+	}
+
+	usage, ok := n.usage.get(bktInfo.CID)
+	if !ok {
+		if usage, err = n.reconcileUsage(ctx, bktInfo, !settings.Quota.Unlimited()); err != nil {
+			return nil, err
+		}
+	}
+
+	return &BucketQuotaInfo{Quota: settings.Quota, Usage: usage}, nil
+}
+
+// ReconcileUsage recalculates usage of all the buckets with quota checked since start.
+func (n *layer) ReconcileUsage(ctx context.Context) {
+	for _, bkt := range n.usage.list() {
+		if _, err := n.reconcileUsage(ctx, bkt, true); err != nil {
+			n.log.Warn("couldn't reconcile bucket usage",
+				zap.String("bucket", bkt.Name),
+				zap.Error(err))
+		}
+	}
+}
+
+// reconcileUsage calculates usage of the bucket listing all object versions.
+// The result is saved if track is true.
+func (n *layer) reconcileUsage(ctx context.Context, bkt *api.BucketInfo, track bool) (BucketUsage, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.reconcileUsage", tracing.AttrBucket.String(bkt.Name))
+	defer span.End()
+
+	versions, err := n.getAllObjectsVersions(ctx, bkt, "", "")
+	if err != nil {
+		return BucketUsage{}, err
+	}
+
+	usage := BucketUsage{Reconciled: time.Now()}
+	for _, v := range versions {
+		for _, oi := range v.objects {
+			bytes, objects := usageOf(oi)
+			usage.Bytes += uint64(bytes)
+			usage.Objects += uint64(objects)
+		}
+	}
+
+	if track {
+		n.usage.set(bkt, usage)
+	}
+
+	return usage, nil
+}
+
+// reserveQuota reserves the space in the bucket for the object of the given
+// size replacing the versions being deleted. QuotaExceeded error is returned
+// if there is no space, nil reservation means the bucket has no quota.
+// Negative size means the size is unknown, the space is reserved while the
+// payload is read then.
+func (n *layer) reserveQuota(ctx context.Context, bkt *api.BucketInfo, size int64, versions *objectVersions, replaced []*object.ID) (*quotaReservation, error) {
+	settings, err := n.getBucketSettingsOrDefault(ctx, bkt)
+	if err != nil {
+		return nil, err
+	}
+	if settings.Quota.Unlimited() {
+		return nil, nil
+	}
+
+	if !n.usage.tracked(bkt.CID) {
+		if _, err = n.reconcileUsage(ctx, bkt, true); err != nil {
+			return nil, err
+		}
+	}
+
+	res := &quotaReservation{
+		counters: n.usage,
+		id:       bkt.CID,
+		quota:    settings.Quota,
+	}
+	objects := int64(1)
+	for _, id := range replaced {
+		b, o := usageOf(versions.getVersion(id))
+		res.credit += b
+		objects -= o
+	}
+
+	var bytes int64
+	if size > 0 {
+		bytes = size - res.credit
+	}
+	if err = res.reserve(bytes, objects); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// reserve reserves positive amounts of space.
+func (r *quotaReservation) reserve(bytes, objects int64) error {
+	if bytes < 0 {
+		bytes = 0
+	}
+	if objects < 0 {
+		objects = 0
+	}
+	if !r.counters.reserve(r.id, r.quota, uint64(bytes), uint64(objects)) {
+		r.exceeded = true
+		return errors.GetAPIError(errors.ErrQuotaExceeded)
+	}
+	r.bytes += bytes
+	r.objects += objects
+	return nil
+}
+
+// reader returns the reader reserving the space for the read payload.
+func (r *quotaReservation) reader(src io.Reader) io.Reader {
+	if r == nil {
+		return src
+	}
+	return &quotaReader{r: src, res: r}
+}
+
+// release returns the reserved space, it must be called after the usage of
+// the put object is added.
+func (r *quotaReservation) release() {
+	if r == nil {
+		return
+	}
+	r.counters.release(r.id, uint64(r.bytes), uint64(r.objects))
+	r.bytes, r.objects = 0, 0
+}
+
+func (q *quotaReader) Read(p []byte) (int, error) {
+	n, err := q.r.Read(p)
+	q.res.read += int64(n)
+	if need := q.res.read - q.res.credit - q.res.bytes; need > 0 {
+		if rErr := q.res.reserve(need, 0); rErr != nil {
+			return n, rErr
+		}
+	}
+	return n, err
+}
+
+// objectUsageInfo returns info of the object to release its usage after
+// removal, nil is returned if the bucket isn't tracked.
+func (n *layer) objectUsageInfo(ctx context.Context, bkt *api.BucketInfo, id *object.ID) *api.ObjectInfo {
+	if !n.usage.tracked(bkt.CID) {
+		return nil
+	}
+
+	meta := n.objectFromObjectsCacheOrNeoFS(ctx, bkt.CID, id)
+	if meta == nil {
+		return nil
+	}
+
+	return objInfoFromMeta(bkt, meta)
+}
+
+// releaseUsage decreases usage of the bucket by the removed object.
+func (n *layer) releaseUsage(bkt *api.BucketInfo, oi *api.ObjectInfo) {
+	bytes, objects := usageOf(oi)
+	n.usage.add(bkt.CID, -bytes, -objects)
+}
@@ -0,0 +1,164 @@
+package layer
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func (tc *testContext) putObjectErr(name string, content []byte) error {
+	_, err := tc.layer.PutObject(tc.ctx, &PutObjectParams{
+		Bucket: tc.bkt,
+		Object: name,
+		Size:   int64(len(content)),
+		Reader: bytes.NewReader(content),
+		Header: make(map[string]string),
+	})
+	return err
+}
+
+func (tc *testContext) checkUsage(bytes, objects uint64) {
+	info, err := tc.layer.GetBucketQuota(tc.ctx, tc.bkt)
+	require.NoError(tc.t, err)
+	require.Equal(tc.t, bytes, info.Usage.Bytes)
+	require.Equal(tc.t, objects, info.Usage.Objects)
+}
+
+func TestBucketQuotaNoVersioning(t *testing.T) {
+	tc := prepareContext(t)
+
+	require.NoError(t, tc.putObjectErr("obj0", []byte("12345")))
+
+	err := tc.layer.PutBucketQuota(tc.ctx, &PutBucketQuotaParams{
+		Bucket: tc.bkt,
+		Quota:  BucketQuota{MaxBytes: 10, MaxObjects: 2},
+	})
+	require.NoError(t, err)
+	tc.checkUsage(5, 1)
+
+	// the object is replaced, so only the difference in size is counted
+	require.NoError(t, tc.putObjectErr("obj0", []byte("1234567")))
+	tc.checkUsage(7, 1)
+
+	err = tc.putObjectErr("obj1", []byte("1234"))
+	require.True(t, errors.IsS3Error(err, errors.ErrQuotaExceeded))
+
+	require.NoError(t, tc.putObjectErr("obj1", []byte("123")))
+	tc.checkUsage(10, 2)
+
+	err = tc.putObjectErr("obj2", nil)
+	require.True(t, errors.IsS3Error(err, errors.ErrQuotaExceeded))
+
+	tc.deleteObject("obj0", "")
+	tc.checkUsage(3, 1)
+	require.NoError(t, tc.putObjectErr("obj2", []byte("1234567")))
+}
+
+func TestBucketQuotaVersioning(t *testing.T) {
+	tc := prepareContext(t)
+
+	err := tc.layer.PutBucketQuota(tc.ctx, &PutBucketQuotaParams{
+		Bucket: tc.bkt,
+		Quota:  BucketQuota{MaxObjects: 2},
+	})
+	require.NoError(t, err)
+
+	_, err = tc.layer.PutBucketVersioning(tc.ctx, &PutVersioningParams{
+		Bucket:   tc.bkt,
+		Settings: &BucketSettings{VersioningStatus: VersioningEnabled},
+	})
+	require.NoError(t, err)
+
+	info, err := tc.layer.GetBucketQuota(tc.ctx, tc.bkt)
+	require.NoError(t, err)
+	require.Equal(t, BucketQuota{MaxObjects: 2}, info.Quota)
+
+	obj1v1 := tc.putObject([]byte("content obj1 v1"))
+	tc.putObject([]byte("content obj1 v2"))
+
+	// all versions are counted
+	err = tc.putObjectErr(tc.obj, []byte("content obj1 v3"))
+	require.True(t, errors.IsS3Error(err, errors.ErrQuotaExceeded))
+
+	// delete markers are allowed and take no space
+	tc.deleteObject(tc.obj, "")
+	tc.checkUsage(30, 2)
+
+	tc.deleteObject(tc.obj, obj1v1.ID.String())
+	tc.checkUsage(15, 1)
+	tc.putObject([]byte("content obj1 v3"))
+
+	// usage is the same after the full scan
+	tc.layer.ReconcileUsage(tc.ctx)
+	tc.checkUsage(30, 2)
+}
+
+func TestBucketQuotaUnknownSize(t *testing.T) {
+	tc := prepareContext(t)
+
+	err := tc.layer.PutBucketQuota(tc.ctx, &PutBucketQuotaParams{
+		Bucket: tc.bkt,
+		Quota:  BucketQuota{MaxBytes: 10},
+	})
+	require.NoError(t, err)
+
+	putChunked := func(name string, content []byte) error {
+		_, err := tc.layer.PutObject(tc.ctx, &PutObjectParams{
+			Bucket: tc.bkt,
+			Object: name,
+			Size:   -1,
+			Reader: bytes.NewReader(content),
+			Header: make(map[string]string),
+		})
+		return err
+	}
+
+	require.NoError(t, putChunked("obj0", []byte("123456")))
+	tc.checkUsage(6, 1)
+
+	err = putChunked("obj1", []byte("12345"))
+	require.True(t, errors.IsS3Error(err, errors.ErrQuotaExceeded))
+	tc.checkUsage(6, 1)
+
+	// the space of the replaced object is available
+	require.NoError(t, putChunked("obj0", []byte("1234567890")))
+	tc.checkUsage(10, 1)
+}
+
+func TestBucketQuotaReservation(t *testing.T) {
+	tc := prepareContext(t)
+
+	err := tc.layer.PutBucketQuota(tc.ctx, &PutBucketQuotaParams{
+		Bucket: tc.bkt,
+		Quota:  BucketQuota{MaxBytes: 10, MaxObjects: 2},
+	})
+	require.NoError(t, err)
+
+	n := tc.layer.(*layer)
+	bkt, err := n.GetBucketInfo(tc.ctx, tc.bkt)
+	require.NoError(t, err)
+
+	// concurrent puts can't use the same space
+	res1, err := n.reserveQuota(tc.ctx, bkt, 6, nil, nil)
+	require.NoError(t, err)
+	_, err = n.reserveQuota(tc.ctx, bkt, 6, nil, nil)
+	require.True(t, errors.IsS3Error(err, errors.ErrQuotaExceeded))
+
+	res2, err := n.reserveQuota(tc.ctx, bkt, -1, nil, nil)
+	require.NoError(t, err)
+	_, err = n.reserveQuota(tc.ctx, bkt, 0, nil, nil)
+	require.True(t, errors.IsS3Error(err, errors.ErrQuotaExceeded))
+
+	// payload of unknown size is limited by the remaining space
+	_, err = io.ReadAll(res2.reader(bytes.NewReader([]byte("12345"))))
+	require.True(t, errors.IsS3Error(err, errors.ErrQuotaExceeded))
+
+	// the space is available after release
+	res1.release()
+	res2.release()
+	_, err = n.reserveQuota(tc.ctx, bkt, 10, nil, nil)
+	require.NoError(t, err)
+}
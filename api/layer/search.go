@@ -0,0 +1,137 @@
+package layer
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/tracing"
+)
+
+type (
+	// SearchMatchType is a type of matching attribute value in SearchFilter.
+	SearchMatchType string
+
+	// SearchFilter is a filter on object user metadata.
+	SearchFilter struct {
+		Key   string
+		Value string
+		Match SearchMatchType
+	}
+
+	// SearchObjectsParams stores params for search of objects by user metadata.
+	SearchObjectsParams struct {
+		ListObjectsParamsV2
+		Filters []SearchFilter
+	}
+)
+
+// Supported match types of SearchFilter.
+const (
+	SearchMatchStringEqual    SearchMatchType = "StringEqual"
+	SearchMatchStringNotEqual SearchMatchType = "StringNotEqual"
+	SearchMatchCommonPrefix   SearchMatchType = "CommonPrefix"
+)
+
+func (m SearchMatchType) toSearchMatchType() (object.SearchMatchType, bool) {
+	switch m {
+	case SearchMatchStringEqual, "":
+		return object.MatchStringEqual, true
+	case SearchMatchStringNotEqual:
+		return object.MatchStringNotEqual, true
+	case SearchMatchCommonPrefix:
+		return object.MatchCommonPrefix, true
+	default:
+		return object.MatchUnknown, false
+	}
+}
+
+// metadataAttributeKey converts user metadata key to the NeoFS attribute key
+// the same way it's done on object upload.
+func metadataAttributeKey(key string) string {
+	key = strings.ToLower(key)
+	return strings.TrimPrefix(key, strings.ToLower(api.MetadataPrefix))
+}
+
+func formSearchFilters(p *SearchObjectsParams) (object.SearchFilters, error) {
+	var opts object.SearchFilters
+
+	opts.AddRootFilter()
+	if p.Prefix != "" {
+		opts.AddFilter(object.AttributeFileName, p.Prefix, object.MatchCommonPrefix)
+	}
+
+	for _, f := range p.Filters {
+		key := metadataAttributeKey(f.Key)
+		if key == "" {
+			return nil, errors.GetAPIError(errors.ErrInvalidArgument)
+		}
+		match, ok := f.Match.toSearchMatchType()
+		if !ok {
+			return nil, errors.GetAPIError(errors.ErrInvalidArgument)
+		}
+		opts.AddFilter(key, f.Value, match)
+	}
+
+	return opts, nil
+}
+
+// SearchObjects returns the latest versions of objects whose user metadata matches
+// all the provided filters. Filters are applied by NeoFS storage nodes.
+func (n *layer) SearchObjects(ctx context.Context, p *SearchObjectsParams) (*ListObjectsInfoV2, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.SearchObjects", tracing.AttrBucket.String(p.Bucket))
+	defer span.End()
+
+	if p.MaxKeys == 0 {
+		return &ListObjectsInfoV2{}, nil
+	}
+
+	bkt, err := n.GetBucketInfo(ctx, p.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	opts, err := formSearchFilters(p)
+	if err != nil {
+		return nil, err
+	}
+
+	ids, err := n.pool.SearchObject(ctx, new(client.SearchObjectParams).WithContainerID(bkt.CID).WithSearchFilters(opts), n.BearerOpt(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return &ListObjectsInfoV2{}, nil
+	}
+
+	matched := make(map[string]struct{}, len(ids))
+	for _, id := range ids {
+		matched[id.String()] = struct{}{}
+	}
+
+	versions, err := n.getAllObjectsVersions(ctx, bkt, p.Prefix, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Object matches only if its latest visible version matches,
+	// otherwise listing would expose overwritten or deleted data.
+	objects := make([]*api.ObjectInfo, 0, len(ids))
+	for _, v := range versions {
+		if lastVersion := v.getLast(); lastVersion != nil {
+			if _, ok := matched[lastVersion.ID.String()]; ok {
+				objects = append(objects, lastVersion)
+			}
+		}
+	}
+
+	sort.Slice(objects, func(i, j int) bool {
+		return objects[i].Name < objects[j].Name
+	})
+
+	return paginateObjectsV2(&p.ListObjectsParamsV2, objects), nil
+}
@@ -0,0 +1,82 @@
+package layer
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/stretchr/testify/require"
+)
+
+func (tc *testContext) putObjectWithMeta(name, color string) *api.ObjectInfo {
+	content := []byte("content " + name)
+	objInfo, err := tc.layer.PutObject(tc.ctx, &PutObjectParams{
+		Bucket: tc.bkt,
+		Object: name,
+		Size:   int64(len(content)),
+		Reader: bytes.NewReader(content),
+		Header: map[string]string{"color": color},
+	})
+	require.NoError(tc.t, err)
+
+	return objInfo
+}
+
+func (tc *testContext) searchObjects(filters ...SearchFilter) []string {
+	res, err := tc.layer.SearchObjects(tc.ctx, &SearchObjectsParams{
+		ListObjectsParamsV2: ListObjectsParamsV2{
+			ListObjectsParamsCommon: ListObjectsParamsCommon{
+				Bucket:  tc.bkt,
+				MaxKeys: 1000,
+			},
+		},
+		Filters: filters,
+	})
+	require.NoError(tc.t, err)
+
+	names := make([]string, 0, len(res.Objects))
+	for _, obj := range res.Objects {
+		names = append(names, obj.Name)
+	}
+	return names
+}
+
+func TestSearchObjects(t *testing.T) {
+	tc := prepareContext(t)
+	_, err := tc.layer.PutBucketVersioning(tc.ctx, &PutVersioningParams{
+		Bucket:   tc.bkt,
+		Settings: &BucketSettings{VersioningStatus: VersioningEnabled},
+	})
+	require.NoError(t, err)
+
+	tc.putObjectWithMeta("obj1", "red")
+	tc.putObjectWithMeta("obj1", "blue")
+	tc.putObjectWithMeta("obj2", "red")
+	tc.putObjectWithMeta("obj3", "black")
+	tc.deleteObject("obj3", "")
+
+	t.Run("equal filter skips old versions", func(t *testing.T) {
+		names := tc.searchObjects(SearchFilter{Key: "X-Amz-Meta-Color", Value: "red"})
+		require.Equal(t, []string{"obj2"}, names)
+	})
+
+	t.Run("not equal filter", func(t *testing.T) {
+		names := tc.searchObjects(SearchFilter{Key: "color", Value: "red", Match: SearchMatchStringNotEqual})
+		require.Equal(t, []string{"obj1"}, names)
+	})
+
+	t.Run("prefix filter skips deleted objects", func(t *testing.T) {
+		names := tc.searchObjects(SearchFilter{Key: "color", Value: "bl", Match: SearchMatchCommonPrefix})
+		require.Equal(t, []string{"obj1"}, names)
+	})
+
+	t.Run("invalid match type", func(t *testing.T) {
+		_, err := tc.layer.SearchObjects(tc.ctx, &SearchObjectsParams{
+			ListObjectsParamsV2: ListObjectsParamsV2{
+				ListObjectsParamsCommon: ListObjectsParamsCommon{Bucket: tc.bkt, MaxKeys: 1000},
+			},
+			Filters: []SearchFilter{{Key: "color", Value: "red", Match: "Regexp"}},
+		})
+		require.Error(t, err)
+	})
+}
@@ -55,6 +55,17 @@ type (
 // PathSeparator is a path components separator string.
 const PathSeparator = string(os.PathSeparator)
 
+const (
+	gateSystemHeaderPrefix  = "S3-"
+	neofsSystemHeaderPrefix = "__NEOFS__"
+)
+
+// IsSystemHeader checks if the object header is set by the gateway or NeoFS
+// for internal purposes and isn't a part of user metadata.
+func IsSystemHeader(key string) bool {
+	return strings.HasPrefix(key, gateSystemHeaderPrefix) || strings.HasPrefix(key, neofsSystemHeaderPrefix)
+}
+
 func userHeaders(attrs []*object.Attribute) map[string]string {
 	result := make(map[string]string, len(attrs))
 
@@ -83,6 +94,7 @@ func objectInfoFromMeta(bkt *api.BucketInfo, meta *object.Object, prefix, delimi
 	}
 
 	userHeaders := userHeaders(meta.Attributes())
+	nullVersion := isNullVersion(userHeaders)
 	delete(userHeaders, object.AttributeFileName)
 	if contentType, ok := userHeaders[object.AttributeContentType]; ok {
 		mimeType = contentType
@@ -111,9 +123,11 @@ func objectInfoFromMeta(bkt *api.BucketInfo, meta *object.Object, prefix, delimi
 	}
 
 	return &api.ObjectInfo{
-		ID:    meta.ID(),
-		CID:   bkt.CID,
-		IsDir: isDir,
+		ID:             meta.ID(),
+		CID:            bkt.CID,
+		IsDir:          isDir,
+		IsDeleteMarker: userHeaders[versionsDeleteMarkAttr] == delMarkFullObject,
+		NullVersion:    nullVersion,
 
 		Bucket:        bkt.Name,
 		Name:          filename,
@@ -55,6 +55,8 @@ func newTestInfo(oid *object.ID, bkt *api.BucketInfo, name string, isDir bool) *
 		Created:     time.Unix(defaultTestCreated.Unix(), 0),
 		Owner:       bkt.Owner,
 		Headers:     make(map[string]string),
+		// objects without versioning attributes
+		NullVersion: true,
 	}
 
 	if isDir {
@@ -9,6 +9,7 @@ import (
 	"github.com/nspcc-dev/neofs-api-go/pkg/object"
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/tracing"
 )
 
 type objectVersions struct {
@@ -20,22 +21,41 @@ type objectVersions struct {
 }
 
 const (
-	unversionedObjectVersionID    = "null"
+	unversionedObjectVersionID    = api.NullVersionID
 	objectSystemAttributeName     = "S3-System-name"
 	attrVersionsIgnore            = "S3-Versions-ignore"
 	attrSettingsVersioningEnabled = "S3-Settings-Versioning-enabled"
+	attrSettingsVersioning        = "S3-Settings-Versioning"
 	versionsDelAttr               = "S3-Versions-del"
 	versionsAddAttr               = "S3-Versions-add"
 	versionsDeleteMarkAttr        = "S3-Versions-delete-mark"
+	versionsNullAttr              = "S3-Versions-null"
 	delMarkFullObject             = "*"
 )
 
+// isNullVersion checks if the object with the attributes is the null version.
+// Objects without versioning attributes are put before versioning was
+// supported, so they're the null version too.
+func isNullVersion(headers map[string]string) bool {
+	if null, ok := headers[versionsNullAttr]; ok {
+		res, _ := strconv.ParseBool(null)
+		return res
+	}
+
+	for _, attr := range []string{versionsAddAttr, versionsDelAttr, versionsDeleteMarkAttr} {
+		if _, ok := headers[attr]; ok {
+			return false
+		}
+	}
+	return true
+}
+
 func newObjectVersions(name string) *objectVersions {
 	return &objectVersions{name: name}
 }
 
 func (v *objectVersions) appendVersion(oi *api.ObjectInfo) {
-	addVers := append(splitVersions(oi.Headers[versionsAddAttr]), oi.Version())
+	addVers := append(splitVersions(oi.Headers[versionsAddAttr]), oi.ID.String())
 	delVers := splitVersions(oi.Headers[versionsDelAttr])
 	v.objects = append(v.objects, oi)
 	for _, add := range addVers {
@@ -68,7 +88,7 @@ func (v *objectVersions) getLast() *api.ObjectInfo {
 	v.sort()
 	existedVersions := getExistedVersions(v)
 	for i := len(v.objects) - 1; i >= 0; i-- {
-		if contains(existedVersions, v.objects[i].Version()) {
+		if contains(existedVersions, v.objects[i].ID.String()) {
 			delMarkHeader := v.objects[i].Headers[versionsDeleteMarkAttr]
 			if delMarkHeader == "" {
 				return v.objects[i]
@@ -93,7 +113,27 @@ func (v *objectVersions) getFiltered() []*api.ObjectInfo {
 
 	for _, version := range v.objects {
 		delMark := version.Headers[versionsDeleteMarkAttr]
-		if contains(existedVersions, version.Version()) && (delMark == delMarkFullObject || delMark == "") {
+		if contains(existedVersions, version.ID.String()) && (delMark == delMarkFullObject || delMark == "") {
+			res = append(res, version)
+		}
+	}
+
+	return res
+}
+
+// getNullVersions returns existing versions which were put when versioning
+// wasn't enabled, normally there is at most one such version.
+func (v *objectVersions) getNullVersions() []*api.ObjectInfo {
+	if len(v.objects) == 0 {
+		return nil
+	}
+
+	v.sort()
+	existedVersions := getExistedVersions(v)
+	var res []*api.ObjectInfo
+
+	for _, version := range v.objects {
+		if version.NullVersion && contains(existedVersions, version.ID.String()) {
 			res = append(res, version)
 		}
 	}
@@ -118,14 +158,29 @@ func (v *objectVersions) getVersion(oid *object.ID) *api.ObjectInfo {
 	return nil
 }
 func (n *layer) PutBucketVersioning(ctx context.Context, p *PutVersioningParams) (*api.ObjectInfo, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.PutBucketVersioning", tracing.AttrBucket.String(p.Bucket))
+	defer span.End()
+
 	bktInfo, err := n.GetBucketInfo(ctx, p.Bucket)
 	if err != nil {
 		return nil, err
 	}
 
+	// settings object contains not only versioning status
+	settings, err := n.getBucketSettingsOrDefault(ctx, bktInfo)
+	if err != nil {
+		return nil, err
+	}
+	settings.VersioningStatus = p.Settings.VersioningStatus
+
+	return n.putBucketSettings(ctx, bktInfo, settings)
+}
+
+func (n *layer) putBucketSettings(ctx context.Context, bktInfo *api.BucketInfo, settings *BucketSettings) (*api.ObjectInfo, error) {
 	metadata := map[string]string{
-		attrSettingsVersioningEnabled: strconv.FormatBool(p.Settings.VersioningEnabled),
+		attrSettingsVersioning: settings.VersioningStatus,
 	}
+	quotaToMetadata(settings.Quota, metadata)
 
 	meta, err := n.putSystemObject(ctx, bktInfo, bktInfo.SettingsObjectName(), metadata, "")
 	if err != nil {
@@ -136,6 +191,9 @@ func (n *layer) PutBucketVersioning(ctx context.Context, p *PutVersioningParams)
 }
 
 func (n *layer) GetBucketVersioning(ctx context.Context, bucketName string) (*BucketSettings, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.GetBucketVersioning", tracing.AttrBucket.String(bucketName))
+	defer span.End()
+
 	bktInfo, err := n.GetBucketInfo(ctx, bucketName)
 	if err != nil {
 		return nil, err
@@ -145,6 +203,9 @@ func (n *layer) GetBucketVersioning(ctx context.Context, bucketName string) (*Bu
 }
 
 func (n *layer) ListObjectVersions(ctx context.Context, p *ListObjectVersionsParams) (*ListObjectVersionsInfo, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.ListObjectVersions", tracing.AttrBucket.String(p.Bucket))
+	defer span.End()
+
 	var (
 		versions   map[string]*objectVersions
 		allObjects = make([]*api.ObjectInfo, 0, p.MaxKeys)
@@ -166,41 +227,60 @@ func (n *layer) ListObjectVersions(ctx context.Context, p *ListObjectVersionsPar
 	}
 	sort.Strings(sortedNames)
 
+	// Versions of every object are listed from the newest one.
 	for _, name := range sortedNames {
-		allObjects = append(allObjects, versions[name].getFiltered()...)
-	}
-
-	for i, obj := range allObjects {
-		if obj.Name >= p.KeyMarker && obj.Version() >= p.VersionIDMarker {
-			allObjects = allObjects[i:]
-			break
+		filtered := versions[name].getFiltered()
+		for i := len(filtered) - 1; i >= 0; i-- {
+			allObjects = append(allObjects, filtered[i])
 		}
 	}
 
 	res.CommonPrefixes, allObjects = triageObjects(allObjects)
 
-	if len(allObjects) > p.MaxKeys {
-		res.IsTruncated = true
-		res.NextKeyMarker = allObjects[p.MaxKeys].Name
-		res.NextVersionIDMarker = allObjects[p.MaxKeys].Version()
-
-		allObjects = allObjects[:p.MaxKeys]
-		res.KeyMarker = allObjects[p.MaxKeys-1].Name
-		res.VersionIDMarker = allObjects[p.MaxKeys-1].Version()
-	}
-
 	objects := make([]*ObjectVersionInfo, len(allObjects))
 	for i, obj := range allObjects {
 		objects[i] = &ObjectVersionInfo{Object: obj}
-		if i == len(allObjects)-1 || allObjects[i+1].Name != obj.Name {
+		if i == 0 || allObjects[i-1].Name != obj.Name {
 			objects[i].IsLatest = true
 		}
 	}
 
+	res.KeyMarker = p.KeyMarker
+	res.VersionIDMarker = p.VersionIDMarker
+	if p.KeyMarker != "" {
+		objects = trimAfterVersionMarker(p.KeyMarker, p.VersionIDMarker, objects)
+	}
+
+	if len(objects) > p.MaxKeys {
+		res.IsTruncated = true
+		objects = objects[:p.MaxKeys]
+		res.NextKeyMarker = objects[p.MaxKeys-1].Object.Name
+		res.NextVersionIDMarker = objects[p.MaxKeys-1].Object.Version()
+	}
+
 	res.Version, res.DeleteMarker = triageVersions(objects)
 	return res, nil
 }
 
+// trimAfterVersionMarker returns versions which are listed after the specified key and version markers.
+func trimAfterVersionMarker(keyMarker, versionIDMarker string, objects []*ObjectVersionInfo) []*ObjectVersionInfo {
+	if versionIDMarker != "" {
+		for i, obj := range objects {
+			if obj.Object.Name == keyMarker && obj.Object.Version() == versionIDMarker {
+				return objects[i+1:]
+			}
+		}
+	}
+
+	for i, obj := range objects {
+		if obj.Object.Name > keyMarker {
+			return objects[i:]
+		}
+	}
+
+	return nil
+}
+
 func triageVersions(objVersions []*ObjectVersionInfo) ([]*ObjectVersionInfo, []*ObjectVersionInfo) {
 	if len(objVersions) == 0 {
 		return nil, nil
@@ -210,7 +290,7 @@ func triageVersions(objVersions []*ObjectVersionInfo) ([]*ObjectVersionInfo, []*
 	var resDelMarkVersions []*ObjectVersionInfo
 
 	for _, version := range objVersions {
-		if version.Object.Headers[versionsDeleteMarkAttr] == delMarkFullObject {
+		if version.Object.IsDeleteMarker {
 			resDelMarkVersions = append(resDelMarkVersions, version)
 		} else {
 			resVersion = append(resVersion, version)
@@ -222,7 +302,7 @@ func triageVersions(objVersions []*ObjectVersionInfo) ([]*ObjectVersionInfo, []*
 
 func less(ov1, ov2 *api.ObjectInfo) bool {
 	if ov1.CreationEpoch == ov2.CreationEpoch {
-		return ov1.Version() < ov2.Version()
+		return ov1.ID.String() < ov2.ID.String()
 	}
 	return ov1.CreationEpoch < ov2.CreationEpoch
 }
@@ -237,6 +317,9 @@ func contains(list []string, elem string) bool {
 }
 
 func (n *layer) getBucketSettings(ctx context.Context, bktInfo *api.BucketInfo) (*BucketSettings, error) {
+	ctx, span := tracing.StartSpan(ctx, "layer.getBucketSettings", tracing.AttrBucket.String(bktInfo.Name))
+	defer span.End()
+
 	objInfo, err := n.getSystemObject(ctx, bktInfo, bktInfo.SettingsObjectName())
 	if err != nil {
 		return nil, err
@@ -245,19 +328,43 @@ func (n *layer) getBucketSettings(ctx context.Context, bktInfo *api.BucketInfo)
 	return objectInfoToBucketSettings(objInfo), nil
 }
 
+// getBucketSettingsOrDefault returns default settings if they have never been set.
+func (n *layer) getBucketSettingsOrDefault(ctx context.Context, bktInfo *api.BucketInfo) (*BucketSettings, error) {
+	settings, err := n.getBucketSettings(ctx, bktInfo)
+	if errors.IsS3Error(err, errors.ErrNoSuchKey) {
+		return &BucketSettings{VersioningStatus: VersioningUnversioned}, nil
+	}
+
+	return settings, err
+}
+
 func objectInfoToBucketSettings(info *api.ObjectInfo) *BucketSettings {
-	res := &BucketSettings{}
+	res := &BucketSettings{
+		VersioningStatus: VersioningUnversioned,
+		Quota:            quotaFromHeaders(info.Headers),
+	}
 
-	enabled, ok := info.Headers[attrSettingsVersioningEnabled]
-	if ok {
+	switch status := info.Headers[attrSettingsVersioning]; status {
+	case VersioningEnabled, VersioningSuspended:
+		res.VersioningStatus = status
+	case "":
+		// settings put by older gateway versions
+		enabled, ok := info.Headers[attrSettingsVersioningEnabled]
+		if !ok {
+			break
+		}
 		if parsed, err := strconv.ParseBool(enabled); err == nil {
-			res.VersioningEnabled = parsed
+			if parsed {
+				res.VersioningStatus = VersioningEnabled
+			} else {
+				res.VersioningStatus = VersioningSuspended
+			}
 		}
 	}
 	return res
 }
 
-func (n *layer) checkVersionsExist(ctx context.Context, bkt *api.BucketInfo, obj *VersionedObject) (*object.ID, error) {
+func (n *layer) checkVersionsExist(ctx context.Context, bkt *api.BucketInfo, obj *VersionedObject) (*api.ObjectInfo, error) {
 	id := object.NewID()
 	if err := id.Parse(obj.VersionID); err != nil {
 		return nil, errors.GetAPIError(errors.ErrInvalidVersion)
@@ -271,5 +378,11 @@ func (n *layer) checkVersionsExist(ctx context.Context, bkt *api.BucketInfo, obj
 		return nil, errors.GetAPIError(errors.ErrInvalidVersion)
 	}
 
-	return id, nil
+	for _, version := range versions.objects {
+		if version.ID.String() == obj.VersionID {
+			return version, nil
+		}
+	}
+
+	return nil, errors.GetAPIError(errors.ErrInvalidVersion)
 }
@@ -3,210 +3,26 @@ package layer
 import (
 	"bytes"
 	"context"
-	"crypto/rand"
 	"crypto/sha256"
-	"fmt"
-	"io"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
-	"github.com/nspcc-dev/neofs-api-go/pkg/acl/eacl"
 	"github.com/nspcc-dev/neofs-api-go/pkg/client"
 	"github.com/nspcc-dev/neofs-api-go/pkg/container"
 	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
 	"github.com/nspcc-dev/neofs-api-go/pkg/object"
-	"github.com/nspcc-dev/neofs-api-go/pkg/owner"
-	"github.com/nspcc-dev/neofs-api-go/pkg/session"
 	"github.com/nspcc-dev/neofs-api-go/pkg/token"
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
 	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/memory"
 	"github.com/nspcc-dev/neofs-sdk-go/logger"
-	"github.com/nspcc-dev/neofs-sdk-go/pool"
 	"github.com/stretchr/testify/require"
 )
 
-type testPool struct {
-	objects      map[string]*object.Object
-	containers   map[string]*container.Container
-	currentEpoch uint64
-}
-
-func newTestPool() *testPool {
-	return &testPool{
-		objects:    make(map[string]*object.Object),
-		containers: make(map[string]*container.Container),
-	}
-}
-
-func (t *testPool) PutObject(ctx context.Context, params *client.PutObjectParams, option ...client.CallOption) (*object.ID, error) {
-	b := make([]byte, 32)
-	if _, err := io.ReadFull(rand.Reader, b); err != nil {
-		return nil, err
-	}
-
-	oid := object.NewID()
-	oid.SetSHA256(sha256.Sum256(b))
-
-	raw := object.NewRawFrom(params.Object())
-	raw.SetID(oid)
-	raw.SetCreationEpoch(t.currentEpoch)
-	t.currentEpoch++
-
-	if params.PayloadReader() != nil {
-		all, err := io.ReadAll(params.PayloadReader())
-		if err != nil {
-			return nil, err
-		}
-		raw.SetPayload(all)
-	}
-
-	addr := newAddress(raw.ContainerID(), raw.ID())
-	t.objects[addr.String()] = raw.Object()
-	return raw.ID(), nil
-}
-
-func (t *testPool) DeleteObject(ctx context.Context, params *client.DeleteObjectParams, option ...client.CallOption) error {
-	delete(t.objects, params.Address().String())
-	return nil
-}
-
-func (t *testPool) GetObject(ctx context.Context, params *client.GetObjectParams, option ...client.CallOption) (*object.Object, error) {
-	if obj, ok := t.objects[params.Address().String()]; ok {
-		if params.PayloadWriter() != nil {
-			_, err := params.PayloadWriter().Write(obj.Payload())
-			if err != nil {
-				return nil, err
-			}
-		}
-		return obj, nil
-	}
-
-	return nil, fmt.Errorf("object not found " + params.Address().String())
-}
-
-func (t *testPool) GetObjectHeader(ctx context.Context, params *client.ObjectHeaderParams, option ...client.CallOption) (*object.Object, error) {
-	p := new(client.GetObjectParams).WithAddress(params.Address())
-	return t.GetObject(ctx, p)
-}
-
-func (t *testPool) ObjectPayloadRangeData(ctx context.Context, params *client.RangeDataParams, option ...client.CallOption) ([]byte, error) {
-	panic("implement me")
-}
-
-func (t *testPool) ObjectPayloadRangeSHA256(ctx context.Context, params *client.RangeChecksumParams, option ...client.CallOption) ([][32]byte, error) {
-	panic("implement me")
-}
-
-func (t *testPool) ObjectPayloadRangeTZ(ctx context.Context, params *client.RangeChecksumParams, option ...client.CallOption) ([][64]byte, error) {
-	panic("implement me")
-}
-
-func (t *testPool) SearchObject(ctx context.Context, params *client.SearchObjectParams, option ...client.CallOption) ([]*object.ID, error) {
-	cidStr := params.ContainerID().String()
-
-	var res []*object.ID
-
-	if len(params.SearchFilters()) == 1 {
-		for k, v := range t.objects {
-			if strings.Contains(k, cidStr) {
-				res = append(res, v.ID())
-			}
-		}
-		return res, nil
-	}
-
-	filter := params.SearchFilters()[1]
-	if len(params.SearchFilters()) != 2 || filter.Operation() != object.MatchStringEqual ||
-		(filter.Header() != object.AttributeFileName && filter.Header() != objectSystemAttributeName) {
-		return nil, fmt.Errorf("usupported filters")
-	}
-
-	for k, v := range t.objects {
-		if strings.Contains(k, cidStr) && isMatched(v.Attributes(), filter) {
-			res = append(res, v.ID())
-		}
-	}
-
-	return res, nil
-}
-
-func isMatched(attributes []*object.Attribute, filter object.SearchFilter) bool {
-	for _, attr := range attributes {
-		if attr.Key() == filter.Header() && attr.Value() == filter.Value() {
-			return true
-		}
-	}
-
-	return false
-}
-
-func (t *testPool) PutContainer(ctx context.Context, container *container.Container, option ...client.CallOption) (*cid.ID, error) {
-	b := make([]byte, 32)
-	if _, err := io.ReadFull(rand.Reader, b); err != nil {
-		return nil, err
-	}
-
-	id := cid.New()
-	id.SetSHA256(sha256.Sum256(b))
-	t.containers[id.String()] = container
-
-	return id, nil
-}
-
-func (t *testPool) GetContainer(ctx context.Context, id *cid.ID, option ...client.CallOption) (*container.Container, error) {
-	for k, v := range t.containers {
-		if k == id.String() {
-			return v, nil
-		}
-	}
-
-	return nil, fmt.Errorf("container not found " + id.String())
-}
-
-func (t *testPool) ListContainers(ctx context.Context, id *owner.ID, option ...client.CallOption) ([]*cid.ID, error) {
-	var res []*cid.ID
-	for k := range t.containers {
-		cID := cid.New()
-		if err := cID.Parse(k); err != nil {
-			return nil, err
-		}
-		res = append(res, cID)
-	}
-
-	return res, nil
-}
-
-func (t *testPool) DeleteContainer(ctx context.Context, id *cid.ID, option ...client.CallOption) error {
-	delete(t.containers, id.String())
-	return nil
-}
-
-func (t *testPool) GetEACL(ctx context.Context, id *cid.ID, option ...client.CallOption) (*client.EACLWithSignature, error) {
-	panic("implement me")
-}
-
-func (t *testPool) SetEACL(ctx context.Context, table *eacl.Table, option ...client.CallOption) error {
-	panic("implement me")
-}
-
-func (t *testPool) AnnounceContainerUsedSpace(ctx context.Context, announcements []container.UsedSpaceAnnouncement, option ...client.CallOption) error {
-	panic("implement me")
-}
-
-func (t *testPool) Connection() (client.Client, *session.Token, error) {
-	panic("implement me")
-}
-
-func (t *testPool) OwnerID() *owner.ID {
-	return nil
-}
-
-func (t *testPool) WaitForContainerPresence(ctx context.Context, id *cid.ID, params *pool.ContainerPollingParams) error {
-	return nil
-}
-
 func (tc *testContext) putObject(content []byte) *api.ObjectInfo {
 	objInfo, err := tc.layer.PutObject(tc.ctx, &PutObjectParams{
 		Bucket: tc.bkt,
@@ -304,7 +120,7 @@ type testContext struct {
 	bkt      string
 	bktID    *cid.ID
 	obj      string
-	testPool *testPool
+	testPool *memory.Pool
 }
 
 func prepareContext(t *testing.T) *testContext {
@@ -319,7 +135,8 @@ func prepareContext(t *testing.T) *testContext {
 	})
 	l, err := logger.New(logger.WithTraceLevel("panic"))
 	require.NoError(t, err)
-	tp := newTestPool()
+	tp, err := memory.NewPool(key)
+	require.NoError(t, err)
 
 	bktName := "testbucket1"
 	cnr := container.New(container.WithAttribute(container.AttributeName, bktName))
@@ -328,12 +145,15 @@ func prepareContext(t *testing.T) *testContext {
 
 	return &testContext{
 		ctx: ctx,
-		layer: NewLayer(l, tp, &CacheConfig{
-			Size:                cache.DefaultObjectsCacheSize,
-			Lifetime:            cache.DefaultObjectsCacheLifetime,
-			ListObjectsLifetime: cache.DefaultObjectsListCacheLifetime,
-			ListObjectsSize:     cache.DefaultObjectsListCacheSize},
-		),
+		layer: NewLayer(l, tp, &Config{
+			Caches: &CacheConfig{
+				Size:                cache.DefaultObjectsCacheSize,
+				Lifetime:            cache.DefaultObjectsCacheLifetime,
+				ListObjectsLifetime: cache.DefaultObjectsListCacheLifetime,
+				ListObjectsSize:     cache.DefaultObjectsListCacheSize,
+			},
+			SessionFallback: SessionFallbackGateway,
+		}),
 		bkt:      bktName,
 		bktID:    bktID,
 		obj:      "obj1",
@@ -346,7 +166,7 @@ func TestSimpleVersioning(t *testing.T) {
 	tc := prepareContext(t)
 	_, err := tc.layer.PutBucketVersioning(tc.ctx, &PutVersioningParams{
 		Bucket:   tc.bkt,
-		Settings: &BucketSettings{VersioningEnabled: true},
+		Settings: &BucketSettings{VersioningStatus: VersioningEnabled},
 	})
 	require.NoError(t, err)
 
@@ -387,7 +207,7 @@ func TestVersioningDeleteObject(t *testing.T) {
 	tc := prepareContext(t)
 	_, err := tc.layer.PutBucketVersioning(tc.ctx, &PutVersioningParams{
 		Bucket:   tc.bkt,
-		Settings: &BucketSettings{VersioningEnabled: true},
+		Settings: &BucketSettings{VersioningStatus: VersioningEnabled},
 	})
 	require.NoError(t, err)
 
@@ -404,7 +224,7 @@ func TestVersioningDeleteSpecificObjectVersion(t *testing.T) {
 	tc := prepareContext(t)
 	_, err := tc.layer.PutBucketVersioning(tc.ctx, &PutVersioningParams{
 		Bucket:   tc.bkt,
-		Settings: &BucketSettings{VersioningEnabled: true},
+		Settings: &BucketSettings{VersioningStatus: VersioningEnabled},
 	})
 	require.NoError(t, err)
 
@@ -444,6 +264,123 @@ func TestNoVersioningDeleteObject(t *testing.T) {
 	tc.checkListObjects()
 }
 
+func TestVersioningSuspended(t *testing.T) {
+	tc := prepareContext(t)
+	_, err := tc.layer.PutBucketVersioning(tc.ctx, &PutVersioningParams{
+		Bucket:   tc.bkt,
+		Settings: &BucketSettings{VersioningStatus: VersioningEnabled},
+	})
+	require.NoError(t, err)
+
+	objV1Content := []byte("content obj1 v1")
+	objV1Info := tc.putObject(objV1Content)
+
+	_, err = tc.layer.PutBucketVersioning(tc.ctx, &PutVersioningParams{
+		Bucket:   tc.bkt,
+		Settings: &BucketSettings{VersioningStatus: VersioningSuspended},
+	})
+	require.NoError(t, err)
+
+	settings, err := tc.layer.GetBucketVersioning(tc.ctx, tc.bkt)
+	require.NoError(t, err)
+	require.True(t, settings.VersioningSuspended())
+
+	objV2Info := tc.putObject([]byte("content obj1 v2"))
+	objV3Content := []byte("content obj1 v3")
+	objV3Info := tc.putObject(objV3Content)
+
+	require.Equal(t, objV1Info.ID.String(), objV1Info.Version())
+	require.Equal(t, unversionedObjectVersionID, objV2Info.Version())
+	require.Equal(t, unversionedObjectVersionID, objV3Info.Version())
+
+	// null version is replaced, real version is kept
+	tc.getObject(tc.obj, objV2Info.ID.String(), true)
+	_, buffer1 := tc.getObject(tc.obj, objV1Info.Version(), false)
+	require.Equal(t, objV1Content, buffer1)
+	nullInfo, buffer3 := tc.getObject(tc.obj, unversionedObjectVersionID, false)
+	require.Equal(t, objV3Content, buffer3)
+	require.Equal(t, objV3Info.ID, nullInfo.ID)
+	require.Equal(t, unversionedObjectVersionID, nullInfo.Version())
+
+	// delete marker becomes the null version
+	toDelete := &VersionedObject{Name: tc.obj}
+	for _, err = range tc.layer.DeleteObjects(tc.ctx, tc.bkt, []*VersionedObject{toDelete}) {
+		require.NoError(t, err)
+	}
+	require.Equal(t, unversionedObjectVersionID, toDelete.DeleteMarkVersion)
+	tc.getObject(tc.obj, "", true)
+	tc.getObject(tc.obj, objV3Info.ID.String(), true)
+	_, buffer1 = tc.getObject(tc.obj, objV1Info.Version(), false)
+	require.Equal(t, objV1Content, buffer1)
+
+	versions := tc.listVersions()
+	require.Len(t, versions.Version, 1)
+	require.Equal(t, objV1Info.Version(), versions.Version[0].Object.Version())
+	require.Len(t, versions.DeleteMarker, 1)
+	require.True(t, versions.DeleteMarker[0].IsLatest)
+	require.Equal(t, unversionedObjectVersionID, versions.DeleteMarker[0].Object.Version())
+
+	// removing null delete marker reveals the real version
+	tc.deleteObject(tc.obj, unversionedObjectVersionID)
+	_, buffer1 = tc.getObject(tc.obj, "", false)
+	require.Equal(t, objV1Content, buffer1)
+}
+
+func TestVersioningDeleteMarkers(t *testing.T) {
+	tc := prepareContext(t)
+	_, err := tc.layer.PutBucketVersioning(tc.ctx, &PutVersioningParams{
+		Bucket:   tc.bkt,
+		Settings: &BucketSettings{VersioningStatus: VersioningEnabled},
+	})
+	require.NoError(t, err)
+
+	objV1Info := tc.putObject([]byte("content obj1 v1"))
+	objV2Info := tc.putObject([]byte("content obj1 v2"))
+
+	toDelete := &VersionedObject{Name: tc.obj}
+	require.Empty(t, tc.layer.DeleteObjects(tc.ctx, tc.bkt, []*VersionedObject{toDelete}))
+	require.NotEmpty(t, toDelete.DeleteMarkVersion)
+
+	markerInfo, err := tc.layer.GetObjectInfo(tc.ctx, &HeadObjectParams{
+		Bucket:    tc.bkt,
+		Object:    tc.obj,
+		VersionID: toDelete.DeleteMarkVersion,
+	})
+	require.NoError(t, err)
+	require.True(t, markerInfo.IsDeleteMarker)
+
+	versions := tc.listVersions()
+	require.Len(t, versions.DeleteMarker, 1)
+	require.True(t, versions.DeleteMarker[0].IsLatest)
+	require.Equal(t, toDelete.DeleteMarkVersion, versions.DeleteMarker[0].Object.Version())
+	require.Len(t, versions.Version, 2)
+	require.Equal(t, objV2Info.Version(), versions.Version[0].Object.Version())
+	require.Equal(t, objV1Info.Version(), versions.Version[1].Object.Version())
+	require.False(t, versions.Version[0].IsLatest)
+
+	page, err := tc.layer.ListObjectVersions(tc.ctx, &ListObjectVersionsParams{
+		Bucket:          tc.bkt,
+		MaxKeys:         1,
+		KeyMarker:       tc.obj,
+		VersionIDMarker: objV2Info.Version(),
+	})
+	require.NoError(t, err)
+	require.False(t, page.IsTruncated)
+	require.Len(t, page.Version, 1)
+	require.Equal(t, objV1Info.Version(), page.Version[0].Object.Version())
+
+	undelete := &VersionedObject{Name: tc.obj, VersionID: toDelete.DeleteMarkVersion}
+	require.Empty(t, tc.layer.DeleteObjects(tc.ctx, tc.bkt, []*VersionedObject{undelete}))
+	require.Equal(t, toDelete.DeleteMarkVersion, undelete.DeleteMarkVersion)
+
+	objInfo, _ := tc.getObject(tc.obj, "", false)
+	require.Equal(t, objV2Info.Version(), objInfo.Version())
+
+	versions = tc.listVersions()
+	require.Empty(t, versions.DeleteMarker)
+	require.True(t, versions.Version[0].IsLatest)
+}
+
 func TestGetLastVersion(t *testing.T) {
 	obj1 := getTestObjectInfo(1, getOID(1), "", "", "")
 	obj1V2 := getTestObjectInfo(1, getOID(2), "", "", "")
@@ -609,3 +546,47 @@ func getTestObjectInfo(epoch uint64, oid *object.ID, addAttr, delAttr, delMarkAt
 		Headers:       headers,
 	}
 }
+
+func TestLegacyNullVersion(t *testing.T) {
+	tc := prepareContext(t)
+
+	// object put by a gateway not aware of versioning
+	filename := object.NewAttribute()
+	filename.SetKey(object.AttributeFileName)
+	filename.SetValue(tc.obj)
+	created := object.NewAttribute()
+	created.SetKey(object.AttributeTimestamp)
+	created.SetValue(strconv.FormatInt(time.Now().Unix(), 10))
+
+	raw := object.NewRaw()
+	raw.SetOwnerID(tc.testPool.OwnerID())
+	raw.SetContainerID(tc.bktID)
+	raw.SetAttributes(filename, created)
+
+	legacyContent := []byte("legacy content")
+	ops := new(client.PutObjectParams).WithObject(raw.Object()).WithPayloadReader(bytes.NewReader(legacyContent))
+	legacyID, err := tc.testPool.PutObject(tc.ctx, ops)
+	require.NoError(t, err)
+
+	_, err = tc.layer.PutBucketVersioning(tc.ctx, &PutVersioningParams{
+		Bucket:   tc.bkt,
+		Settings: &BucketSettings{VersioningStatus: VersioningEnabled},
+	})
+	require.NoError(t, err)
+	objV2Info := tc.putObject([]byte("content obj1 v2"))
+	require.Equal(t, objV2Info.ID.String(), objV2Info.Version())
+
+	versions := tc.listVersions()
+	require.Len(t, versions.Version, 2)
+	for _, ver := range versions.Version {
+		if ver.Object.ID.Equal(legacyID) {
+			require.Equal(t, unversionedObjectVersionID, ver.Object.Version())
+		} else {
+			require.Equal(t, objV2Info.Version(), ver.Object.Version())
+		}
+	}
+
+	nullInfo, buffer := tc.getObject(tc.obj, unversionedObjectVersionID, false)
+	require.Equal(t, legacyContent, buffer)
+	require.Equal(t, unversionedObjectVersionID, nullInfo.Version())
+}
@@ -2,54 +2,98 @@ package api
 
 import (
 	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
 )
 
 type (
 	// MaxClients provides HTTP handler wrapper with client limit.
 	MaxClients interface {
 		Handle(http.HandlerFunc) http.HandlerFunc
+		// Update replaces the limits. Requests being served keep
+		// the slots of the limits they were started with.
+		Update(count int, timeout time.Duration)
+		// Stalled checks if requests aren't served: all the slots have
+		// been taken for several deadlines and none of them was released.
+		Stalled() bool
 	}
 
 	maxClients struct {
+		mu      sync.RWMutex
 		pool    chan struct{}
 		timeout time.Duration
+		// released is the time a slot was released last, in nanoseconds.
+		released int64
 	}
 )
 
-const defaultRequestDeadline = time.Second * 30
+const (
+	defaultRequestDeadline = time.Second * 30
+
+	// stalledFactor is a number of deadlines during which no slot is
+	// released when all of them are taken to consider the requests stalled.
+	stalledFactor = 3
+)
 
 // NewMaxClientsMiddleware returns MaxClients interface with handler wrapper based on
 // provided count and timeout limits.
 func NewMaxClientsMiddleware(count int, timeout time.Duration) MaxClients {
+	m := new(maxClients)
+	m.Update(count, timeout)
+
+	return m
+}
+
+// Update sets new count and timeout limits.
+func (m *maxClients) Update(count int, timeout time.Duration) {
 	if timeout <= 0 {
 		timeout = defaultRequestDeadline
 	}
 
-	return &maxClients{
-		pool:    make(chan struct{}, count),
-		timeout: timeout,
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.pool == nil || cap(m.pool) != count {
+		m.pool = make(chan struct{}, count)
+		atomic.StoreInt64(&m.released, time.Now().UnixNano())
 	}
+	m.timeout = timeout
+}
+
+func (m *maxClients) limits() (chan struct{}, time.Duration) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.pool, m.timeout
 }
 
 // Handler wraps HTTP handler function with logic limiting access to it.
 func (m *maxClients) Handle(f http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if m.pool == nil {
+		pool, timeout := m.limits()
+		if pool == nil {
 			f.ServeHTTP(w, r)
 			return
 		}
 
-		deadline := time.NewTimer(m.timeout)
+		deadline := time.NewTimer(timeout)
 		defer deadline.Stop()
 
+		start := time.Now()
 		select {
-		case m.pool <- struct{}{}:
-			defer func() { <-m.pool }()
+		case pool <- struct{}{}:
+			defer func() {
+				<-pool
+				atomic.StoreInt64(&m.released, time.Now().UnixNano())
+			}()
+			metrics.ObserveMaxClientsWait(time.Since(start))
 			f.ServeHTTP(w, r)
 		case <-deadline.C:
+			metrics.ObserveMaxClientsWait(time.Since(start))
 			// Send a http timeout message
 			WriteErrorResponse(w, GetReqInfo(r.Context()), errors.GetAPIError(errors.ErrOperationTimedOut))
 			return
@@ -58,3 +102,14 @@ func (m *maxClients) Handle(f http.HandlerFunc) http.HandlerFunc {
 		}
 	}
 }
+
+// Stalled implements MaxClients interface.
+func (m *maxClients) Stalled() bool {
+	pool, timeout := m.limits()
+	if cap(pool) == 0 || len(pool) < cap(pool) {
+		return false
+	}
+
+	released := time.Unix(0, atomic.LoadInt64(&m.released))
+	return time.Since(released) > stalledFactor*timeout
+}
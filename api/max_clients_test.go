@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMaxClientsStalled(t *testing.T) {
+	m := NewMaxClientsMiddleware(1, time.Second).(*maxClients)
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	h := m.Handle(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		<-release
+	})
+
+	done := make(chan struct{})
+	go func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/bucket/object", nil))
+		close(done)
+	}()
+	<-started
+
+	// the slot is taken, but the request isn't stuck yet
+	require.False(t, m.Stalled())
+
+	atomic.StoreInt64(&m.released, time.Now().Add(-stalledFactor*2*time.Second).UnixNano())
+	require.True(t, m.Stalled())
+
+	close(release)
+	<-done
+	require.False(t, m.Stalled())
+
+	// all the slots are free
+	atomic.StoreInt64(&m.released, time.Now().Add(-stalledFactor*2*time.Second).UnixNano())
+	require.False(t, m.Stalled())
+}
@@ -1,13 +1,16 @@
 package metrics
 
 import (
+	"context"
 	"io"
 	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/prometheus/client_golang/prometheus"
 )
 
@@ -47,6 +50,15 @@ type (
 		statusCode int
 		startTime  time.Time
 	}
+
+	// requestBucket is the bucket of the request, the request is counted by
+	// per-bucket metrics only if the bucket is resolved.
+	requestBucket struct {
+		name     string
+		resolved uint32
+	}
+
+	requestBucketKey struct{}
 )
 
 const systemPath = "/system"
@@ -61,8 +73,83 @@ var (
 		},
 		[]string{"api"},
 	)
+	httpResponses = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "neofs_s3_responses_total",
+			Help: "Number of responses sent by current NeoFS S3 Gate instance by status code",
+		},
+		[]string{"api", "code"},
+	)
+	uploadsInFlight = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "neofs_s3_uploads_in_flight",
+			Help: "Number of object uploads being served by current NeoFS S3 Gate instance",
+		},
+	)
+
+	// perBucket enables per-bucket metrics, it's set atomically.
+	perBucket     uint32
+	bucketTraffic = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "neofs_s3_bucket_requests_total",
+			Help: "Number of requests to the bucket by status code",
+		},
+		[]string{"bucket", "api", "code"},
+	)
+	bucketBytes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "neofs_s3_bucket_bytes_total",
+			Help: "Number of bytes received (in) and sent (out) for the bucket",
+		},
+		[]string{"bucket", "direction"},
+	)
+	maxClientsWait = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "neofs_s3_max_clients_wait_seconds",
+			Help:    "Time requests wait for a free slot of max clients limit",
+			Buckets: []float64{.001, .01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+		},
+	)
 )
 
+// uploadAPIs are the APIs which upload object payload.
+var uploadAPIs = map[string]struct{}{
+	"putobject":      {},
+	"putobjectpart":  {},
+	"copyobject":     {},
+	"copyobjectpart": {},
+	"postobject":     {},
+}
+
+// EnableBucketMetrics turns per-bucket request and traffic counters on or off.
+// They are off by default as the number of series grows with the number of buckets.
+func EnableBucketMetrics(enabled bool) {
+	var v uint32
+	if enabled {
+		v = 1
+	}
+	atomic.StoreUint32(&perBucket, v)
+}
+
+func bucketMetricsEnabled() bool {
+	return atomic.LoadUint32(&perBucket) == 1
+}
+
+// BucketResolved marks the bucket of the authenticated request as existing,
+// so the request is counted by per-bucket metrics. Other buckets of the
+// request (e.g. the source of the copy) are ignored.
+func BucketResolved(ctx context.Context, name string) {
+	if bucket, ok := ctx.Value(requestBucketKey{}).(*requestBucket); ok && bucket.name == name {
+		atomic.StoreUint32(&bucket.resolved, 1)
+	}
+}
+
+// ObserveMaxClientsWait records the time the request waited for a free slot
+// of max clients limit.
+func ObserveMaxClientsWait(d time.Duration) {
+	maxClientsWait.Observe(d.Seconds())
+}
+
 // Collects HTTP metrics for NeoFS S3 Gate in Prometheus specific format
 // and sends to given channel.
 func collectHTTPMetrics(ch chan<- prometheus.Metric) {
@@ -109,6 +196,19 @@ func APIStats(api string, f http.HandlerFunc) http.HandlerFunc {
 		httpStatsMetric.currentS3Requests.Inc(api)
 		defer httpStatsMetric.currentS3Requests.Dec(api)
 
+		if _, ok := uploadAPIs[api]; ok {
+			uploadsInFlight.Inc()
+			defer uploadsInFlight.Dec()
+		}
+
+		// per-bucket metrics aren't recorded for the buckets which aren't
+		// resolved, otherwise any client can add series with random names
+		var bucket *requestBucket
+		if name := mux.Vars(r)["bucket"]; name != "" && bucketMetricsEnabled() {
+			bucket = &requestBucket{name: name}
+			r = r.WithContext(context.WithValue(r.Context(), requestBucketKey{}, bucket))
+		}
+
 		in := &readCounter{ReadCloser: r.Body}
 		out := &writeCounter{ResponseWriter: w}
 
@@ -130,7 +230,22 @@ func APIStats(api string, f http.HandlerFunc) http.HandlerFunc {
 
 		atomic.AddUint64(&httpStatsMetric.totalInputBytes, in.countBytes)
 		atomic.AddUint64(&httpStatsMetric.totalOutputBytes, out.countBytes)
+
+		if bucket != nil && atomic.LoadUint32(&bucket.resolved) == 1 {
+			bucketTraffic.WithLabelValues(bucket.name, api, statusCode(statsWriter)).Inc()
+			bucketBytes.WithLabelValues(bucket.name, "in").Add(float64(in.countBytes))
+			bucketBytes.WithLabelValues(bucket.name, "out").Add(float64(out.countBytes))
+		}
+	}
+}
+
+// statusCode returns the code sent to the client as a label value.
+// Handlers which don't call WriteHeader explicitly respond with 200.
+func statusCode(w *responseWrapper) string {
+	if w.statusCode == 0 {
+		return strconv.Itoa(http.StatusOK)
 	}
+	return strconv.Itoa(w.statusCode)
 }
 
 // Inc increments the api stats counter.
@@ -183,6 +298,7 @@ func (st *HTTPStats) updateStats(api string, w http.ResponseWriter, r *http.Requ
 
 	if res, ok := w.(*responseWrapper); ok {
 		code = res.statusCode
+		httpResponses.WithLabelValues(api, statusCode(res)).Inc()
 	}
 
 	// A successful request has a 2xx response code
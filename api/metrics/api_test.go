@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIStats(t *testing.T) {
+	// the handlers resolve all the buckets except "missing" one
+	resolve := func(r *http.Request) {
+		if bucket := mux.Vars(r)["bucket"]; bucket != "missing" {
+			BucketResolved(r.Context(), "other")
+			BucketResolved(r.Context(), bucket)
+		}
+	}
+
+	r := mux.NewRouter()
+	r.Methods(http.MethodPut).Path("/{bucket}/{object:.+}").HandlerFunc(
+		APIStats("putobject", func(w http.ResponseWriter, r *http.Request) {
+			resolve(r)
+			require.Equal(t, 1.0, testutil.ToFloat64(uploadsInFlight))
+			_, err := ioutil.ReadAll(r.Body)
+			require.NoError(t, err)
+			w.WriteHeader(http.StatusForbidden)
+		}))
+	r.Methods(http.MethodGet).Path("/{bucket}/{object:.+}").HandlerFunc(
+		APIStats("getobject", func(w http.ResponseWriter, r *http.Request) {
+			resolve(r)
+			_, _ = w.Write([]byte("payload"))
+		}))
+
+	put := func(bucket string) {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/"+bucket+"/obj", strings.NewReader("data")))
+	}
+
+	put("private")
+	require.Equal(t, 1.0, testutil.ToFloat64(httpResponses.WithLabelValues("putobject", "403")))
+	require.Equal(t, 0.0, testutil.ToFloat64(uploadsInFlight))
+	require.Equal(t, 0, testutil.CollectAndCount(bucketTraffic))
+
+	EnableBucketMetrics(true)
+	t.Cleanup(func() { EnableBucketMetrics(false) })
+
+	put("public")
+	put("missing")
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/public/obj", nil))
+
+	require.Equal(t, 1.0, testutil.ToFloat64(bucketTraffic.WithLabelValues("public", "putobject", "403")))
+	require.Equal(t, 1.0, testutil.ToFloat64(bucketTraffic.WithLabelValues("public", "getobject", "200")))
+	require.Equal(t, 4.0, testutil.ToFloat64(bucketBytes.WithLabelValues("public", "in")))
+	require.Equal(t, 7.0, testutil.ToFloat64(bucketBytes.WithLabelValues("public", "out")))
+	require.Equal(t, 2, testutil.CollectAndCount(bucketTraffic))
+}
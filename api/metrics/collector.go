@@ -31,6 +31,14 @@ func init() {
 	prometheus.MustRegister(versionInfo)
 	prometheus.MustRegister(statsMetrics)
 	prometheus.MustRegister(httpRequestsDuration)
+	prometheus.MustRegister(httpResponses)
+	prometheus.MustRegister(uploadsInFlight)
+	prometheus.MustRegister(bucketTraffic)
+	prometheus.MustRegister(bucketBytes)
+	prometheus.MustRegister(neofsRequestDuration)
+	prometheus.MustRegister(nodeHealth)
+	prometheus.MustRegister(retiredKeyBoxes)
+	prometheus.MustRegister(maxClientsWait)
 }
 
 func collectNetworkMetrics(ch chan<- prometheus.Metric) {
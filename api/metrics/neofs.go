@@ -0,0 +1,239 @@
+package metrics
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/bluele/gcache"
+	"github.com/nspcc-dev/neofs-api-go/pkg/acl/eacl"
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
+	"github.com/nspcc-dev/neofs-api-go/pkg/container"
+	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/nspcc-dev/neofs-api-go/pkg/netmap"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"github.com/nspcc-dev/neofs-api-go/pkg/owner"
+	"github.com/nspcc-dev/neofs-api-go/pkg/session"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/neofs"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// measuredPool is a pool.Pool which observes duration of every NeoFS call.
+type measuredPool struct {
+	pool pool.Pool
+}
+
+var (
+	neofsRequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "neofs_s3_neofs_request_seconds",
+			Help:    "Time taken by requests to NeoFS made by current NeoFS S3 Gate instance",
+			Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+		},
+		[]string{"method", "status"},
+	)
+	nodeHealth = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "neofs_s3_pool_node_healthy",
+			Help: "Health of NeoFS nodes of the connection pool, 1 if the node is healthy",
+		},
+		[]string{"node"},
+	)
+	retiredKeyBoxes = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "neofs_s3_retired_key_access_boxes",
+			Help: "Number of recently used access boxes decrypted with the retired gateway key",
+		},
+		[]string{"key"},
+	)
+
+	retiredKeyBoxesMu sync.Mutex
+	// retiredKeyBoxesSeen maps access key IDs of the counted boxes to their
+	// keys, the boxes evicted from it aren't counted anymore.
+	retiredKeyBoxesSeen = gcache.New(retiredKeyBoxesLimit).LRU().EvictedFunc(unobserveRetiredKeyBox).Build()
+)
+
+// retiredKeyBoxesLimit is the number of the most recently used boxes counted
+// by the retired key metric.
+const retiredKeyBoxesLimit = 10000
+
+// ReplacedBoxKey is the key label of the boxes which can't be decrypted with
+// any gateway key, so their replacements are used.
+const ReplacedBoxKey = "replaced"
+
+// ObserveRetiredKeyBox counts the access box decrypted with the retired key,
+// every box is counted once. Only the most recently used boxes are counted.
+func ObserveRetiredKeyBox(key, accessKeyID string) {
+	retiredKeyBoxesMu.Lock()
+	defer retiredKeyBoxesMu.Unlock()
+
+	if _, err := retiredKeyBoxesSeen.Get(accessKeyID); err == nil {
+		return
+	}
+	retiredKeyBoxes.WithLabelValues(key).Inc()
+	_ = retiredKeyBoxesSeen.Set(accessKeyID, key)
+}
+
+func unobserveRetiredKeyBox(_, key interface{}) {
+	retiredKeyBoxes.WithLabelValues(key.(string)).Dec()
+}
+
+// SetNodesHealth replaces the health state of NeoFS nodes.
+func SetNodesHealth(healthy map[string]bool) {
+	nodeHealth.Reset()
+	for address, ok := range healthy {
+		var v float64
+		if ok {
+			v = 1
+		}
+		nodeHealth.WithLabelValues(address).Set(v)
+	}
+}
+
+// WrapPool returns pool.Pool which observes latency of calls to p.
+func WrapPool(p pool.Pool) pool.Pool {
+	return &measuredPool{pool: p}
+}
+
+func observe(method string, start time.Time, err error) {
+	status := "ok"
+	if err != nil {
+		status = "error"
+	}
+	neofsRequestDuration.WithLabelValues(method, status).Observe(time.Since(start).Seconds())
+}
+
+func (m *measuredPool) PutObject(ctx context.Context, params *client.PutObjectParams, opts ...client.CallOption) (*object.ID, error) {
+	start := time.Now()
+	id, err := m.pool.PutObject(ctx, params, opts...)
+	observe("put_object", start, err)
+	return id, err
+}
+
+func (m *measuredPool) DeleteObject(ctx context.Context, params *client.DeleteObjectParams, opts ...client.CallOption) error {
+	start := time.Now()
+	err := m.pool.DeleteObject(ctx, params, opts...)
+	observe("delete_object", start, err)
+	return err
+}
+
+func (m *measuredPool) GetObject(ctx context.Context, params *client.GetObjectParams, opts ...client.CallOption) (*object.Object, error) {
+	start := time.Now()
+	obj, err := m.pool.GetObject(ctx, params, opts...)
+	observe("get_object", start, err)
+	return obj, err
+}
+
+func (m *measuredPool) GetObjectHeader(ctx context.Context, params *client.ObjectHeaderParams, opts ...client.CallOption) (*object.Object, error) {
+	start := time.Now()
+	obj, err := m.pool.GetObjectHeader(ctx, params, opts...)
+	observe("head_object", start, err)
+	return obj, err
+}
+
+func (m *measuredPool) ObjectPayloadRangeData(ctx context.Context, params *client.RangeDataParams, opts ...client.CallOption) ([]byte, error) {
+	start := time.Now()
+	data, err := m.pool.ObjectPayloadRangeData(ctx, params, opts...)
+	observe("range_object", start, err)
+	return data, err
+}
+
+func (m *measuredPool) ObjectPayloadRangeSHA256(ctx context.Context, params *client.RangeChecksumParams, opts ...client.CallOption) ([][sha256.Size]byte, error) {
+	start := time.Now()
+	res, err := m.pool.ObjectPayloadRangeSHA256(ctx, params, opts...)
+	observe("range_hash_object", start, err)
+	return res, err
+}
+
+func (m *measuredPool) ObjectPayloadRangeTZ(ctx context.Context, params *client.RangeChecksumParams, opts ...client.CallOption) ([][client.TZSize]byte, error) {
+	start := time.Now()
+	res, err := m.pool.ObjectPayloadRangeTZ(ctx, params, opts...)
+	observe("range_hash_object", start, err)
+	return res, err
+}
+
+func (m *measuredPool) SearchObject(ctx context.Context, params *client.SearchObjectParams, opts ...client.CallOption) ([]*object.ID, error) {
+	start := time.Now()
+	ids, err := m.pool.SearchObject(ctx, params, opts...)
+	observe("search_object", start, err)
+	return ids, err
+}
+
+func (m *measuredPool) PutContainer(ctx context.Context, cnr *container.Container, opts ...client.CallOption) (*cid.ID, error) {
+	start := time.Now()
+	id, err := m.pool.PutContainer(ctx, cnr, opts...)
+	observe("put_container", start, err)
+	return id, err
+}
+
+func (m *measuredPool) GetContainer(ctx context.Context, id *cid.ID, opts ...client.CallOption) (*container.Container, error) {
+	start := time.Now()
+	cnr, err := m.pool.GetContainer(ctx, id, opts...)
+	observe("get_container", start, err)
+	return cnr, err
+}
+
+func (m *measuredPool) ListContainers(ctx context.Context, ownerID *owner.ID, opts ...client.CallOption) ([]*cid.ID, error) {
+	start := time.Now()
+	ids, err := m.pool.ListContainers(ctx, ownerID, opts...)
+	observe("list_containers", start, err)
+	return ids, err
+}
+
+func (m *measuredPool) DeleteContainer(ctx context.Context, id *cid.ID, opts ...client.CallOption) error {
+	start := time.Now()
+	err := m.pool.DeleteContainer(ctx, id, opts...)
+	observe("delete_container", start, err)
+	return err
+}
+
+func (m *measuredPool) GetEACL(ctx context.Context, id *cid.ID, opts ...client.CallOption) (*client.EACLWithSignature, error) {
+	start := time.Now()
+	table, err := m.pool.GetEACL(ctx, id, opts...)
+	observe("get_eacl", start, err)
+	return table, err
+}
+
+// GetEACLTable requests the eACL table with the wrapped pool.
+func (m *measuredPool) GetEACLTable(ctx context.Context, id *cid.ID, opts ...client.CallOption) (*eacl.Table, error) {
+	start := time.Now()
+	table, err := neofs.GetEACL(ctx, m.pool, id, opts...)
+	observe("get_eacl", start, err)
+	return table, err
+}
+
+func (m *measuredPool) SetEACL(ctx context.Context, table *eacl.Table, opts ...client.CallOption) error {
+	start := time.Now()
+	err := m.pool.SetEACL(ctx, table, opts...)
+	observe("set_eacl", start, err)
+	return err
+}
+
+func (m *measuredPool) AnnounceContainerUsedSpace(ctx context.Context, announce []container.UsedSpaceAnnouncement, opts ...client.CallOption) error {
+	start := time.Now()
+	err := m.pool.AnnounceContainerUsedSpace(ctx, announce, opts...)
+	observe("announce_used_space", start, err)
+	return err
+}
+
+// NetworkInfo requests the network info with the wrapped pool.
+func (m *measuredPool) NetworkInfo(ctx context.Context, opts ...client.CallOption) (*netmap.NetworkInfo, error) {
+	start := time.Now()
+	info, err := neofs.NetworkInfo(ctx, m.pool, opts...)
+	observe("network_info", start, err)
+	return info, err
+}
+
+func (m *measuredPool) Connection() (client.Client, *session.Token, error) {
+	return m.pool.Connection()
+}
+
+func (m *measuredPool) OwnerID() *owner.ID {
+	return m.pool.OwnerID()
+}
+
+func (m *measuredPool) WaitForContainerPresence(ctx context.Context, id *cid.ID, params *pool.ContainerPollingParams) error {
+	return m.pool.WaitForContainerPresence(ctx, id, params)
+}
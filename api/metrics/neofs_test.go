@@ -0,0 +1,22 @@
+package metrics
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveRetiredKeyBox(t *testing.T) {
+	ObserveRetiredKeyBox("old", "box")
+	ObserveRetiredKeyBox("old", "box")
+	require.Equal(t, 1.0, testutil.ToFloat64(retiredKeyBoxes.WithLabelValues("old")))
+
+	for i := 0; i < retiredKeyBoxesLimit+10; i++ {
+		ObserveRetiredKeyBox(ReplacedBoxKey, strconv.Itoa(i))
+	}
+	require.Equal(t, 0.0, testutil.ToFloat64(retiredKeyBoxes.WithLabelValues("old")))
+	require.Equal(t, float64(retiredKeyBoxesLimit), testutil.ToFloat64(retiredKeyBoxes.WithLabelValues(ReplacedBoxKey)))
+	require.Equal(t, retiredKeyBoxesLimit, retiredKeyBoxesSeen.Len(false))
+}
@@ -0,0 +1,406 @@
+package api
+
+import (
+	"container/list"
+	"context"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/errors"
+)
+
+type (
+	// RateLimiter provides HTTP middleware limiting requests of every access
+	// key or, for anonymous requests, of every source IP.
+	RateLimiter interface {
+		Middleware(http.Handler) http.Handler
+		// SourceMiddleware limits requests of every source IP before
+		// authentication, so requests failing it are limited too.
+		SourceMiddleware(http.Handler) http.Handler
+		// Update replaces the limits. Requests being served keep
+		// the limits they were started with.
+		Update(cfg *RateLimits)
+	}
+
+	// RateLimits contains default limits and per-key overrides.
+	RateLimits struct {
+		Default Limits
+		// SourceIP are the limits of every source IP applied before
+		// authentication, overrides of the IP replace them too.
+		SourceIP Limits
+		// Overrides are keyed by access key ID or source IP.
+		Overrides map[string]Limits
+		// TrustedProxies can set the source IP in forwarding headers.
+		TrustedProxies TrustedProxies
+	}
+
+	// Limits of the requests of the single client, zero means no limit.
+	Limits struct {
+		// RequestsPerSecond and Burst limit the rate of requests.
+		RequestsPerSecond float64
+		Burst             int
+		// Concurrency limits the number of requests being served at once.
+		Concurrency int
+		// Bandwidth limits the sum of request and response payload bytes per second.
+		Bandwidth int64
+	}
+
+	rateLimiter struct {
+		mu      sync.Mutex
+		cfg     RateLimits
+		clients map[string]*list.Element
+		// lru keeps clients in the order of their activity, the least
+		// recently seen ones are at the back.
+		lru        *list.List
+		maxClients int
+	}
+
+	// clientKeyFunc returns the key of the request client and its limits.
+	clientKeyFunc func(r *http.Request, cfg *RateLimits) (string, Limits)
+
+	clientLimiter struct {
+		key       string
+		limits    Limits
+		requests  *tokenBucket
+		bandwidth *tokenBucket
+
+		// inFlight and lastSeen are guarded by rateLimiter.mu.
+		inFlight int
+		lastSeen time.Time
+	}
+
+	// tokenBucket is a classic token bucket, tokens may go below zero
+	// to make the caller wait for them.
+	tokenBucket struct {
+		mu     sync.Mutex
+		rate   float64
+		burst  float64
+		tokens float64
+		last   time.Time
+	}
+
+	throttledReader struct {
+		io.ReadCloser
+		ctx    context.Context
+		bucket *tokenBucket
+	}
+
+	throttledWriter struct {
+		http.ResponseWriter
+		ctx    context.Context
+		bucket *tokenBucket
+	}
+)
+
+const (
+	// clientIdleTimeout is the time after which the state of inactive client is dropped.
+	clientIdleTimeout = 10 * time.Minute
+	// maxRateLimitedClients is the max number of clients tracked at once.
+	maxRateLimitedClients = 100000
+	// sourceKeyPrefix separates source IPs limited before authentication
+	// from the clients limited after it.
+	sourceKeyPrefix = "source:"
+	// maxThrottleChunk is the max number of bytes read or written in one go under bandwidth limit.
+	maxThrottleChunk = 32 * 1024
+)
+
+// NewRateLimiter returns RateLimiter with the given limits.
+func NewRateLimiter(cfg *RateLimits) RateLimiter {
+	l := &rateLimiter{maxClients: maxRateLimitedClients}
+	l.Update(cfg)
+
+	return l
+}
+
+// Update sets new limits. The state of the clients which limits aren't
+// changed is kept.
+func (l *rateLimiter) Update(cfg *RateLimits) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.cfg = *cfg
+	if l.clients == nil {
+		l.clients = make(map[string]*list.Element)
+		l.lru = list.New()
+		return
+	}
+
+	for e := l.lru.Front(); e != nil; {
+		next := e.Next()
+		if client := e.Value.(*clientLimiter); client.limits != l.cfg.clientLimits(client.key) {
+			l.remove(e)
+		}
+		e = next
+	}
+}
+
+// limitsFor returns the limits of the client with the given key.
+func (c *RateLimits) limitsFor(key string) Limits {
+	if lim, ok := c.Overrides[key]; ok {
+		return lim
+	}
+	return c.Default
+}
+
+// sourceLimits returns the limits of the source IP applied before
+// authentication.
+func (c *RateLimits) sourceLimits(ip string) Limits {
+	if lim, ok := c.Overrides[ip]; ok {
+		return lim
+	}
+	return c.SourceIP
+}
+
+// clientLimits returns the limits of the tracked client with the given key.
+func (c *RateLimits) clientLimits(key string) Limits {
+	if strings.HasPrefix(key, sourceKeyPrefix) {
+		return c.sourceLimits(strings.TrimPrefix(key, sourceKeyPrefix))
+	}
+	return c.limitsFor(key)
+}
+
+func (l Limits) unlimited() bool {
+	return l.RequestsPerSecond <= 0 && l.Concurrency <= 0 && l.Bandwidth <= 0
+}
+
+// Middleware rejects requests over the limits with SlowDown error and
+// throttles payload of accepted ones.
+func (l *rateLimiter) Middleware(h http.Handler) http.Handler {
+	return l.handler(h, func(r *http.Request, cfg *RateLimits) (string, Limits) {
+		key := GetReqInfo(r.Context()).AccessKeyID
+		if key == "" {
+			key = cfg.TrustedProxies.ClientIP(r)
+		}
+		return key, cfg.limitsFor(key)
+	})
+}
+
+// SourceMiddleware is the same as Middleware but uses SourceIP limits of
+// the client IP.
+func (l *rateLimiter) SourceMiddleware(h http.Handler) http.Handler {
+	return l.handler(h, func(r *http.Request, cfg *RateLimits) (string, Limits) {
+		ip := cfg.TrustedProxies.ClientIP(r)
+		return sourceKeyPrefix + ip, cfg.sourceLimits(ip)
+	})
+}
+
+func (l *rateLimiter) handler(h http.Handler, keyFunc clientKeyFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqInfo := GetReqInfo(r.Context())
+
+		client, retryAfter := l.acquire(r, keyFunc)
+		if client == nil {
+			w.Header().Set(hdrRetryAfter, strconv.Itoa(retryAfter))
+			WriteErrorResponse(w, reqInfo, errors.GetAPIError(errors.ErrSlowDown))
+			return
+		}
+		defer l.release(client)
+
+		if client.bandwidth != nil {
+			r.Body = &throttledReader{ReadCloser: r.Body, ctx: r.Context(), bucket: client.bandwidth}
+			w = &throttledWriter{ResponseWriter: w, ctx: r.Context(), bucket: client.bandwidth}
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// acquire takes the request slot of the client. If the client is over the
+// limits, nil and the number of seconds to retry after are returned.
+func (l *rateLimiter) acquire(r *http.Request, keyFunc clientKeyFunc) (*clientLimiter, int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.sweep(now)
+
+	key, limits := keyFunc(r, &l.cfg)
+
+	var client *clientLimiter
+	if e, ok := l.clients[key]; ok {
+		client = e.Value.(*clientLimiter)
+		l.lru.MoveToFront(e)
+	} else {
+		if limits.unlimited() {
+			// there is nothing to track
+			return &clientLimiter{limits: limits}, 0
+		}
+		if len(l.clients) >= l.maxClients && !l.evict() {
+			// all the tracked clients are busy
+			return nil, 1
+		}
+		client = newClientLimiter(key, limits, now)
+		l.clients[key] = l.lru.PushFront(client)
+	}
+	client.lastSeen = now
+
+	if client.limits.Concurrency > 0 && client.inFlight >= client.limits.Concurrency {
+		return nil, 1
+	}
+
+	if client.requests != nil {
+		if wait := client.requests.take(now, 1, false); wait > 0 {
+			return nil, int(math.Ceil(wait.Seconds()))
+		}
+	}
+
+	client.inFlight++
+
+	return client, 0
+}
+
+func (l *rateLimiter) release(client *clientLimiter) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	client.inFlight--
+	client.lastSeen = time.Now()
+	// the client may have been dropped by Update if its limits are changed
+	if e, ok := l.clients[client.key]; ok && e.Value == client {
+		l.lru.MoveToFront(e)
+	}
+}
+
+// sweep drops clients idle for clientIdleTimeout.
+func (l *rateLimiter) sweep(now time.Time) {
+	for e := l.lru.Back(); e != nil; {
+		client := e.Value.(*clientLimiter)
+		if now.Sub(client.lastSeen) <= clientIdleTimeout {
+			return
+		}
+
+		prev := e.Prev()
+		if client.inFlight == 0 {
+			l.remove(e)
+		}
+		e = prev
+	}
+}
+
+// evict drops the least recently seen client without requests in flight.
+func (l *rateLimiter) evict() bool {
+	for e := l.lru.Back(); e != nil; e = e.Prev() {
+		if e.Value.(*clientLimiter).inFlight == 0 {
+			l.remove(e)
+			return true
+		}
+	}
+	return false
+}
+
+func (l *rateLimiter) remove(e *list.Element) {
+	delete(l.clients, e.Value.(*clientLimiter).key)
+	l.lru.Remove(e)
+}
+
+func newClientLimiter(key string, limits Limits, now time.Time) *clientLimiter {
+	client := &clientLimiter{key: key, limits: limits}
+
+	if limits.RequestsPerSecond > 0 {
+		burst := limits.Burst
+		if burst <= 0 {
+			burst = int(math.Max(1, math.Ceil(limits.RequestsPerSecond)))
+		}
+		client.requests = newTokenBucket(limits.RequestsPerSecond, float64(burst), now)
+	}
+
+	if limits.Bandwidth > 0 {
+		client.bandwidth = newTokenBucket(float64(limits.Bandwidth), float64(limits.Bandwidth), now)
+	}
+
+	return client
+}
+
+func newTokenBucket(rate, burst float64, now time.Time) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, last: now}
+}
+
+// take takes n tokens and returns the time to wait for them. If there are
+// not enough tokens and force is false, nothing is taken.
+func (b *tokenBucket) take(now time.Time, n float64, force bool) time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	if b.tokens >= n {
+		b.tokens -= n
+		return 0
+	}
+
+	wait := time.Duration((n - b.tokens) / b.rate * float64(time.Second))
+	if force {
+		b.tokens -= n
+	}
+
+	return wait
+}
+
+// wait takes n tokens waiting for them if necessary.
+func (b *tokenBucket) wait(ctx context.Context, n int) error {
+	d := b.take(time.Now(), float64(n), true)
+	if d <= 0 {
+		return nil
+	}
+
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (r *throttledReader) Read(p []byte) (int, error) {
+	if len(p) > maxThrottleChunk {
+		p = p[:maxThrottleChunk]
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	if n > 0 {
+		if werr := r.bucket.wait(r.ctx, n); werr != nil && err == nil {
+			err = werr
+		}
+	}
+
+	return n, err
+}
+
+func (w *throttledWriter) Write(p []byte) (int, error) {
+	var written int
+	for len(p) > 0 {
+		chunk := p
+		if len(chunk) > maxThrottleChunk {
+			chunk = chunk[:maxThrottleChunk]
+		}
+
+		if err := w.bucket.wait(w.ctx, len(chunk)); err != nil {
+			return written, err
+		}
+
+		n, err := w.ResponseWriter.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+
+	return written, nil
+}
+
+// Flush calls the underlying Flush.
+func (w *throttledWriter) Flush() {
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter(t *testing.T) {
+	const (
+		limitedKey   = "limited"
+		unlimitedKey = "unlimited"
+	)
+
+	l := NewRateLimiter(&RateLimits{
+		Default: Limits{Concurrency: 1},
+		Overrides: map[string]Limits{
+			limitedKey:   {RequestsPerSecond: 0.1, Burst: 2},
+			unlimitedKey: {},
+		},
+	})
+
+	release := make(chan struct{})
+	started := make(chan struct{})
+	h := l.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			started <- struct{}{}
+			<-release
+		}
+	}))
+
+	do := func(method, accessKey, remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/bucket/object", nil)
+		r.RemoteAddr = remoteAddr
+		r = r.WithContext(SetReqInfo(r.Context(), &ReqInfo{AccessKeyID: accessKey, URL: r.URL}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w
+	}
+
+	t.Run("concurrency by source ip", func(t *testing.T) {
+		done := make(chan struct{})
+		go func() {
+			do(http.MethodPut, "", "10.0.0.1:1234")
+			close(done)
+		}()
+		<-started
+
+		w := do(http.MethodGet, "", "10.0.0.1:4321")
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.Equal(t, "1", w.Header().Get(hdrRetryAfter))
+		require.Contains(t, w.Body.String(), "SlowDown")
+
+		require.Equal(t, http.StatusOK, do(http.MethodGet, "", "10.0.0.2:1234").Code)
+
+		close(release)
+		<-done
+		require.Equal(t, http.StatusOK, do(http.MethodGet, "", "10.0.0.1:4321").Code)
+	})
+
+	t.Run("requests per second by access key", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, do(http.MethodGet, limitedKey, "10.0.0.3:1234").Code)
+		require.Equal(t, http.StatusOK, do(http.MethodGet, limitedKey, "10.0.0.3:1234").Code)
+
+		w := do(http.MethodGet, limitedKey, "10.0.0.3:1234")
+		require.Equal(t, http.StatusServiceUnavailable, w.Code)
+		require.Equal(t, "10", w.Header().Get(hdrRetryAfter))
+
+		for i := 0; i < 10; i++ {
+			require.Equal(t, http.StatusOK, do(http.MethodGet, unlimitedKey, "10.0.0.3:1234").Code)
+		}
+	})
+}
+
+func TestRateLimiterSourceIP(t *testing.T) {
+	_, proxy, err := net.ParseCIDR("10.0.0.0/24")
+	require.NoError(t, err)
+
+	l := NewRateLimiter(&RateLimits{
+		Default:        Limits{RequestsPerSecond: 0.1, Burst: 1},
+		SourceIP:       Limits{RequestsPerSecond: 0.1, Burst: 2},
+		TrustedProxies: TrustedProxies{proxy},
+	})
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	authenticated := l.Middleware(h)
+	source := l.SourceMiddleware(h)
+
+	do := func(h http.Handler, remoteAddr, forwardedFor string) int {
+		r := httptest.NewRequest(http.MethodGet, "/bucket/object", nil)
+		r.RemoteAddr = remoteAddr
+		if forwardedFor != "" {
+			r.Header.Set(xForwardedFor, forwardedFor)
+		}
+		r = r.WithContext(SetReqInfo(r.Context(), &ReqInfo{URL: r.URL}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	t.Run("forwarding headers of untrusted peer", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, do(authenticated, "192.0.2.1:1234", "203.0.113.1"))
+		require.Equal(t, http.StatusServiceUnavailable, do(authenticated, "192.0.2.1:1234", "203.0.113.2"))
+	})
+
+	t.Run("forwarding headers of trusted proxy", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, do(authenticated, "10.0.0.1:1234", "203.0.113.1"))
+		require.Equal(t, http.StatusOK, do(authenticated, "10.0.0.1:1234", "203.0.113.2"))
+		require.Equal(t, http.StatusServiceUnavailable, do(authenticated, "10.0.0.2:1234", "203.0.113.2"))
+	})
+
+	t.Run("before authentication", func(t *testing.T) {
+		require.Equal(t, http.StatusOK, do(source, "192.0.2.3:1234", ""))
+		require.Equal(t, http.StatusOK, do(source, "192.0.2.3:1234", ""))
+		require.Equal(t, http.StatusServiceUnavailable, do(source, "192.0.2.3:1234", ""))
+	})
+}
+
+func TestRateLimiterClients(t *testing.T) {
+	l := NewRateLimiter(&RateLimits{Default: Limits{Concurrency: 1}}).(*rateLimiter)
+	l.maxClients = 2
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	acquire := func(key string) *clientLimiter {
+		client, _ := l.acquire(r, func(*http.Request, *RateLimits) (string, Limits) {
+			return key, l.cfg.Default
+		})
+		return client
+	}
+
+	c1 := acquire("1")
+	require.NotNil(t, c1)
+	c2 := acquire("2")
+	require.NotNil(t, c2)
+
+	// all the clients are busy
+	require.Nil(t, acquire("3"))
+
+	// the least recently seen idle client is evicted
+	l.release(c2)
+	l.release(c1)
+	require.NotNil(t, acquire("3"))
+	require.Len(t, l.clients, 2)
+	require.Contains(t, l.clients, "1")
+	require.Contains(t, l.clients, "3")
+
+	// idle clients are dropped
+	l.sweep(time.Now().Add(2 * clientIdleTimeout))
+	require.Len(t, l.clients, 1)
+	require.Contains(t, l.clients, "3")
+}
+
+func TestRateLimiterUpdate(t *testing.T) {
+	cfg := &RateLimits{
+		Default:   Limits{RequestsPerSecond: 0.1, Burst: 1},
+		SourceIP:  Limits{RequestsPerSecond: 0.1, Burst: 1},
+		Overrides: map[string]Limits{"changed": {RequestsPerSecond: 0.1, Burst: 1}},
+	}
+	l := NewRateLimiter(cfg)
+	h := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	authenticated := l.Middleware(h)
+	source := l.SourceMiddleware(h)
+
+	do := func(h http.Handler, accessKey string) int {
+		r := httptest.NewRequest(http.MethodGet, "/bucket/object", nil)
+		r.RemoteAddr = "192.0.2.1:1234"
+		r = r.WithContext(SetReqInfo(r.Context(), &ReqInfo{AccessKeyID: accessKey, URL: r.URL}))
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	for _, key := range []string{"kept", "changed"} {
+		require.Equal(t, http.StatusOK, do(authenticated, key))
+		require.Equal(t, http.StatusServiceUnavailable, do(authenticated, key))
+	}
+	require.Equal(t, http.StatusOK, do(source, ""))
+	require.Equal(t, http.StatusServiceUnavailable, do(source, ""))
+
+	l.Update(&RateLimits{
+		Default:   cfg.Default,
+		SourceIP:  cfg.SourceIP,
+		Overrides: map[string]Limits{"changed": {RequestsPerSecond: 0.1, Burst: 2}},
+	})
+
+	// the clients with the same limits are still limited
+	require.Equal(t, http.StatusServiceUnavailable, do(authenticated, "kept"))
+	require.Equal(t, http.StatusServiceUnavailable, do(source, ""))
+
+	// the state of the client with new limits is reset
+	require.Equal(t, http.StatusOK, do(authenticated, "changed"))
+	require.Equal(t, http.StatusOK, do(authenticated, "changed"))
+	require.Equal(t, http.StatusServiceUnavailable, do(authenticated, "changed"))
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(100, 100, now)
+
+	require.Zero(t, b.take(now, 60, true))
+	require.Equal(t, 200*time.Millisecond, b.take(now, 60, true))
+	// tokens are in debt, so the next caller waits longer
+	require.Equal(t, 700*time.Millisecond, b.take(now, 50, false))
+	require.Zero(t, b.take(now.Add(time.Second), 50, false))
+}
@@ -118,9 +118,12 @@ func WriteErrorResponse(w http.ResponseWriter, reqInfo *ReqInfo, err error) {
 
 		switch e.Code {
 		case "SlowDown", "XNeoFSServerNotInitialized", "XNeoFSReadQuorum", "XNeoFSWriteQuorum":
-			// Set retry-after header to indicate user-agents to retry request after 120secs.
+			// Set retry-after header to indicate user-agents to retry request after 120secs
+			// unless the caller knows better.
 			// https://developer.mozilla.org/en-US/docs/Web/HTTP/Headers/Retry-After
-			w.Header().Set(hdrRetryAfter, "120")
+			if w.Header().Get(hdrRetryAfter) == "" {
+				w.Header().Set(hdrRetryAfter, "120")
+			}
 		case "AccessDenied":
 			// TODO process when the request is from browser and also if browser
 		}
@@ -9,6 +9,11 @@ import (
 	"github.com/gorilla/mux"
 	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
 	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.4.0"
 	"go.uber.org/zap"
 	"google.golang.org/grpc/metadata"
 )
@@ -57,6 +62,8 @@ type (
 		GetBucketNotificationHandler(http.ResponseWriter, *http.Request)
 		ListenBucketNotificationHandler(http.ResponseWriter, *http.Request)
 		ListMultipartUploadsHandler(http.ResponseWriter, *http.Request)
+		SearchObjectsHandler(http.ResponseWriter, *http.Request)
+		GetBucketQuotaHandler(http.ResponseWriter, *http.Request)
 		ListObjectsV2MHandler(http.ResponseWriter, *http.Request)
 		ListObjectsV2Handler(http.ResponseWriter, *http.Request)
 		ListBucketObjectVersionsHandler(http.ResponseWriter, *http.Request)
@@ -67,6 +74,7 @@ type (
 		PutBucketObjectLockConfigHandler(http.ResponseWriter, *http.Request)
 		PutBucketTaggingHandler(http.ResponseWriter, *http.Request)
 		PutBucketVersioningHandler(http.ResponseWriter, *http.Request)
+		PutBucketQuotaHandler(http.ResponseWriter, *http.Request)
 		PutBucketNotificationHandler(http.ResponseWriter, *http.Request)
 		CreateBucketHandler(http.ResponseWriter, *http.Request)
 		HeadBucketHandler(http.ResponseWriter, *http.Request)
@@ -159,6 +167,29 @@ func logErrorResponse(l *zap.Logger) mux.MiddlewareFunc {
 	}
 }
 
+// traceRequest starts a span for S3 operation continuing the trace of the caller
+// if it's passed in traceparent header.
+func traceRequest(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		reqInfo := GetReqInfo(ctx)
+
+		ctx, span := tracing.StartServerSpan(ctx, mux.CurrentRoute(r).GetName(),
+			semconv.HTTPMethodKey.String(r.Method),
+			semconv.HTTPTargetKey.String(r.URL.RequestURI()),
+			tracing.AttrBucket.String(reqInfo.BucketName),
+			tracing.AttrObject.String(reqInfo.ObjectName),
+			attribute.String("s3.request_id", reqInfo.RequestID))
+		defer span.End()
+
+		lw := &logResponseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		h.ServeHTTP(lw, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPStatusCodeKey.Int(lw.statusCode))
+		span.SetStatus(semconv.SpanStatusFromHTTPStatusCode(lw.statusCode))
+	})
+}
+
 // GetRequestID returns request ID from response writer or context.
 func GetRequestID(v interface{}) string {
 	switch t := v.(type) {
@@ -171,22 +202,44 @@ func GetRequestID(v interface{}) string {
 	}
 }
 
-// Attach adds S3 API handlers from h to r for domains with m client limit using
-// center authentication and log logger.
-func Attach(r *mux.Router, domains []string, m MaxClients, h Handler, center auth.Center, log *zap.Logger) {
+// Attach adds S3 API handlers from h to r for domains with m client limit and
+// rl limits of every client using center authentication, optional az
+// authorization and log logger. Middlewares mws are applied after request
+// info is set and before authentication, so they see the requests failed on it.
+func Attach(r *mux.Router, domains []string, m MaxClients, rl RateLimiter, az Authorizer, h Handler, center auth.Center, log *zap.Logger, mws ...mux.MiddlewareFunc) {
 	api := r.PathPrefix(SlashSeparator).Subrouter()
 
 	api.Use(
 		// -- prepare request
 		setRequestID,
+	)
+
+	// -- access log, in-flight requests tracking
+	api.Use(mws...)
+
+	api.Use(
 
 		// -- logging error requests
 		logErrorResponse(log),
+
+		// -- tracing
+		traceRequest,
 	)
 
+	// Source IPs are limited before authentication to limit requests failing it.
+	api.Use(rl.SourceMiddleware)
+
 	// Attach user authentication for all S3 routes.
 	AttachUserAuth(api, center, log)
 
+	// Limits are applied per access key, so they go after authentication.
+	api.Use(rl.Middleware)
+
+	// External authorization is asked for the requests within the limits only.
+	if az != nil {
+		api.Use(az.Middleware)
+	}
+
 	buckets := make([]*mux.Router, 0, len(domains)+1)
 	buckets = append(buckets, api.PathPrefix("/{bucket}").Subrouter())
 
@@ -366,6 +419,14 @@ func Attach(r *mux.Router, domains []string, m MaxClients, h Handler, center aut
 		bucket.Methods(http.MethodGet).HandlerFunc(
 			m.Handle(metrics.APIStats("listmultipartuploads", h.ListMultipartUploadsHandler))).Queries("uploads", "").
 			Name("ListMultipartUploads")
+		// SearchObjects (NeoFS extension)
+		bucket.Methods(http.MethodGet).HandlerFunc(
+			m.Handle(metrics.APIStats("searchobjects", h.SearchObjectsHandler))).Queries("x-neofs-search", "").
+			Name("SearchObjects")
+		// GetBucketQuota (NeoFS extension)
+		bucket.Methods(http.MethodGet).HandlerFunc(
+			m.Handle(metrics.APIStats("getbucketquota", h.GetBucketQuotaHandler))).Queries("x-neofs-quota", "").
+			Name("GetBucketQuota")
 		// ListObjectsV2M
 		bucket.Methods(http.MethodGet).HandlerFunc(
 			m.Handle(metrics.APIStats("listobjectsv2M", h.ListObjectsV2MHandler))).Queries("list-type", "2", "metadata", "true").
@@ -408,6 +469,10 @@ func Attach(r *mux.Router, domains []string, m MaxClients, h Handler, center aut
 		bucket.Methods(http.MethodPut).HandlerFunc(
 			m.Handle(metrics.APIStats("putbucketversioning", h.PutBucketVersioningHandler))).Queries("versioning", "").
 			Name("PutBucketVersioning")
+		// PutBucketQuota (NeoFS extension)
+		bucket.Methods(http.MethodPut).HandlerFunc(
+			m.Handle(metrics.APIStats("putbucketquota", h.PutBucketQuotaHandler))).Queries("x-neofs-quota", "").
+			Name("PutBucketQuota")
 		// PutBucketNotification
 		bucket.Methods(http.MethodPut).HandlerFunc(
 			m.Handle(metrics.APIStats("putbucketnotification", h.PutBucketNotificationHandler))).Queries("notification", "").
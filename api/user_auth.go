@@ -35,7 +35,17 @@ func AttachUserAuth(router *mux.Router, center auth.Center, log *zap.Logger) {
 					return
 				}
 			} else {
-				ctx = context.WithValue(r.Context(), BoxData, box)
+				ctx = context.WithValue(r.Context(), BoxData, box.AccessBox)
+				reqInfo := GetReqInfo(ctx)
+				// ReqInfo is read by the admin API concurrently
+				reqInfo.Lock()
+				reqInfo.AccessKeyID = box.AccessKeyID
+				if gate := box.AccessBox.Gate; gate != nil && gate.BearerToken != nil {
+					if ownerID := gate.BearerToken.Issuer(); ownerID != nil {
+						reqInfo.OwnerID = ownerID.String()
+					}
+				}
+				reqInfo.Unlock()
 			}
 
 			h.ServeHTTP(w, r.WithContext(ctx))
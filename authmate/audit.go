@@ -0,0 +1,98 @@
+package authmate
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/audit"
+)
+
+// VerifyAuditOptions contains options for passing to VerifyAudit function.
+type VerifyAuditOptions struct {
+	// Files are paths to audit objects downloaded from the audit container.
+	Files         []string
+	GatePublicKey *keys.PublicKey
+}
+
+// VerifyAudit checks that the records of the files form continuous chains
+// signed by the gate and writes the verified ranges to io.Writer. Records of
+// every gateway instance form a separate chain. It doesn't need a connection
+// to NeoFS.
+func VerifyAudit(w io.Writer, options *VerifyAuditOptions) error {
+	chains := make(map[string][]*audit.Record)
+	for _, path := range options.Files {
+		recs, err := readAuditFile(path)
+		if err != nil {
+			return err
+		}
+		for _, rec := range recs {
+			chains[rec.Instance] = append(chains[rec.Instance], rec)
+		}
+	}
+
+	if len(chains) == 0 {
+		return fmt.Errorf("no audit records found")
+	}
+
+	instances := make([]string, 0, len(chains))
+	for instance := range chains {
+		instances = append(instances, instance)
+	}
+	sort.Strings(instances)
+
+	for _, instance := range instances {
+		records, err := sortAuditRecords(chains[instance])
+		if err != nil {
+			return fmt.Errorf("instance %q: %w", instance, err)
+		}
+
+		if err = audit.Verify(records, options.GatePublicKey); err != nil {
+			return fmt.Errorf("instance %q: %w", instance, err)
+		}
+
+		first, last := records[0], records[len(records)-1]
+		fmt.Fprintf(w, "instance %q: verified records %d-%d (%s - %s)\n", instance, first.Seq, last.Seq, first.Time, last.Time)
+		if first.Seq != 1 {
+			fmt.Fprintf(w, "instance %q: records before %d weren't checked\n", instance, first.Seq)
+		}
+	}
+
+	return nil
+}
+
+func readAuditFile(path string) ([]*audit.Record, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	records, err := audit.ReadRecords(f)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read %s: %w", path, err)
+	}
+	return records, nil
+}
+
+// sortAuditRecords orders the records by sequence number and drops duplicates,
+// which appear when the same records were sealed twice.
+func sortAuditRecords(records []*audit.Record) ([]*audit.Record, error) {
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Seq < records[j].Seq })
+
+	res := records[:1]
+	for _, rec := range records[1:] {
+		prev := res[len(res)-1]
+		if rec.Seq != prev.Seq {
+			res = append(res, rec)
+			continue
+		}
+		if rec.Hash != prev.Hash {
+			return nil, fmt.Errorf("there are different records with sequence number %d", rec.Seq)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,94 @@
+package authmate
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/audit"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fileSealer struct {
+	dir   string
+	files []string
+	seq   uint64
+	hash  string
+}
+
+func (s *fileSealer) Seal(_ context.Context, records []*audit.Record) error {
+	path := filepath.Join(s.dir, "audit-"+records[0].Hash[:8]+".jsonl")
+	buf := new(bytes.Buffer)
+	if err := audit.WriteRecords(buf, records); err != nil {
+		return err
+	}
+	s.files = append(s.files, path)
+	last := records[len(records)-1]
+	s.seq, s.hash = last.Seq, last.Hash
+	return os.WriteFile(path, buf.Bytes(), 0644)
+}
+
+func (s *fileSealer) Last(context.Context) (uint64, string, error) {
+	return s.seq, s.hash, nil
+}
+
+func TestVerifyAudit(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	sealer := &fileSealer{dir: t.TempDir()}
+	journal, err := audit.NewFileJournal(filepath.Join(t.TempDir(), "audit.journal"))
+	require.NoError(t, err)
+	defer func() { require.NoError(t, journal.Close()) }()
+	trail, err := audit.NewTrail(context.Background(), key, "gw1", sealer, journal, zap.NewNop())
+	require.NoError(t, err)
+
+	for i := 0; i < 3; i++ {
+		trail.Record(audit.Event{Action: audit.ActionObjectPut})
+		trail.Record(audit.Event{Action: audit.ActionObjectDelete})
+		require.NoError(t, trail.Seal(context.Background()))
+	}
+
+	verify := func(files ...string) (string, error) {
+		buf := new(bytes.Buffer)
+		err := VerifyAudit(buf, &VerifyAuditOptions{Files: files, GatePublicKey: key.PublicKey()})
+		return buf.String(), err
+	}
+
+	// files are in any order, duplicates are allowed
+	out, err := verify(sealer.files[2], sealer.files[0], sealer.files[1], sealer.files[0])
+	require.NoError(t, err)
+	require.Contains(t, out, "verified records 1-6")
+
+	out, err = verify(sealer.files[1:]...)
+	require.NoError(t, err)
+	require.Contains(t, out, "records before 3 weren't checked")
+
+	_, err = verify(sealer.files[0], sealer.files[2])
+	require.Error(t, err)
+
+	t.Run("several instances", func(t *testing.T) {
+		otherSealer := &fileSealer{dir: t.TempDir()}
+		otherJournal, err := audit.NewFileJournal(filepath.Join(t.TempDir(), "audit.journal"))
+		require.NoError(t, err)
+		defer func() { require.NoError(t, otherJournal.Close()) }()
+		otherTrail, err := audit.NewTrail(context.Background(), key, "gw2", otherSealer, otherJournal, zap.NewNop())
+		require.NoError(t, err)
+		otherTrail.Record(audit.Event{Action: audit.ActionObjectPut})
+		require.NoError(t, otherTrail.Seal(context.Background()))
+
+		out, err := verify(append(sealer.files, otherSealer.files...)...)
+		require.NoError(t, err)
+		require.Contains(t, out, `instance "gw1": verified records 1-6`)
+		require.Contains(t, out, `instance "gw2": verified records 1-1`)
+	})
+
+	other, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	err = VerifyAudit(new(bytes.Buffer), &VerifyAuditOptions{Files: sealer.files, GatePublicKey: other.PublicKey()})
+	require.Error(t, err)
+}
@@ -1,6 +1,7 @@
 package authmate
 
 import (
+	"bytes"
 	"context"
 	"crypto/ecdsa"
 	"encoding/hex"
@@ -8,7 +9,6 @@ import (
 	"fmt"
 	"io"
 	"math"
-	"os"
 	"strconv"
 	"time"
 
@@ -58,8 +58,12 @@ type (
 		ContextRules          []byte
 		SessionTkn            bool
 		Lifetime              uint64
-		AwsCliCredentialsFile string
 		ContainerPolicies     ContainerPolicies
+		Output                CredentialsOutput
+		// Policy replaces EACLRules and ContextRules if it's set.
+		Policy *Policy
+		// DryRun makes IssueSecret print the rules of the tokens without issuing them.
+		DryRun bool
 	}
 
 	// ObtainSecretOptions contains options for passing to Agent.ObtainSecret method.
@@ -81,6 +85,14 @@ type (
 		SecretAccessKey string `json:"secret_access_key"`
 		OwnerPrivateKey string `json:"owner_private_key"`
 		ContainerID     string `json:"container_id"`
+		// ReplacedAccessKeyID is an access key ID of the re-encrypted secret,
+		// it's resolved to the new box.
+		ReplacedAccessKeyID string `json:"replaced_access_key_id,omitempty"`
+	}
+
+	dryRunResult struct {
+		BearerRules  json.RawMessage   `json:"bearer_rules"`
+		SessionRules []json.RawMessage `json:"session_rules,omitempty"`
 	}
 
 	obtainingResult struct {
@@ -181,6 +193,14 @@ func (a *Agent) IssueSecret(ctx context.Context, w io.Writer, options *IssueSecr
 		return err
 	}
 
+	if options.DryRun {
+		table, sessionCtxs, err := a.buildRules(ctx, options, options.ContainerID)
+		if err != nil {
+			return err
+		}
+		return printRules(w, table, sessionCtxs)
+	}
+
 	lifetime.Iat, err = a.getCurrentEpoch(ctx)
 	if err != nil {
 		return err
@@ -197,7 +217,12 @@ func (a *Agent) IssueSecret(ctx context.Context, w io.Writer, options *IssueSecr
 		return err
 	}
 
-	gatesData, err := createTokens(options, lifetime, cid)
+	table, sessionCtxs, err := a.buildRules(ctx, options, cid)
+	if err != nil {
+		return err
+	}
+
+	gatesData, err := createTokens(options, lifetime, table, sessionCtxs)
 	if err != nil {
 		return fmt.Errorf("failed to build bearer token: %w", err)
 	}
@@ -241,28 +266,11 @@ func (a *Agent) IssueSecret(ctx context.Context, w io.Writer, options *IssueSecr
 		ContainerID:     cid.String(),
 	}
 
-	enc := json.NewEncoder(w)
-	enc.SetIndent("", "  ")
-	if err = enc.Encode(ir); err != nil {
-		return err
-	}
-
-	if options.AwsCliCredentialsFile != "" {
-		profileName := "authmate_cred_" + address.ObjectID().String()
-		if _, err = os.Stat(options.AwsCliCredentialsFile); os.IsNotExist(err) {
-			profileName = "default"
-		}
-		file, err := os.OpenFile(options.AwsCliCredentialsFile, os.O_APPEND|os.O_WRONLY|os.O_CREATE, 0600)
-		if err != nil {
-			return fmt.Errorf("couldn't open aws cli credentials file: %w", err)
-		}
-		defer file.Close()
-		if _, err = file.WriteString(fmt.Sprintf("\n[%s]\naws_access_key_id = %s\naws_secret_access_key = %s\n",
-			profileName, accessKeyID, secrets.AccessKey)); err != nil {
-			return err
-		}
-	}
-	return nil
+	return options.Output.writeCredentials(w, ir, clientCredentials{
+		AccessKeyID:     accessKeyID,
+		SecretAccessKey: secrets.AccessKey,
+		ProfileSuffix:   address.ObjectID().String(),
+	})
 }
 
 // ObtainSecret receives an existing secret access key from NeoFS and
@@ -343,20 +351,49 @@ func buildEACLTable(cid *cid.ID, eaclTable []byte) (*eacl.Table, error) {
 	return table, nil
 }
 
-func buildContext(rules []byte) (*session.ContainerContext, error) {
-	sessionCtx := session.NewContainerContext() // wildcard == true on by default
+// buildContexts makes contexts of the session tokens from the rules, which
+// are either a single context or a list of them. Every context has one verb,
+// so by default there are contexts to create, delete buckets and set their
+// ACL.
+func buildContexts(rules []byte) ([]*session.ContainerContext, error) {
+	if len(rules) == 0 {
+		return defaultContexts(), nil
+	}
 
-	if len(rules) != 0 {
-		// cast ToV2 temporary, because there is no method for unmarshalling in ContainerContext in api-go
-		err := sessionCtx.UnmarshalJSON(rules)
-		if err != nil {
+	list := []json.RawMessage{rules}
+	if trimmed := bytes.TrimSpace(rules); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &list); err != nil {
+			return nil, fmt.Errorf("failed to read rules for session tokens: %w", err)
+		}
+	}
+
+	sessionCtxs := make([]*session.ContainerContext, 0, len(list))
+	for _, data := range list {
+		sessionCtx := session.NewContainerContext() // wildcard == true on by default
+		if err := sessionCtx.UnmarshalJSON(data); err != nil {
 			return nil, fmt.Errorf("failed to read rules for session token: %w", err)
 		}
-		return sessionCtx, nil
+		sessionCtxs = append(sessionCtxs, sessionCtx)
+	}
+	return sessionCtxs, nil
+}
+
+func defaultContexts() []*session.ContainerContext {
+	return newContexts((*session.ContainerContext).ForPut,
+		(*session.ContainerContext).ForDelete,
+		(*session.ContainerContext).ForSetEACL)
+}
+
+// newContexts returns contexts with the verbs for all containers.
+func newContexts(verbs ...func(*session.ContainerContext)) []*session.ContainerContext {
+	sessionCtxs := make([]*session.ContainerContext, 0, len(verbs))
+	for _, verb := range verbs {
+		sessionCtx := session.NewContainerContext()
+		verb(sessionCtx)
+		sessionCtx.ApplyTo(nil)
+		sessionCtxs = append(sessionCtxs, sessionCtx)
 	}
-	sessionCtx.ForPut()
-	sessionCtx.ApplyTo(nil)
-	return sessionCtx, nil
+	return sessionCtxs
 }
 
 func buildBearerToken(key *keys.PrivateKey, table *eacl.Table, lifetime lifetimeOptions, gateKey *keys.PublicKey) (*token.BearerToken, error) {
@@ -415,13 +452,63 @@ func buildSessionTokens(key *keys.PrivateKey, oid *owner.ID, lifetime lifetimeOp
 	return sessionTokens, nil
 }
 
-func createTokens(options *IssueSecretOptions, lifetime lifetimeOptions, cid *cid.ID) ([]*accessbox.GateData, error) {
-	gates := make([]*accessbox.GateData, len(options.GatesPublicKeys))
+// buildRules returns eACL table of the bearer token and contexts of the session
+// tokens, there are no contexts if session tokens aren't needed.
+func (a *Agent) buildRules(ctx context.Context, options *IssueSecretOptions, cid *cid.ID) (*eacl.Table, []*session.ContainerContext, error) {
+	if options.Policy != nil {
+		oid, err := ownerIDFromNeoFSKey(options.NeoFSKey.PublicKey())
+		if err != nil {
+			return nil, nil, err
+		}
+		containers, err := a.resolveBuckets(ctx, oid, options.Policy)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve policy buckets: %w", err)
+		}
+		return options.Policy.buildTable(containers), options.Policy.buildContexts(), nil
+	}
 
 	table, err := buildEACLTable(cid, options.EACLRules)
 	if err != nil {
-		return nil, fmt.Errorf("failed to build eacl table: %w", err)
+		return nil, nil, fmt.Errorf("failed to build eacl table: %w", err)
+	}
+
+	if !options.SessionTkn {
+		return table, nil, nil
+	}
+
+	sessionCtxs, err := buildContexts(options.ContextRules)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to build context for session token: %w", err)
+	}
+
+	return table, sessionCtxs, nil
+}
+
+func printRules(w io.Writer, table *eacl.Table, sessionCtxs []*session.ContainerContext) error {
+	var (
+		res dryRunResult
+		err error
+	)
+
+	if res.BearerRules, err = table.MarshalJSON(); err != nil {
+		return fmt.Errorf("couldn't marshal eacl table: %w", err)
+	}
+	for _, sessionCtx := range sessionCtxs {
+		data, err := sessionCtx.MarshalJSON()
+		if err != nil {
+			return fmt.Errorf("couldn't marshal session context: %w", err)
+		}
+		res.SessionRules = append(res.SessionRules, data)
 	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(res)
+}
+
+func createTokens(options *IssueSecretOptions, lifetime lifetimeOptions, table *eacl.Table, sessionCtxs []*session.ContainerContext) ([]*accessbox.GateData, error) {
+	gates := make([]*accessbox.GateData, len(options.GatesPublicKeys))
+
 	bearerTokens, err := buildBearerTokens(options.NeoFSKey, table, lifetime, options.GatesPublicKeys)
 	if err != nil {
 		return nil, fmt.Errorf("failed to build bearer tokens: %w", err)
@@ -430,22 +517,20 @@ func createTokens(options *IssueSecretOptions, lifetime lifetimeOptions, cid *ci
 		gates[i] = accessbox.NewGateData(gateKey, bearerTokens[i])
 	}
 
-	if options.SessionTkn {
-		sessionRules, err := buildContext(options.ContextRules)
-		if err != nil {
-			return nil, fmt.Errorf("failed to build context for session token: %w", err)
-		}
+	if len(sessionCtxs) > 0 {
 		oid, err := ownerIDFromNeoFSKey(options.NeoFSKey.PublicKey())
 		if err != nil {
 			return nil, err
 		}
 
-		sessionTokens, err := buildSessionTokens(options.NeoFSKey, oid, lifetime, sessionRules, options.GatesPublicKeys)
-		if err != nil {
-			return nil, err
-		}
-		for i, sessionToken := range sessionTokens {
-			gates[i].SessionToken = sessionToken
+		for _, sessionCtx := range sessionCtxs {
+			sessionTokens, err := buildSessionTokens(options.NeoFSKey, oid, lifetime, sessionCtx, options.GatesPublicKeys)
+			if err != nil {
+				return nil, err
+			}
+			for i, sessionToken := range sessionTokens {
+				gates[i].SessionTokens = append(gates[i].SessionTokens, sessionToken)
+			}
 		}
 	}
 
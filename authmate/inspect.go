@@ -0,0 +1,304 @@
+package authmate
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-api-go/pkg/acl/eacl"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"github.com/nspcc-dev/neofs-api-go/pkg/session"
+	"github.com/nspcc-dev/neofs-api-go/pkg/token"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
+	"github.com/nspcc-dev/neofs-sdk-go/policy"
+)
+
+type (
+	// InspectSecretOptions contains options for passing to Agent.InspectSecret method.
+	InspectSecretOptions struct {
+		SecretAddress  string
+		GatePrivateKey *keys.PrivateKey
+		// EpochDuration is used to estimate wall-clock time of token expiration.
+		EpochDuration time.Duration
+		JSON          bool
+	}
+
+	secretInfo struct {
+		AccessKeyID       string            `json:"access_key_id"`
+		IssuerID          string            `json:"issuer_id"`
+		GatePublicKeys    []string          `json:"gate_public_keys"`
+		CurrentEpoch      uint64            `json:"current_epoch"`
+		BearerToken       *bearerInfo       `json:"bearer_token,omitempty"`
+		SessionTokens     []*sessionInfo    `json:"session_tokens,omitempty"`
+		ContainerPolicies map[string]string `json:"container_policies,omitempty"`
+
+		gateKey string
+	}
+
+	tokenLifetime struct {
+		Iat uint64 `json:"iat"`
+		Nbf uint64 `json:"nbf"`
+		Exp uint64 `json:"exp"`
+		// ExpiresAt is an estimation, it's nil if the token never expires.
+		ExpiresAt *time.Time `json:"expires_at,omitempty"`
+		Expired   bool       `json:"expired"`
+	}
+
+	bearerInfo struct {
+		tokenLifetime
+		Records []eaclRecordInfo `json:"records"`
+	}
+
+	eaclRecordInfo struct {
+		Operation string   `json:"operation"`
+		Action    string   `json:"action"`
+		Filters   []string `json:"filters,omitempty"`
+		Targets   []string `json:"targets"`
+	}
+
+	sessionInfo struct {
+		tokenLifetime
+		Verb string `json:"verb"`
+		// Container is empty if the session applies to any container.
+		Container string `json:"container,omitempty"`
+	}
+)
+
+// InspectSecret decrypts an existing access box with the gate key and writes
+// its description to io.Writer.
+func (a *Agent) InspectSecret(ctx context.Context, w io.Writer, options *InspectSecretOptions) error {
+	address := object.NewAddress()
+	if err := address.Parse(options.SecretAddress); err != nil {
+		return fmt.Errorf("failed to parse secret address: %w", err)
+	}
+
+	box, err := tokens.New(a.pool, options.GatePrivateKey).GetAccessBox(ctx, address)
+	if err != nil {
+		return fmt.Errorf("failed to get access box: %w", err)
+	}
+
+	gate, err := box.GetTokens(options.GatePrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt tokens: %w", err)
+	}
+
+	policies, err := box.GetPlacementPolicy()
+	if err != nil {
+		return fmt.Errorf("failed to parse container policies: %w", err)
+	}
+
+	epoch, err := a.getCurrentEpoch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get current epoch: %w", err)
+	}
+
+	info := newSecretInfo(strings.ReplaceAll(address.String(), "/", "0"), box, gate, policies, epoch,
+		options.EpochDuration, time.Now())
+
+	if options.JSON {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(info)
+	}
+
+	return info.print(w)
+}
+
+func newSecretInfo(accessKeyID string, box *accessbox.AccessBox, gate *accessbox.GateData,
+	policies []*accessbox.ContainerPolicy, epoch uint64, epochDuration time.Duration, now time.Time) *secretInfo {
+	info := &secretInfo{
+		AccessKeyID:  accessKeyID,
+		CurrentEpoch: epoch,
+	}
+
+	if gate.GateKey != nil {
+		info.gateKey = hex.EncodeToString(gate.GateKey.Bytes())
+	}
+	for _, g := range box.Gates {
+		info.GatePublicKeys = append(info.GatePublicKeys, hex.EncodeToString(g.GatePublicKey))
+	}
+
+	if gate.BearerToken != nil {
+		if issuer := gate.BearerToken.Issuer(); issuer != nil {
+			info.IssuerID = issuer.String()
+		}
+		info.BearerToken = newBearerInfo(gate.BearerToken, epoch, epochDuration, now)
+	}
+
+	for _, tkn := range gate.SessionTokens {
+		if info.IssuerID == "" && tkn.OwnerID() != nil {
+			info.IssuerID = tkn.OwnerID().String()
+		}
+		info.SessionTokens = append(info.SessionTokens, newSessionInfo(tkn, epoch, epochDuration, now))
+	}
+
+	if len(policies) > 0 {
+		info.ContainerPolicies = make(map[string]string, len(policies))
+		for _, p := range policies {
+			info.ContainerPolicies[p.LocationConstraint] = strings.Join(policy.Encode(p.Policy), " ")
+		}
+	}
+
+	return info
+}
+
+func newLifetime(iat, nbf, exp, epoch uint64, epochDuration time.Duration, now time.Time) tokenLifetime {
+	lt := tokenLifetime{Iat: iat, Nbf: nbf, Exp: exp, Expired: exp < epoch}
+
+	if epochDuration <= 0 {
+		return lt
+	}
+
+	maxEpochs := uint64(math.MaxInt64 / int64(epochDuration))
+	switch {
+	case exp >= epoch && exp-epoch <= maxEpochs:
+		at := now.Add(time.Duration(exp-epoch) * epochDuration)
+		lt.ExpiresAt = &at
+	case exp < epoch && epoch-exp <= maxEpochs:
+		at := now.Add(-time.Duration(epoch-exp) * epochDuration)
+		lt.ExpiresAt = &at
+	}
+
+	return lt
+}
+
+func newBearerInfo(t *token.BearerToken, epoch uint64, epochDuration time.Duration, now time.Time) *bearerInfo {
+	body := t.ToV2().GetBody()
+	lifetime := body.GetLifetime()
+
+	info := &bearerInfo{
+		tokenLifetime: newLifetime(lifetime.GetIat(), lifetime.GetNbf(), lifetime.GetExp(), epoch, epochDuration, now),
+		Records:       []eaclRecordInfo{},
+	}
+
+	if body.GetEACL() == nil {
+		return info
+	}
+
+	for _, r := range eacl.NewTableFromV2(body.GetEACL()).Records() {
+		rec := eaclRecordInfo{
+			Operation: r.Operation().String(),
+			Action:    r.Action().String(),
+		}
+		for _, f := range r.Filters() {
+			rec.Filters = append(rec.Filters, fmt.Sprintf("%s %s %s %s", f.From(), f.Key(), f.Matcher(), f.Value()))
+		}
+		for _, t := range r.Targets() {
+			if keys := t.BinaryKeys(); len(keys) > 0 {
+				for _, k := range keys {
+					rec.Targets = append(rec.Targets, hex.EncodeToString(k))
+				}
+				continue
+			}
+			rec.Targets = append(rec.Targets, t.Role().String())
+		}
+		info.Records = append(info.Records, rec)
+	}
+
+	return info
+}
+
+func newSessionInfo(t *session.Token, epoch uint64, epochDuration time.Duration, now time.Time) *sessionInfo {
+	info := &sessionInfo{
+		tokenLifetime: newLifetime(t.Iat(), t.Nbf(), t.Exp(), epoch, epochDuration, now),
+	}
+
+	if ctx, ok := t.Context().(*session.ContainerContext); ok {
+		switch {
+		case ctx.IsForPut():
+			info.Verb = "PUT"
+		case ctx.IsForDelete():
+			info.Verb = "DELETE"
+		case ctx.IsForSetEACL():
+			info.Verb = "SETEACL"
+		}
+		if id := ctx.Container(); id != nil {
+			info.Container = id.String()
+		}
+	}
+
+	return info
+}
+
+func (lt tokenLifetime) String() string {
+	var expires string
+	switch {
+	case lt.Exp == math.MaxUint64:
+		expires = "never expires"
+	case lt.ExpiresAt == nil && lt.Expired:
+		expires = "expired"
+	case lt.ExpiresAt == nil:
+		expires = "not expired yet"
+	case lt.Expired:
+		expires = "expired about " + lt.ExpiresAt.UTC().Format(time.RFC3339)
+	default:
+		expires = "expires about " + lt.ExpiresAt.UTC().Format(time.RFC3339)
+	}
+
+	return fmt.Sprintf("issued at epoch %d, valid from epoch %d till epoch %d (%s)", lt.Iat, lt.Nbf, lt.Exp, expires)
+}
+
+func (i *secretInfo) print(w io.Writer) error {
+	buf := new(bytes.Buffer)
+
+	fmt.Fprintf(buf, "Access key ID: %s\n", i.AccessKeyID)
+	fmt.Fprintf(buf, "Issuer:        %s\n", i.IssuerID)
+	fmt.Fprintf(buf, "Current epoch: %d\n", i.CurrentEpoch)
+
+	fmt.Fprintln(buf, "Gate public keys:")
+	for _, key := range i.GatePublicKeys {
+		if key == i.gateKey {
+			key += " (used to decrypt)"
+		}
+		fmt.Fprintf(buf, "  %s\n", key)
+	}
+
+	if i.BearerToken != nil {
+		fmt.Fprintln(buf, "Bearer token:")
+		fmt.Fprintf(buf, "  %s\n", i.BearerToken.tokenLifetime)
+		if len(i.BearerToken.Records) == 0 {
+			fmt.Fprintln(buf, "  no eACL records")
+		}
+		for n, r := range i.BearerToken.Records {
+			fmt.Fprintf(buf, "  %d. %s %s for %s\n", n+1, r.Action, r.Operation, strings.Join(r.Targets, ", "))
+			for _, f := range r.Filters {
+				fmt.Fprintf(buf, "     if %s\n", f)
+			}
+		}
+	}
+
+	for _, tkn := range i.SessionTokens {
+		container := tkn.Container
+		if container == "" {
+			container = "any container"
+		}
+		fmt.Fprintln(buf, "Session token:")
+		fmt.Fprintf(buf, "  %s\n", tkn.tokenLifetime)
+		fmt.Fprintf(buf, "  allows %s for %s\n", tkn.Verb, container)
+	}
+
+	if len(i.ContainerPolicies) > 0 {
+		locations := make([]string, 0, len(i.ContainerPolicies))
+		for location := range i.ContainerPolicies {
+			locations = append(locations, location)
+		}
+		sort.Strings(locations)
+
+		fmt.Fprintln(buf, "Container policies:")
+		for _, location := range locations {
+			fmt.Fprintf(buf, "  %s: %s\n", location, i.ContainerPolicies[location])
+		}
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
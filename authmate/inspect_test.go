@@ -0,0 +1,90 @@
+package authmate
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-api-go/pkg/acl/eacl"
+	"github.com/nspcc-dev/neofs-api-go/pkg/owner"
+	"github.com/nspcc-dev/neofs-api-go/pkg/session"
+	"github.com/nspcc-dev/neofs-api-go/pkg/token"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecretInfo(t *testing.T) {
+	gateKey, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	otherGateKey, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	table := eacl.NewTable()
+	record := eacl.CreateRecord(eacl.ActionAllow, eacl.OperationGet)
+	record.AddFilter(eacl.HeaderFromObject, eacl.MatchStringEqual, "FileName", "cat.jpg")
+	eacl.AddFormedTarget(record, eacl.RoleOthers)
+	table.AddRecord(record)
+
+	bearer := token.NewBearerToken()
+	bearer.SetEACLTable(table)
+	bearer.SetLifetime(110, 100, 100)
+
+	wallet, err := owner.NEO3WalletFromPublicKey((*ecdsa.PublicKey)(otherGateKey.PublicKey()))
+	require.NoError(t, err)
+	issuer := owner.NewIDFromNeo3Wallet(wallet)
+
+	sessionCtx := session.NewContainerContext()
+	sessionCtx.ForPut()
+	sessionTkn := session.NewToken()
+	sessionTkn.SetOwnerID(issuer)
+	sessionTkn.SetContext(sessionCtx)
+	sessionTkn.SetIat(100)
+	sessionTkn.SetNbf(100)
+	sessionTkn.SetExp(102)
+
+	box := &accessbox.AccessBox{
+		Gates: []*accessbox.AccessBox_Gate{
+			{GatePublicKey: gateKey.PublicKey().Bytes()},
+			{GatePublicKey: otherGateKey.PublicKey().Bytes()},
+		},
+	}
+	gate := &accessbox.GateData{
+		BearerToken:   bearer,
+		SessionTokens: []*session.Token{sessionTkn},
+		GateKey:       gateKey.PublicKey(),
+	}
+
+	now := time.Date(2021, 10, 1, 12, 0, 0, 0, time.UTC)
+	info := newSecretInfo("access-key", box, gate, nil, 105, time.Minute, now)
+
+	require.Equal(t, "access-key", info.AccessKeyID)
+	require.Equal(t, issuer.String(), info.IssuerID)
+	require.Equal(t, []string{
+		hex.EncodeToString(gateKey.PublicKey().Bytes()),
+		hex.EncodeToString(otherGateKey.PublicKey().Bytes()),
+	}, info.GatePublicKeys)
+
+	require.False(t, info.BearerToken.Expired)
+	require.Equal(t, now.Add(5*time.Minute), *info.BearerToken.ExpiresAt)
+	require.Equal(t, []eaclRecordInfo{{
+		Operation: "GET",
+		Action:    "ALLOW",
+		Filters:   []string{"OBJECT FileName STRING_EQUAL cat.jpg"},
+		Targets:   []string{"OTHERS"},
+	}}, info.BearerToken.Records)
+
+	require.Len(t, info.SessionTokens, 1)
+	require.True(t, info.SessionTokens[0].Expired)
+	require.Equal(t, now.Add(-3*time.Minute), *info.SessionTokens[0].ExpiresAt)
+	require.Equal(t, "PUT", info.SessionTokens[0].Verb)
+	require.Empty(t, info.SessionTokens[0].Container)
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, info.print(buf))
+	require.Contains(t, buf.String(), hex.EncodeToString(gateKey.PublicKey().Bytes())+" (used to decrypt)")
+	require.Contains(t, buf.String(), "1. ALLOW GET for OTHERS")
+	require.Contains(t, buf.String(), "allows PUT for any container")
+}
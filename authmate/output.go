@@ -0,0 +1,258 @@
+package authmate
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/ini.v1"
+)
+
+// Output formats of the issued credentials.
+const (
+	OutputJSON       = "json"
+	OutputEnv        = "env"
+	OutputAWSProfile = "aws-profile"
+	OutputRclone     = "rclone"
+	OutputS3cmd      = "s3cmd"
+)
+
+// defaultProfile is a name of the profile used if no other is set.
+const defaultProfile = "default"
+
+type (
+	// CredentialsOutput describes how to output the issued credentials.
+	CredentialsOutput struct {
+		// Format is one of Output* constants, OutputJSON is used if it's empty.
+		Format string
+		// Profile is a name of aws cli profile or rclone remote.
+		Profile string
+		// Endpoint is an URL of the gateway.
+		Endpoint string
+		Region   string
+		// AwsCliCredentialsFile and AwsCliConfigFile are used with aws-profile format.
+		AwsCliCredentialsFile string
+		AwsCliConfigFile      string
+		// ConfigFile is a config of rclone or s3cmd.
+		ConfigFile string
+	}
+
+	// clientCredentials are the values written to configs of S3 clients.
+	clientCredentials struct {
+		AccessKeyID     string
+		SecretAccessKey string
+		// ProfileSuffix is used to make a profile name if none is set.
+		ProfileSuffix string
+	}
+)
+
+// ValidOutputFormat checks if the format is known.
+func ValidOutputFormat(format string) bool {
+	switch format {
+	case "", OutputJSON, OutputEnv, OutputAWSProfile, OutputRclone, OutputS3cmd:
+		return true
+	}
+	return false
+}
+
+// writeCredentials writes the issuing result to w and the configs of
+// S3 clients according to the output format.
+func (o *CredentialsOutput) writeCredentials(w io.Writer, ir *issuingResult, creds clientCredentials) error {
+	if o.Format == OutputEnv {
+		return o.writeEnv(w, creds)
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(ir); err != nil {
+		return err
+	}
+
+	switch o.Format {
+	case OutputAWSProfile:
+		return o.writeAWSProfile(creds)
+	case OutputRclone:
+		return o.writeRclone(creds)
+	case OutputS3cmd:
+		return o.writeS3cmd(creds)
+	case "", OutputJSON:
+		if o.AwsCliCredentialsFile != "" {
+			return o.writeAWSProfile(creds)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown output format '%s'", o.Format)
+	}
+}
+
+func (o *CredentialsOutput) writeEnv(w io.Writer, creds clientCredentials) error {
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "export AWS_ACCESS_KEY_ID=%s\n", creds.AccessKeyID)
+	fmt.Fprintf(buf, "export AWS_SECRET_ACCESS_KEY=%s\n", creds.SecretAccessKey)
+	if o.Region != "" {
+		fmt.Fprintf(buf, "export AWS_DEFAULT_REGION=%s\n", o.Region)
+	}
+	if o.Endpoint != "" {
+		fmt.Fprintf(buf, "export AWS_ENDPOINT_URL=%s\n", o.Endpoint)
+	}
+
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// profileName returns the name of the profile. Credentials are written to
+// the default profile of a new file and to a profile named after the secret
+// otherwise, just like earlier versions did.
+func (o *CredentialsOutput) profileName(file string, creds clientCredentials) string {
+	if o.Profile != "" {
+		return o.Profile
+	}
+	if _, err := os.Stat(file); os.IsNotExist(err) {
+		return defaultProfile
+	}
+	return "authmate_cred_" + creds.ProfileSuffix
+}
+
+// writeAWSProfile replaces the profile in aws cli credentials file and sets
+// the region and the endpoint of the profile in aws cli config file.
+func (o *CredentialsOutput) writeAWSProfile(creds clientCredentials) error {
+	profile := o.profileName(o.AwsCliCredentialsFile, creds)
+
+	err := updateINI(o.AwsCliCredentialsFile, func(f *ini.File) {
+		f.DeleteSection(profile)
+		sec := f.Section(profile)
+		sec.Key("aws_access_key_id").SetValue(creds.AccessKeyID)
+		sec.Key("aws_secret_access_key").SetValue(creds.SecretAccessKey)
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't update aws cli credentials file: %w", err)
+	}
+
+	if o.AwsCliConfigFile == "" || o.Region == "" && o.Endpoint == "" {
+		return nil
+	}
+
+	// profiles of the config file are prefixed except for the default one
+	section := profile
+	if profile != defaultProfile {
+		section = "profile " + profile
+	}
+
+	err = updateINI(o.AwsCliConfigFile, func(f *ini.File) {
+		sec := f.Section(section)
+		setIfNotEmpty(sec, "region", o.Region)
+		setIfNotEmpty(sec, "endpoint_url", o.Endpoint)
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't update aws cli config file: %w", err)
+	}
+
+	return nil
+}
+
+// writeRclone replaces the remote in rclone config file.
+func (o *CredentialsOutput) writeRclone(creds clientCredentials) error {
+	remote := o.profileName(o.ConfigFile, creds)
+
+	err := updateINI(o.ConfigFile, func(f *ini.File) {
+		f.DeleteSection(remote)
+		sec := f.Section(remote)
+		sec.Key("type").SetValue("s3")
+		sec.Key("provider").SetValue("Other")
+		sec.Key("access_key_id").SetValue(creds.AccessKeyID)
+		sec.Key("secret_access_key").SetValue(creds.SecretAccessKey)
+		setIfNotEmpty(sec, "endpoint", o.Endpoint)
+		setIfNotEmpty(sec, "region", o.Region)
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't update rclone config file: %w", err)
+	}
+
+	return nil
+}
+
+// writeS3cmd sets the credentials and the endpoint in s3cmd config file, it
+// has the only section, so other settings are kept.
+func (o *CredentialsOutput) writeS3cmd(creds clientCredentials) error {
+	var host, https string
+	if o.Endpoint != "" {
+		u, err := url.Parse(o.Endpoint)
+		if err != nil || u.Host == "" {
+			return fmt.Errorf("invalid endpoint '%s'", o.Endpoint)
+		}
+		host, https = u.Host, "False"
+		if u.Scheme == "https" {
+			https = "True"
+		}
+	}
+
+	err := updateINI(o.ConfigFile, func(f *ini.File) {
+		sec := f.Section(defaultProfile)
+		sec.Key("access_key").SetValue(creds.AccessKeyID)
+		sec.Key("secret_key").SetValue(creds.SecretAccessKey)
+		setIfNotEmpty(sec, "host_base", host)
+		// path-style addressing
+		setIfNotEmpty(sec, "host_bucket", host)
+		setIfNotEmpty(sec, "use_https", https)
+		setIfNotEmpty(sec, "bucket_location", o.Region)
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't update s3cmd config file: %w", err)
+	}
+
+	return nil
+}
+
+func setIfNotEmpty(sec *ini.Section, key, value string) {
+	if value != "" {
+		sec.Key(key).SetValue(value)
+	}
+}
+
+// updateINI loads ini file (missing file is treated as empty), applies update
+// and replaces the file atomically keeping other sections intact.
+func updateINI(path string, update func(*ini.File)) error {
+	if path == "" {
+		return fmt.Errorf("path to the file is not set")
+	}
+
+	f, err := ini.LoadSources(ini.LoadOptions{Loose: true, IgnoreInlineComment: true}, path)
+	if err != nil {
+		return err
+	}
+
+	update(f)
+
+	buf := new(bytes.Buffer)
+	if _, err = f.WriteTo(buf); err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err = tmp.Write(buf.Bytes()); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Chmod(0600); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err = tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,101 @@
+package authmate
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteCredentials(t *testing.T) {
+	ir := &issuingResult{AccessKeyID: "key", SecretAccessKey: "secret"}
+	creds := clientCredentials{AccessKeyID: "key", SecretAccessKey: "secret", ProfileSuffix: "oid"}
+
+	t.Run("env", func(t *testing.T) {
+		buf := new(bytes.Buffer)
+		o := &CredentialsOutput{Format: OutputEnv, Endpoint: "http://localhost:8080", Region: "ru"}
+		require.NoError(t, o.writeCredentials(buf, ir, creds))
+		require.Equal(t, "export AWS_ACCESS_KEY_ID=key\n"+
+			"export AWS_SECRET_ACCESS_KEY=secret\n"+
+			"export AWS_DEFAULT_REGION=ru\n"+
+			"export AWS_ENDPOINT_URL=http://localhost:8080\n", buf.String())
+	})
+
+	t.Run("aws profile", func(t *testing.T) {
+		dir := t.TempDir()
+		o := &CredentialsOutput{
+			Format:                OutputAWSProfile,
+			Profile:               "neofs",
+			Endpoint:              "http://localhost:8080",
+			AwsCliCredentialsFile: filepath.Join(dir, "credentials"),
+			AwsCliConfigFile:      filepath.Join(dir, "config"),
+		}
+		require.NoError(t, os.WriteFile(o.AwsCliCredentialsFile,
+			[]byte("[default]\naws_access_key_id = other\n\n[neofs]\naws_access_key_id = old\nstale = value\n"), 0600))
+		require.NoError(t, os.WriteFile(o.AwsCliConfigFile,
+			[]byte("[profile neofs]\noutput = json\n"), 0600))
+
+		// writing twice must not duplicate the profile
+		for i := 0; i < 2; i++ {
+			require.NoError(t, o.writeCredentials(new(bytes.Buffer), ir, creds))
+		}
+
+		data, err := os.ReadFile(o.AwsCliCredentialsFile)
+		require.NoError(t, err)
+		require.Equal(t, "[default]\naws_access_key_id = other\n\n"+
+			"[neofs]\naws_access_key_id     = key\naws_secret_access_key = secret\n\n", string(data))
+
+		data, err = os.ReadFile(o.AwsCliConfigFile)
+		require.NoError(t, err)
+		require.Equal(t, "[profile neofs]\noutput       = json\nendpoint_url = http://localhost:8080\n\n", string(data))
+
+		info, err := os.Stat(o.AwsCliCredentialsFile)
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	})
+
+	t.Run("default profile names", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "credentials")
+		o := &CredentialsOutput{AwsCliCredentialsFile: file}
+		require.Equal(t, defaultProfile, o.profileName(file, creds))
+		require.NoError(t, o.writeCredentials(new(bytes.Buffer), ir, creds))
+		require.Equal(t, "authmate_cred_oid", o.profileName(file, creds))
+	})
+
+	t.Run("rclone", func(t *testing.T) {
+		o := &CredentialsOutput{
+			Format:     OutputRclone,
+			Profile:    "neofs",
+			Endpoint:   "https://s3.neofs.example",
+			ConfigFile: filepath.Join(t.TempDir(), "rclone", "rclone.conf"),
+		}
+		require.NoError(t, o.writeCredentials(new(bytes.Buffer), ir, creds))
+
+		data, err := os.ReadFile(o.ConfigFile)
+		require.NoError(t, err)
+		require.Contains(t, string(data), "[neofs]\n")
+		require.Contains(t, string(data), "type              = s3\n")
+		require.Contains(t, string(data), "endpoint          = https://s3.neofs.example\n")
+	})
+
+	t.Run("s3cmd", func(t *testing.T) {
+		o := &CredentialsOutput{
+			Format:     OutputS3cmd,
+			Endpoint:   "https://s3.neofs.example",
+			ConfigFile: filepath.Join(t.TempDir(), ".s3cfg"),
+		}
+		require.NoError(t, os.WriteFile(o.ConfigFile, []byte("[default]\nsignature_v2 = False\n"), 0600))
+		require.NoError(t, o.writeCredentials(new(bytes.Buffer), ir, creds))
+
+		data, err := os.ReadFile(o.ConfigFile)
+		require.NoError(t, err)
+		require.Contains(t, string(data), "signature_v2 = False\n")
+		require.Contains(t, string(data), "host_base    = s3.neofs.example\n")
+		require.Contains(t, string(data), "use_https    = True\n")
+
+		o.Endpoint = "s3.neofs.example"
+		require.Error(t, o.writeCredentials(new(bytes.Buffer), ir, creds))
+	})
+}
@@ -0,0 +1,212 @@
+package authmate
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nspcc-dev/neofs-api-go/pkg/acl/eacl"
+	"github.com/nspcc-dev/neofs-api-go/pkg/container"
+	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/nspcc-dev/neofs-api-go/pkg/owner"
+	"github.com/nspcc-dev/neofs-api-go/pkg/session"
+	"gopkg.in/yaml.v2"
+)
+
+type (
+	// Policy is a high-level description of the access granted by a secret.
+	// It's compiled into eACL table of the bearer token and context of
+	// the session token.
+	Policy struct {
+		Rules []PolicyRule `yaml:"rules"`
+		// AllowCreateBucket adds session token allowing to create containers.
+		AllowCreateBucket bool `yaml:"allow_create_bucket"`
+	}
+
+	// PolicyRule grants access to the bucket.
+	PolicyRule struct {
+		// Bucket is a bucket name, a container ID or "*" for any bucket.
+		Bucket string `yaml:"bucket"`
+		// Prefix of object keys, eACL can't match it, so it must be empty.
+		Prefix     string `yaml:"prefix"`
+		Access     Access `yaml:"access"`
+		DenyDelete bool   `yaml:"deny_delete"`
+	}
+
+	// Access is a level of access to the bucket objects.
+	Access string
+)
+
+// Access levels.
+const (
+	AccessReadOnly  Access = "read-only"
+	AccessReadWrite Access = "read-write"
+	AccessWriteOnly Access = "write-only"
+	AccessListOnly  Access = "list-only"
+)
+
+// AnyBucket is a bucket of the rule applied to all buckets.
+const AnyBucket = "*"
+
+var (
+	allOperations = []eacl.Operation{eacl.OperationGet, eacl.OperationHead, eacl.OperationPut,
+		eacl.OperationDelete, eacl.OperationSearch, eacl.OperationRange, eacl.OperationRangeHash}
+
+	// accessOperations are the NeoFS operations the gateway needs to serve
+	// S3 requests of the access level. Writing and listing require
+	// HEAD and SEARCH to look up object versions.
+	accessOperations = map[Access][]eacl.Operation{
+		AccessReadOnly:  {eacl.OperationGet, eacl.OperationHead, eacl.OperationSearch, eacl.OperationRange, eacl.OperationRangeHash},
+		AccessReadWrite: allOperations,
+		AccessWriteOnly: {eacl.OperationHead, eacl.OperationPut, eacl.OperationDelete, eacl.OperationSearch},
+		AccessListOnly:  {eacl.OperationHead, eacl.OperationSearch},
+	}
+)
+
+// ParsePolicy parses YAML (or JSON) policy and checks it.
+func ParsePolicy(data []byte) (*Policy, error) {
+	p := new(Policy)
+	if err := yaml.UnmarshalStrict(data, p); err != nil {
+		return nil, fmt.Errorf("couldn't parse policy: %w", err)
+	}
+
+	for i, r := range p.Rules {
+		if r.Bucket == "" {
+			return nil, fmt.Errorf("rule %d: bucket is not set", i+1)
+		}
+		if r.Prefix != "" {
+			return nil, fmt.Errorf("rule %d: prefixes are not supported, eACL can't match a part of object key", i+1)
+		}
+		if _, ok := accessOperations[r.Access]; !ok {
+			return nil, fmt.Errorf("rule %d: unknown access '%s'", i+1, r.Access)
+		}
+	}
+
+	return p, nil
+}
+
+// buildTable makes eACL table of the policy for the bearer token. Rules of
+// certain buckets go first and rules of any bucket go after them, so every
+// request is matched by the most specific rule. The last records deny
+// everything else.
+func (p *Policy) buildTable(containers map[string]*cid.ID) *eacl.Table {
+	table := eacl.NewTable()
+
+	var bucketIDs []*cid.ID
+	for _, r := range p.Rules {
+		if r.Bucket != AnyBucket {
+			bucketIDs = append(bucketIDs, containers[r.Bucket])
+		}
+	}
+
+	// table of the single bucket is bound to its container
+	if len(bucketIDs) == len(p.Rules) && len(bucketIDs) > 0 {
+		single := true
+		for _, id := range bucketIDs {
+			single = single && id.Equal(bucketIDs[0])
+		}
+		if single {
+			table.SetCID(bucketIDs[0])
+		}
+	}
+
+	for _, anyBucket := range []bool{false, true} {
+		for _, r := range p.Rules {
+			if (r.Bucket == AnyBucket) != anyBucket {
+				continue
+			}
+			allowed := r.operations()
+			for _, op := range allOperations {
+				action := eacl.ActionDeny
+				if _, ok := allowed[op]; ok {
+					action = eacl.ActionAllow
+				}
+				record := eacl.CreateRecord(action, op)
+				if !anyBucket {
+					record.AddObjectContainerIDFilter(eacl.MatchStringEqual, containers[r.Bucket])
+				}
+				eacl.AddFormedTarget(record, eacl.RoleOthers)
+				table.AddRecord(record)
+			}
+		}
+	}
+
+	for _, op := range allOperations {
+		record := eacl.CreateRecord(eacl.ActionDeny, op)
+		eacl.AddFormedTarget(record, eacl.RoleOthers)
+		table.AddRecord(record)
+	}
+
+	return table
+}
+
+func (r PolicyRule) operations() map[eacl.Operation]struct{} {
+	ops := make(map[eacl.Operation]struct{})
+	for _, op := range accessOperations[r.Access] {
+		ops[op] = struct{}{}
+	}
+	if r.DenyDelete {
+		delete(ops, eacl.OperationDelete)
+	}
+	return ops
+}
+
+// buildContexts makes contexts of the session tokens, nil is returned if
+// the policy doesn't need session tokens. Bucket creation sets its eACL, so
+// it needs both PUT and SETEACL.
+func (p *Policy) buildContexts() []*session.ContainerContext {
+	if !p.AllowCreateBucket {
+		return nil
+	}
+
+	return newContexts((*session.ContainerContext).ForPut, (*session.ContainerContext).ForSetEACL)
+}
+
+// resolveBuckets returns IDs of the containers of the policy rules. Bucket
+// names are looked up among the containers of the owner.
+func (a *Agent) resolveBuckets(ctx context.Context, oid *owner.ID, p *Policy) (map[string]*cid.ID, error) {
+	res := make(map[string]*cid.ID)
+
+	var names []string
+	for _, r := range p.Rules {
+		if r.Bucket == AnyBucket {
+			continue
+		}
+		id := cid.New()
+		if err := id.Parse(r.Bucket); err == nil {
+			res[r.Bucket] = id
+			continue
+		}
+		names = append(names, r.Bucket)
+	}
+
+	if len(names) == 0 {
+		return res, nil
+	}
+
+	ids, err := a.pool.ListContainers(ctx, oid)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't list containers: %w", err)
+	}
+
+	for _, id := range ids {
+		cnr, err := a.pool.GetContainer(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get container %s: %w", id, err)
+		}
+		for _, attr := range cnr.Attributes() {
+			if attr.Key() == container.AttributeName {
+				if _, ok := res[attr.Value()]; !ok {
+					res[attr.Value()] = id
+				}
+			}
+		}
+	}
+
+	for _, name := range names {
+		if _, ok := res[name]; !ok {
+			return nil, fmt.Errorf("bucket '%s' not found", name)
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,126 @@
+package authmate
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-api-go/pkg/acl/eacl"
+	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePolicy(t *testing.T) {
+	p, err := ParsePolicy([]byte(`
+allow_create_bucket: true
+rules:
+  - bucket: photos
+    access: read-write
+    deny_delete: true
+  - bucket: "*"
+    access: list-only
+`))
+	require.NoError(t, err)
+	require.Equal(t, &Policy{
+		AllowCreateBucket: true,
+		Rules: []PolicyRule{
+			{Bucket: "photos", Access: AccessReadWrite, DenyDelete: true},
+			{Bucket: AnyBucket, Access: AccessListOnly},
+		},
+	}, p)
+
+	for _, tc := range []string{
+		"rules: [{access: read-only}]",
+		"rules: [{bucket: photos, access: everything}]",
+		"rules: [{bucket: photos, prefix: cats/, access: read-only}]",
+		"rules: [{bucket: photos, acess: read-only}]",
+	} {
+		_, err = ParsePolicy([]byte(tc))
+		require.Error(t, err, tc)
+	}
+}
+
+func TestPolicyBuildTable(t *testing.T) {
+	photos := cid.New()
+	photos.SetSHA256(sha256.Sum256([]byte("photos")))
+
+	p := &Policy{Rules: []PolicyRule{
+		{Bucket: AnyBucket, Access: AccessListOnly},
+		{Bucket: "photos", Access: AccessReadWrite, DenyDelete: true},
+	}}
+
+	table := p.buildTable(map[string]*cid.ID{"photos": photos})
+	require.Nil(t, table.CID())
+
+	records := table.Records()
+	require.Len(t, records, 3*len(allOperations))
+
+	// rules of certain buckets go first
+	for i, op := range allOperations {
+		r := records[i]
+		require.Equal(t, op, r.Operation())
+		if op == eacl.OperationDelete {
+			require.Equal(t, eacl.ActionDeny, r.Action())
+		} else {
+			require.Equal(t, eacl.ActionAllow, r.Action())
+		}
+		require.Len(t, r.Filters(), 1)
+		require.Equal(t, photos.String(), r.Filters()[0].Value())
+		require.Equal(t, eacl.RoleOthers, r.Targets()[0].Role())
+	}
+
+	for i, op := range allOperations {
+		r := records[len(allOperations)+i]
+		require.Equal(t, op, r.Operation())
+		if op == eacl.OperationHead || op == eacl.OperationSearch {
+			require.Equal(t, eacl.ActionAllow, r.Action())
+		} else {
+			require.Equal(t, eacl.ActionDeny, r.Action())
+		}
+		require.Empty(t, r.Filters())
+	}
+
+	for _, r := range records[2*len(allOperations):] {
+		require.Equal(t, eacl.ActionDeny, r.Action())
+		require.Empty(t, r.Filters())
+	}
+
+	t.Run("single bucket", func(t *testing.T) {
+		p := &Policy{Rules: []PolicyRule{{Bucket: "photos", Access: AccessReadOnly}}}
+		require.True(t, photos.Equal(p.buildTable(map[string]*cid.ID{"photos": photos}).CID()))
+	})
+}
+
+func TestPolicyBuildContexts(t *testing.T) {
+	require.Nil(t, (&Policy{}).buildContexts())
+
+	sessionCtxs := (&Policy{AllowCreateBucket: true}).buildContexts()
+	require.Len(t, sessionCtxs, 2)
+	require.True(t, sessionCtxs[0].IsForPut())
+	require.True(t, sessionCtxs[1].IsForSetEACL())
+	for _, sessionCtx := range sessionCtxs {
+		require.Nil(t, sessionCtx.Container())
+	}
+}
+
+func TestBuildContexts(t *testing.T) {
+	sessionCtxs, err := buildContexts(nil)
+	require.NoError(t, err)
+	require.Len(t, sessionCtxs, 3)
+	require.True(t, sessionCtxs[0].IsForPut())
+	require.True(t, sessionCtxs[1].IsForDelete())
+	require.True(t, sessionCtxs[2].IsForSetEACL())
+
+	sessionCtxs, err = buildContexts([]byte(`{"verb":"DELETE","wildcard":true,"containerID":null}`))
+	require.NoError(t, err)
+	require.Len(t, sessionCtxs, 1)
+	require.True(t, sessionCtxs[0].IsForDelete())
+
+	sessionCtxs, err = buildContexts([]byte(` [{"verb":"PUT","wildcard":true},{"verb":"SETEACL","wildcard":true}]`))
+	require.NoError(t, err)
+	require.Len(t, sessionCtxs, 2)
+	require.True(t, sessionCtxs[0].IsForPut())
+	require.True(t, sessionCtxs[1].IsForSetEACL())
+
+	_, err = buildContexts([]byte(`[{"verb":`))
+	require.Error(t, err)
+}
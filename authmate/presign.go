@@ -0,0 +1,68 @@
+package authmate
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+)
+
+// PresignOptions contains options for passing to GeneratePresignedURL function.
+type PresignOptions struct {
+	Method   string
+	Endpoint string
+	Bucket   string
+	Key      string
+	Region   string
+	Lifetime time.Duration
+	// Credentials to sign the URL with.
+	Credentials *credentials.Credentials
+}
+
+// GeneratePresignedURL writes to io.Writer the URL of the object signed with
+// query parameters. It doesn't need a connection to NeoFS.
+func GeneratePresignedURL(w io.Writer, options *PresignOptions) error {
+	switch options.Method {
+	case http.MethodGet, http.MethodPut:
+	default:
+		return fmt.Errorf("unsupported method '%s'", options.Method)
+	}
+
+	if options.Lifetime <= 0 || options.Lifetime > auth.MaxPresignLifetime {
+		return fmt.Errorf("lifetime must be positive and not longer than %s", auth.MaxPresignLifetime)
+	}
+
+	if options.Bucket == "" || options.Key == "" {
+		return fmt.Errorf("bucket and key must be set")
+	}
+
+	u, err := url.Parse(options.Endpoint)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid endpoint '%s'", options.Endpoint)
+	}
+	// path-style addressing
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/" + options.Bucket + "/" + options.Key
+
+	req, err := http.NewRequest(options.Method, u.String(), nil)
+	if err != nil {
+		return fmt.Errorf("couldn't create request: %w", err)
+	}
+
+	err = auth.PresignRequest(options.Credentials, req, auth.PresignData{
+		Service:  "s3",
+		Region:   options.Region,
+		Lifetime: options.Lifetime,
+		SignTime: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't sign request: %w", err)
+	}
+
+	_, err = fmt.Fprintln(w, req.URL.String())
+	return err
+}
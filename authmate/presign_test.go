@@ -0,0 +1,42 @@
+package authmate
+
+import (
+	"bytes"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGeneratePresignedURL(t *testing.T) {
+	options := &PresignOptions{
+		Method:      "GET",
+		Endpoint:    "http://localhost:8084",
+		Bucket:      "bucket",
+		Key:         "path/to/object",
+		Region:      "us-east-1",
+		Lifetime:    time.Hour,
+		Credentials: credentials.NewStaticCredentials("key", "secret", ""),
+	}
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, GeneratePresignedURL(buf, options))
+
+	u, err := url.Parse(strings.TrimSpace(buf.String()))
+	require.NoError(t, err)
+	require.Equal(t, "localhost:8084", u.Host)
+	require.Equal(t, "/bucket/path/to/object", u.Path)
+	require.Equal(t, "3600", u.Query().Get("X-Amz-Expires"))
+	require.True(t, strings.HasPrefix(u.Query().Get("X-Amz-Credential"), "key/"))
+	require.NotEmpty(t, u.Query().Get("X-Amz-Signature"))
+
+	options.Method = "DELETE"
+	require.Error(t, GeneratePresignedURL(buf, options))
+
+	options.Method = "PUT"
+	options.Lifetime = 8 * 24 * time.Hour
+	require.Error(t, GeneratePresignedURL(buf, options))
+}
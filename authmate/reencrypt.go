@@ -0,0 +1,150 @@
+package authmate
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-api-go/pkg/acl/eacl"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"github.com/nspcc-dev/neofs-api-go/pkg/session"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
+	"go.uber.org/zap"
+)
+
+// ReencryptSecretOptions contains options for passing to Agent.ReencryptSecret method.
+type ReencryptSecretOptions struct {
+	SecretAddress string
+	// NeoFSKey is a key of the secret issuer, it signs the new tokens.
+	NeoFSKey *keys.PrivateKey
+	// GatePrivateKey is any key the secret is encrypted for.
+	GatePrivateKey  *keys.PrivateKey
+	GatesPublicKeys []*keys.PublicKey
+}
+
+// ReencryptSecret issues the tokens of the existing secret for the given gate
+// keys and puts them into a new access box next to the existing one. Objects
+// in NeoFS can't be changed, so the new box has another access key ID, while
+// the secret access key, rules, lifetime and container policies are kept. The
+// new box is marked as a replacement of the existing one, so gates resolve the
+// old access key ID to it.
+func (a *Agent) ReencryptSecret(ctx context.Context, w io.Writer, options *ReencryptSecretOptions) error {
+	address := object.NewAddress()
+	if err := address.Parse(options.SecretAddress); err != nil {
+		return fmt.Errorf("failed to parse secret address: %w", err)
+	}
+
+	box, err := tokens.New(a.pool, options.GatePrivateKey).GetAccessBox(ctx, address)
+	if err != nil {
+		return fmt.Errorf("failed to get access box: %w", err)
+	}
+
+	gate, err := box.GetTokens(options.GatePrivateKey)
+	if err != nil {
+		return fmt.Errorf("failed to decrypt tokens: %w", err)
+	}
+
+	oid, err := ownerIDFromNeoFSKey(options.NeoFSKey.PublicKey())
+	if err != nil {
+		return err
+	}
+
+	// tokens signed by another key are rejected by NeoFS
+	issuer := gate.BearerToken.Issuer()
+	if issuer == nil {
+		return fmt.Errorf("couldn't get issuer of the secret")
+	}
+	if !issuer.Equal(oid) {
+		return fmt.Errorf("secret was issued by %s, not by the owner of the wallet %s", issuer, oid)
+	}
+
+	gates, err := reissueTokens(options.NeoFSKey, gate, options.GatesPublicKeys)
+	if err != nil {
+		return err
+	}
+
+	newBox, secrets, err := accessbox.PackTokensWithAccessKey(gates, gate.AccessKey)
+	if err != nil {
+		return err
+	}
+	newBox.ContainerPolicy = box.ContainerPolicy
+
+	a.log.Info("store re-encrypted tokens into NeoFS",
+		zap.Stringer("owner_tkn", oid),
+		zap.Stringer("cid", address.ContainerID()))
+
+	newAddress, err := tokens.
+		New(a.pool, secrets.EphemeralKey).
+		PutReplacement(ctx, address, oid, newBox, options.GatesPublicKeys...)
+	if err != nil {
+		return fmt.Errorf("failed to put re-encrypted tokens: %w", err)
+	}
+
+	ir := &issuingResult{
+		AccessKeyID:         strings.ReplaceAll(newAddress.String(), "/", "0"),
+		SecretAccessKey:     gate.AccessKey,
+		OwnerPrivateKey:     hex.EncodeToString(secrets.EphemeralKey.Bytes()),
+		ContainerID:         newAddress.ContainerID().String(),
+		ReplacedAccessKeyID: strings.ReplaceAll(address.String(), "/", "0"),
+	}
+
+	a.log.Info("access key ID is replaced, the old one is resolved to the new box",
+		zap.String("replaced_access_key_id", ir.ReplacedAccessKeyID),
+		zap.String("access_key_id", ir.AccessKeyID))
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(ir)
+}
+
+// reissueTokens makes tokens with the rules and lifetime of the decrypted
+// ones for every gate key.
+func reissueTokens(key *keys.PrivateKey, gate *accessbox.GateData, gatesKeys []*keys.PublicKey) ([]*accessbox.GateData, error) {
+	body := gate.BearerToken.ToV2().GetBody()
+	bearerLifetime := lifetimeOptions{
+		Iat: body.GetLifetime().GetIat(),
+		Exp: body.GetLifetime().GetExp(),
+	}
+
+	table := eacl.NewTable()
+	if body.GetEACL() != nil {
+		table = eacl.NewTableFromV2(body.GetEACL())
+	}
+
+	bearerTokens, err := buildBearerTokens(key, table, bearerLifetime, gatesKeys)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build bearer tokens: %w", err)
+	}
+
+	gates := make([]*accessbox.GateData, len(gatesKeys))
+	for i, gateKey := range gatesKeys {
+		gates[i] = accessbox.NewGateData(gateKey, bearerTokens[i])
+	}
+
+	for _, tkn := range gate.SessionTokens {
+		sessionCtx, ok := tkn.Context().(*session.ContainerContext)
+		if !ok {
+			continue
+		}
+
+		sessionLifetime := lifetimeOptions{
+			Iat: tkn.Iat(),
+			Exp: tkn.Exp(),
+		}
+
+		sessionTokens, err := buildSessionTokens(key, tkn.OwnerID(), sessionLifetime, sessionCtx, gatesKeys)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build session tokens: %w", err)
+		}
+		for i, sessionToken := range sessionTokens {
+			gates[i].SessionTokens = append(gates[i].SessionTokens, sessionToken)
+		}
+	}
+
+	return gates, nil
+}
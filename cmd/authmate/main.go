@@ -5,12 +5,15 @@ import (
 	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
 	"github.com/nspcc-dev/neofs-s3-gw/authmate"
@@ -28,6 +31,11 @@ const (
 	poolRequestTimeout = 5 * time.Second
 	// a number of 15-second blocks in a month.
 	defaultLifetime = 172800
+	// defaultEpochDuration is used to estimate the time of tokens expiration.
+	defaultEpochDuration = 15 * time.Second
+	// defaultPresignLifetime is the lifetime of pre-signed URLs.
+	defaultPresignLifetime = time.Hour
+	defaultRegion          = "us-east-1"
 )
 
 var (
@@ -48,6 +56,23 @@ var (
 	lifetimeFlag           uint64
 	containerPolicies      string
 	awcCliCredFile         string
+	epochDurationFlag      time.Duration
+	jsonOutputFlag         bool
+	policyFlag             string
+	dryRunFlag             bool
+	outputFormatFlag       string
+	profileFlag            string
+	endpointFlag           string
+	regionFlag             string
+	awsCliConfigFile       string
+	clientConfigFile       string
+	methodFlag             string
+	bucketFlag             string
+	objectKeyFlag          string
+	secretAccessKeyFlag    string
+	presignLifetimeFlag    time.Duration
+	auditFilesFlag         cli.StringSlice
+	gatePublicKeyFlag      string
 )
 
 const (
@@ -130,6 +155,10 @@ func appCommands() []*cli.Command {
 	return []*cli.Command{
 		issueSecret(),
 		obtainSecret(),
+		inspectSecret(),
+		generatePresigned(),
+		reencryptSecret(),
+		verifyAudit(),
 	}
 }
 
@@ -167,14 +196,26 @@ func issueSecret() *cli.Command {
 			},
 			&cli.StringFlag{
 				Name:        "session-rules",
-				Usage:       "rules for session token as plain json string",
+				Usage:       "rules for session tokens as plain json string (a rule or a list of them, a token is created for each rule)",
 				Required:    false,
 				Destination: &contextRulesFlag,
 			},
+			&cli.StringFlag{
+				Name:        "policy",
+				Usage:       "path to yaml file with access policy, replaces bearer and session rules",
+				Required:    false,
+				Destination: &policyFlag,
+			},
+			&cli.BoolFlag{
+				Name:        "dry-run",
+				Usage:       "print the rules of the tokens without issuing the secret",
+				Required:    false,
+				Destination: &dryRunFlag,
+			},
 			&cli.StringSliceFlag{
 				Name:        "gate-public-key",
 				Usage:       "public 256r1 key of a gate (use flags repeatedly for multiple gates)",
-				Required:    true,
+				Required:    false,
 				Destination: &gatesPublicKeysFlag,
 			},
 			&cli.StringFlag{
@@ -212,14 +253,75 @@ func issueSecret() *cli.Command {
 			},
 			&cli.StringFlag{
 				Name:        "aws-cli-credentials",
-				Usage:       "path to the aws cli credential file",
+				Usage:       "path to the aws cli credential file (default: ~/.aws/credentials with aws-profile output)",
 				Required:    false,
 				Destination: &awcCliCredFile,
 			},
+			&cli.StringFlag{
+				Name:        "aws-cli-config",
+				Usage:       "path to the aws cli config file (default: ~/.aws/config with aws-profile output)",
+				Required:    false,
+				Destination: &awsCliConfigFile,
+			},
+			&cli.StringFlag{
+				Name:        "output",
+				Usage:       "output format of the credentials: json, env, aws-profile, rclone or s3cmd",
+				Required:    false,
+				Destination: &outputFormatFlag,
+				Value:       authmate.OutputJSON,
+			},
+			&cli.StringFlag{
+				Name:        "profile",
+				Usage:       "name of aws cli profile or rclone remote to create or replace",
+				Required:    false,
+				Destination: &profileFlag,
+			},
+			&cli.StringFlag{
+				Name:        "endpoint",
+				Usage:       "URL of the gateway to write into the client config",
+				Required:    false,
+				Destination: &endpointFlag,
+			},
+			&cli.StringFlag{
+				Name:        "region",
+				Usage:       "region to write into the client config",
+				Required:    false,
+				Destination: &regionFlag,
+			},
+			&cli.StringFlag{
+				Name:        "config-file",
+				Usage:       "path to the rclone or s3cmd config file (default: ~/.config/rclone/rclone.conf or ~/.s3cfg)",
+				Required:    false,
+				Destination: &clientConfigFile,
+			},
 		},
 		Action: func(c *cli.Context) error {
 			ctx, log := prepare()
 
+			output, err := credentialsOutput()
+			if err != nil {
+				return cli.Exit(err.Error(), 8)
+			}
+
+			if len(gatesPublicKeysFlag.Value()) == 0 && !dryRunFlag {
+				return cli.Exit("at least one gate public key must be set", 4)
+			}
+
+			var policy *authmate.Policy
+			if policyFlag != "" {
+				if eaclRulesFlag != "" || contextRulesFlag != "" || sessionTokenFlag {
+					return cli.Exit("policy can't be used with bearer and session rules, "+
+						"set allow_create_bucket in the policy to create session token", 7)
+				}
+				data, err := os.ReadFile(policyFlag)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("couldn't read policy: %s", err), 7)
+				}
+				if policy, err = authmate.ParsePolicy(data); err != nil {
+					return cli.Exit(err.Error(), 7)
+				}
+			}
+
 			password := wallet.GetPassword(viper.GetViper(), envWalletPassphrase)
 			key, err := wallet.GetKeyFromPath(walletPathFlag, accountAddressFlag, password)
 			if err != nil {
@@ -271,7 +373,9 @@ func issueSecret() *cli.Command {
 				ContainerPolicies:     policies,
 				SessionTkn:            sessionTokenFlag,
 				Lifetime:              lifetimeFlag,
-				AwsCliCredentialsFile: awcCliCredFile,
+				Output:                *output,
+				Policy:                policy,
+				DryRun:                dryRunFlag,
 			}
 
 			if err = agent.IssueSecret(ctx, os.Stdout, issueSecretOptions); err != nil {
@@ -283,6 +387,50 @@ func issueSecret() *cli.Command {
 	}
 }
 
+// credentialsOutput returns the output settings with default paths of
+// the client configs.
+func credentialsOutput() (*authmate.CredentialsOutput, error) {
+	if !authmate.ValidOutputFormat(outputFormatFlag) {
+		return nil, fmt.Errorf("unknown output format '%s'", outputFormatFlag)
+	}
+
+	output := &authmate.CredentialsOutput{
+		Format:                outputFormatFlag,
+		Profile:               profileFlag,
+		Endpoint:              endpointFlag,
+		Region:                regionFlag,
+		AwsCliCredentialsFile: awcCliCredFile,
+		AwsCliConfigFile:      awsCliConfigFile,
+		ConfigFile:            clientConfigFile,
+	}
+
+	var defaults map[*string]string
+	switch outputFormatFlag {
+	case authmate.OutputAWSProfile:
+		defaults = map[*string]string{
+			&output.AwsCliCredentialsFile: filepath.Join(".aws", "credentials"),
+			&output.AwsCliConfigFile:      filepath.Join(".aws", "config"),
+		}
+	case authmate.OutputRclone:
+		defaults = map[*string]string{&output.ConfigFile: filepath.Join(".config", "rclone", "rclone.conf")}
+	case authmate.OutputS3cmd:
+		defaults = map[*string]string{&output.ConfigFile: ".s3cfg"}
+	}
+
+	for path, def := range defaults {
+		if *path != "" {
+			continue
+		}
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("couldn't get home directory: %w", err)
+		}
+		*path = filepath.Join(home, def)
+	}
+
+	return output, nil
+}
+
 func parsePolicies(val string) (authmate.ContainerPolicies, error) {
 	if val == "" {
 		return nil, nil
@@ -399,6 +547,335 @@ func obtainSecret() *cli.Command {
 	return command
 }
 
+func inspectSecret() *cli.Command {
+	return &cli.Command{
+		Name:  "inspect-secret",
+		Usage: "Decrypt a secret from NeoFS network and show what it allows",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "peer",
+				Value:       "",
+				Usage:       "address of neofs peer to connect to",
+				Required:    true,
+				Destination: &peerAddressFlag,
+			},
+			&cli.StringFlag{
+				Name:        "gate-wallet",
+				Value:       "",
+				Usage:       "path to the gate wallet",
+				Required:    true,
+				Destination: &gateWalletPathFlag,
+			},
+			&cli.StringFlag{
+				Name:        "gate-address",
+				Value:       "",
+				Usage:       "address of gate wallet account",
+				Required:    false,
+				Destination: &gateAccountAddressFlag,
+			},
+			&cli.StringFlag{
+				Name:        "access-key-id",
+				Usage:       "access key id for s3",
+				Required:    true,
+				Destination: &accessKeyIDFlag,
+			},
+			&cli.DurationFlag{
+				Name:        "epoch-duration",
+				Usage:       "duration of NeoFS epoch to estimate the time of tokens expiration",
+				Required:    false,
+				Destination: &epochDurationFlag,
+				Value:       defaultEpochDuration,
+			},
+			&cli.BoolFlag{
+				Name:        "json",
+				Usage:       "print the result as json",
+				Required:    false,
+				Destination: &jsonOutputFlag,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx, log := prepare()
+
+			password := wallet.GetPassword(viper.GetViper(), envWalletGatePassphrase)
+			gateCreds, err := wallet.GetKeyFromPath(gateWalletPathFlag, gateAccountAddressFlag, password)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to load gate's private key: %s", err), 1)
+			}
+
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			client, err := createSDKClient(ctx, log, &gateCreds.PrivateKey, peerAddressFlag)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to create sdk client: %s", err), 2)
+			}
+
+			agent := authmate.New(log, client)
+
+			inspectSecretOptions := &authmate.InspectSecretOptions{
+				// access key id is "<cid>0<oid>", base58 doesn't contain zeroes
+				SecretAddress:  strings.ReplaceAll(accessKeyIDFlag, "0", "/"),
+				GatePrivateKey: gateCreds,
+				EpochDuration:  epochDurationFlag,
+				JSON:           jsonOutputFlag,
+			}
+
+			if err = agent.InspectSecret(ctx, os.Stdout, inspectSecretOptions); err != nil {
+				return cli.Exit(fmt.Sprintf("failed to inspect secret: %s", err), 3)
+			}
+
+			return nil
+		},
+	}
+}
+
+func generatePresigned() *cli.Command {
+	return &cli.Command{
+		Name:  "generate-presigned",
+		Usage: "Generate URL of an object signed with the credentials, no network access is needed",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "method",
+				Usage:       "HTTP method of the request: GET or PUT",
+				Required:    false,
+				Destination: &methodFlag,
+				Value:       http.MethodGet,
+			},
+			&cli.StringFlag{
+				Name:        "endpoint",
+				Usage:       "URL of the gateway",
+				Required:    true,
+				Destination: &endpointFlag,
+			},
+			&cli.StringFlag{
+				Name:        "bucket",
+				Usage:       "bucket of the object",
+				Required:    true,
+				Destination: &bucketFlag,
+			},
+			&cli.StringFlag{
+				Name:        "key",
+				Usage:       "key of the object",
+				Required:    true,
+				Destination: &objectKeyFlag,
+			},
+			&cli.DurationFlag{
+				Name:        "lifetime",
+				Usage:       "lifetime of the URL, 7 days at most",
+				Required:    false,
+				Destination: &presignLifetimeFlag,
+				Value:       defaultPresignLifetime,
+			},
+			&cli.StringFlag{
+				Name:        "region",
+				Usage:       "region to sign the URL for",
+				Required:    false,
+				Destination: &regionFlag,
+				Value:       defaultRegion,
+			},
+			&cli.StringFlag{
+				Name:        "access-key-id",
+				Usage:       "access key id for s3",
+				Required:    false,
+				EnvVars:     []string{"AWS_ACCESS_KEY_ID"},
+				Destination: &accessKeyIDFlag,
+			},
+			&cli.StringFlag{
+				Name:        "secret-access-key",
+				Usage:       "secret access key for s3",
+				Required:    false,
+				EnvVars:     []string{"AWS_SECRET_ACCESS_KEY"},
+				Destination: &secretAccessKeyFlag,
+			},
+			&cli.StringFlag{
+				Name:        "profile",
+				Usage:       "aws cli profile to take the credentials from if they're not set",
+				Required:    false,
+				Destination: &profileFlag,
+			},
+			&cli.StringFlag{
+				Name:        "aws-cli-credentials",
+				Usage:       "path to the aws cli credential file (default: ~/.aws/credentials)",
+				Required:    false,
+				Destination: &awcCliCredFile,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			var creds *credentials.Credentials
+			switch {
+			case accessKeyIDFlag != "" && secretAccessKeyFlag != "":
+				creds = credentials.NewStaticCredentials(accessKeyIDFlag, secretAccessKeyFlag, "")
+			case accessKeyIDFlag != "" || secretAccessKeyFlag != "":
+				return cli.Exit("both access key id and secret access key must be set", 1)
+			default:
+				// empty file name and profile make the provider use defaults of aws cli
+				creds = credentials.NewSharedCredentials(awcCliCredFile, profileFlag)
+			}
+
+			presignOptions := &authmate.PresignOptions{
+				Method:      strings.ToUpper(methodFlag),
+				Endpoint:    endpointFlag,
+				Bucket:      bucketFlag,
+				Key:         objectKeyFlag,
+				Region:      regionFlag,
+				Lifetime:    presignLifetimeFlag,
+				Credentials: creds,
+			}
+
+			if err := authmate.GeneratePresignedURL(os.Stdout, presignOptions); err != nil {
+				return cli.Exit(fmt.Sprintf("failed to generate pre-signed URL: %s", err), 2)
+			}
+
+			return nil
+		},
+	}
+}
+
+func reencryptSecret() *cli.Command {
+	return &cli.Command{
+		Name:  "reencrypt-secret",
+		Usage: "Issue tokens of an existing secret for new gate keys, the secret access key is kept",
+		Description: "NeoFS objects are immutable, so the tokens are put into a new box with a new access key ID. " +
+			"The new box replaces the existing one: gates resolve the old access key ID to it " +
+			"when they can't decrypt the existing box.",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:        "wallet",
+				Value:       "",
+				Usage:       "path to the wallet the secret was issued with",
+				Required:    true,
+				Destination: &walletPathFlag,
+			},
+			&cli.StringFlag{
+				Name:        "address",
+				Value:       "",
+				Usage:       "address of wallet account",
+				Required:    false,
+				Destination: &accountAddressFlag,
+			},
+			&cli.StringFlag{
+				Name:        "peer",
+				Value:       "",
+				Usage:       "address of neofs peer to connect to",
+				Required:    true,
+				Destination: &peerAddressFlag,
+			},
+			&cli.StringFlag{
+				Name:        "gate-wallet",
+				Value:       "",
+				Usage:       "path to the wallet of a gate the secret is encrypted for",
+				Required:    true,
+				Destination: &gateWalletPathFlag,
+			},
+			&cli.StringFlag{
+				Name:        "gate-address",
+				Value:       "",
+				Usage:       "address of gate wallet account",
+				Required:    false,
+				Destination: &gateAccountAddressFlag,
+			},
+			&cli.StringFlag{
+				Name:        "access-key-id",
+				Usage:       "access key id for s3",
+				Required:    true,
+				Destination: &accessKeyIDFlag,
+			},
+			&cli.StringSliceFlag{
+				Name:        "gate-public-key",
+				Usage:       "public 256r1 key of a gate to encrypt the secret for (use flags repeatedly for multiple gates)",
+				Required:    true,
+				Destination: &gatesPublicKeysFlag,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			ctx, log := prepare()
+
+			password := wallet.GetPassword(viper.GetViper(), envWalletPassphrase)
+			key, err := wallet.GetKeyFromPath(walletPathFlag, accountAddressFlag, password)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to load neofs private key: %s", err), 1)
+			}
+
+			password = wallet.GetPassword(viper.GetViper(), envWalletGatePassphrase)
+			gateCreds, err := wallet.GetKeyFromPath(gateWalletPathFlag, gateAccountAddressFlag, password)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to load gate's private key: %s", err), 1)
+			}
+
+			var gatesPublicKeys []*keys.PublicKey
+			for _, key := range gatesPublicKeysFlag.Value() {
+				gpk, err := keys.NewPublicKeyFromString(key)
+				if err != nil {
+					return cli.Exit(fmt.Sprintf("failed to load gate's public key: %s", err), 4)
+				}
+				gatesPublicKeys = append(gatesPublicKeys, gpk)
+			}
+
+			ctx, cancel := context.WithCancel(ctx)
+			defer cancel()
+
+			client, err := createSDKClient(ctx, log, &key.PrivateKey, peerAddressFlag)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to create sdk client: %s", err), 2)
+			}
+
+			agent := authmate.New(log, client)
+
+			reencryptSecretOptions := &authmate.ReencryptSecretOptions{
+				// access key id is "<cid>0<oid>", base58 doesn't contain zeroes
+				SecretAddress:   strings.ReplaceAll(accessKeyIDFlag, "0", "/"),
+				NeoFSKey:        key,
+				GatePrivateKey:  gateCreds,
+				GatesPublicKeys: gatesPublicKeys,
+			}
+
+			if err = agent.ReencryptSecret(ctx, os.Stdout, reencryptSecretOptions); err != nil {
+				return cli.Exit(fmt.Sprintf("failed to re-encrypt secret: %s", err), 3)
+			}
+
+			return nil
+		},
+	}
+}
+
+func verifyAudit() *cli.Command {
+	return &cli.Command{
+		Name:  "verify-audit",
+		Usage: "Verify the audit trail of a gate, no network access is needed",
+		Flags: []cli.Flag{
+			&cli.StringSliceFlag{
+				Name:        "file",
+				Usage:       "path to the audit object downloaded from the audit container (use flags repeatedly for multiple objects)",
+				Required:    true,
+				Destination: &auditFilesFlag,
+			},
+			&cli.StringFlag{
+				Name:        "gate-public-key",
+				Usage:       "public 256r1 key of the gate that signed the records",
+				Required:    true,
+				Destination: &gatePublicKeyFlag,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			gatePublicKey, err := keys.NewPublicKeyFromString(gatePublicKeyFlag)
+			if err != nil {
+				return cli.Exit(fmt.Sprintf("failed to load gate's public key: %s", err), 1)
+			}
+
+			verifyAuditOptions := &authmate.VerifyAuditOptions{
+				Files:         auditFilesFlag.Value(),
+				GatePublicKey: gatePublicKey,
+			}
+
+			if err = authmate.VerifyAudit(os.Stdout, verifyAuditOptions); err != nil {
+				return cli.Exit(fmt.Sprintf("audit trail verification failed: %s", err), 2)
+			}
+
+			return nil
+		},
+	}
+}
+
 func createSDKClient(ctx context.Context, log *zap.Logger, key *ecdsa.PrivateKey, peerAddress string) (pool.Pool, error) {
 	log.Debug("prepare connection pool")
 
@@ -6,13 +6,23 @@ import (
 	"math"
 	"net"
 	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
+	"github.com/nspcc-dev/neofs-api-go/pkg/netmap"
 	"github.com/nspcc-dev/neofs-s3-gw/api"
 	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
 	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
 	"github.com/nspcc-dev/neofs-s3-gw/api/handler"
 	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/audit"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/tracing"
 	"github.com/nspcc-dev/neofs-s3-gw/internal/wallet"
 	"github.com/nspcc-dev/neofs-sdk-go/policy"
 	"github.com/nspcc-dev/neofs-sdk-go/pool"
@@ -23,15 +33,30 @@ import (
 type (
 	// App is the main application structure.
 	App struct {
-		pool pool.Pool
+		pool *reloadablePool
 		ctr  auth.Center
 		log  *zap.Logger
+		lvl  zap.AtomicLevel
 		cfg  *viper.Viper
-		tls  *tlsConfig
 		obj  layer.Client
 		api  api.Handler
+		hc   *healthChecker
+		key  *keys.PrivateKey
 
-		maxClients api.MaxClients
+		stopTracing func(context.Context) error
+		audit       *audit.Trail
+
+		// mu guards cfg and cacheCfg changed on reload.
+		mu         sync.RWMutex
+		handlerCfg *handler.Config
+		cacheCfg   *layer.CacheConfig
+		peers      []peer
+
+		maxClients  api.MaxClients
+		rateLimiter api.RateLimiter
+		authorizer  api.Authorizer
+		accessLog   mux.MiddlewareFunc
+		inFlight    *api.InFlight
 
 		webDone chan struct{}
 		wrkDone chan struct{}
@@ -43,80 +68,84 @@ type (
 	}
 )
 
-func newApp(ctx context.Context, l *zap.Logger, v *viper.Viper) *App {
+func newApp(ctx context.Context, l *zap.Logger, lvl zap.AtomicLevel, v *viper.Viper) *App {
 	var (
-		conns  pool.Pool
+		conns  *reloadablePool
 		key    *keys.PrivateKey
 		err    error
-		tls    *tlsConfig
 		caller api.Handler
 		ctr    auth.Center
 		obj    layer.Client
 
 		poolPeers = fetchPeers(l, v)
 
-		reBalance  = defaultRebalanceTimer
-		conTimeout = defaultConnectTimeout
 		reqTimeout = defaultRequestTimeout
+		hcInterval = defaultHealthcheckInterval
 
-		maxClientsCount    = defaultMaxClientsCount
-		maxClientsDeadline = defaultMaxClientsDeadline
+		maxClientsCount, maxClientsDeadline = getMaxClientsOptions(v)
 	)
 
-	if v := v.GetDuration(cfgConnectTimeout); v > 0 {
-		conTimeout = v
-	}
-
 	if v := v.GetDuration(cfgRequestTimeout); v > 0 {
 		reqTimeout = v
 	}
 
-	if v := v.GetInt(cfgMaxClientsCount); v > 0 {
-		maxClientsCount = v
-	}
-
-	if v := v.GetDuration(cfgMaxClientsDeadline); v > 0 {
-		maxClientsDeadline = v
-	}
-
-	if v := v.GetDuration(cfgRebalanceTimer); v > 0 {
-		reBalance = v
-	}
-
-	password := wallet.GetPassword(v, cfgWalletPassphrase)
-	if key, err = wallet.GetKeyFromPath(v.GetString(cfgWallet), v.GetString(cfgAddress), password); err != nil {
-		l.Fatal("could not load NeoFS private key", zap.Error(err))
+	if v := v.GetDuration(cfgHealthcheckInterval); v > 0 {
+		hcInterval = v
 	}
 
-	if v.IsSet(cfgTLSKeyFile) && v.IsSet(cfgTLSCertFile) {
-		tls = &tlsConfig{
-			KeyFile:  v.GetString(cfgTLSKeyFile),
-			CertFile: v.GetString(cfgTLSCertFile),
+	memoryBackend := useMemoryBackend(v, l)
+	if memoryBackend {
+		key = getMemoryBackendKey(v, l)
+	} else {
+		password := wallet.GetPassword(v, cfgWalletPassphrase)
+		if key, err = wallet.GetKeyFromPath(v.GetString(cfgWallet), v.GetString(cfgAddress), password); err != nil {
+			l.Fatal("could not load NeoFS private key", zap.Error(err))
 		}
 	}
 
 	l.Info("using credentials",
 		zap.String("NeoFS", hex.EncodeToString(key.PublicKey().Bytes())))
 
-	opts := &pool.BuilderOptions{
-		Key:                     &key.PrivateKey,
-		NodeConnectionTimeout:   conTimeout,
-		NodeRequestTimeout:      reqTimeout,
-		ClientRebalanceInterval: reBalance,
-		SessionExpirationEpoch:  math.MaxUint64,
-	}
-	conns, err = poolPeers.Build(ctx, opts)
-	if err != nil {
+	maxClients := api.NewMaxClientsMiddleware(maxClientsCount, maxClientsDeadline)
+	hc := newHealthChecker(l, &key.PrivateKey, fetchPeerAddresses(v), hcInterval, reqTimeout)
+	hc.serving = maxClients
+
+	if memoryBackend {
+		memPool := newMemoryPool(l, key)
+		conns = newReloadablePool(l, &connections{Pool: memPool})
+		hc.probes = []peerProbe{{address: backendMemory, cli: memPool}}
+	} else if conns, err = buildPool(ctx, l, v, poolPeers, key); err != nil {
 		l.Fatal("failed to create connection pool", zap.Error(err))
 	}
 
+	stopTracing := initTracing(ctx, v, l)
+
+	// NeoFS calls are traced and measured regardless of pool reloads
+	tracedConns := tracing.WrapPool(conns)
+	if v.GetBool(cfgEnableMetrics) {
+		tracedConns = metrics.WrapPool(tracedConns)
+	}
+
 	cacheCfg := getCacheOptions(v, l)
+	auditTrail := newAuditTrail(ctx, v, l, tracedConns, key)
+
+	layerCfg := &layer.Config{
+		Caches:          cacheCfg,
+		SessionFallback: getSessionFallback(v, l),
+	}
+	if auditTrail != nil {
+		layerCfg.Audit = auditTrail
+	}
 
 	// prepare object layer
-	obj = layer.NewLayer(l, conns, cacheCfg)
+	obj = layer.NewLayer(l, tracedConns, layerCfg)
 
 	// prepare auth center
-	ctr = auth.New(conns, key)
+	ctr = auth.New(tracedConns, key, getAuthOptions(v, l))
+
+	if memoryBackend {
+		issueMemoryCredentials(ctx, l, os.Stderr, tracedConns, key)
+	}
 
 	handlerOptions := getHandlerOptions(v, l)
 
@@ -128,16 +157,81 @@ func newApp(ctx context.Context, l *zap.Logger, v *viper.Viper) *App {
 		ctr:  ctr,
 		pool: conns,
 		log:  l,
+		lvl:  lvl,
 		cfg:  v,
 		obj:  obj,
-		tls:  tls,
 		api:  caller,
+		hc:   hc,
+		key:  key,
+
+		stopTracing: stopTracing,
+		audit:       auditTrail,
+
+		handlerCfg: handlerOptions,
+		cacheCfg:   cacheCfg,
+		peers:      poolPeers,
 
 		webDone: make(chan struct{}, 1),
 		wrkDone: make(chan struct{}, 1),
 
-		maxClients: api.NewMaxClientsMiddleware(maxClientsCount, maxClientsDeadline),
+		maxClients:  maxClients,
+		rateLimiter: api.NewRateLimiter(getRateLimits(v, l)),
+		authorizer:  newAuthorizer(v, l),
+		accessLog:   newAccessLog(v, l),
+		inFlight:    api.NewInFlight(),
+	}
+}
+
+func buildPool(ctx context.Context, l *zap.Logger, v *viper.Viper, peers []peer, key *keys.PrivateKey) (*reloadablePool, error) {
+	conns, err := buildConnections(ctx, v, peers, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return newReloadablePool(l, conns), nil
+}
+
+// buildConnections creates connection pool which works until its cancel
+// function is called.
+func buildConnections(ctx context.Context, v *viper.Viper, peers []peer, key *keys.PrivateKey) (*connections, error) {
+	var (
+		reBalance  = defaultRebalanceTimer
+		conTimeout = defaultConnectTimeout
+		reqTimeout = defaultRequestTimeout
+	)
+
+	if v := v.GetDuration(cfgConnectTimeout); v > 0 {
+		conTimeout = v
+	}
+
+	if v := v.GetDuration(cfgRequestTimeout); v > 0 {
+		reqTimeout = v
+	}
+
+	if v := v.GetDuration(cfgRebalanceTimer); v > 0 {
+		reBalance = v
+	}
+
+	opts := &pool.BuilderOptions{
+		Key:                     &key.PrivateKey,
+		NodeConnectionTimeout:   conTimeout,
+		NodeRequestTimeout:      reqTimeout,
+		ClientRebalanceInterval: reBalance,
+		SessionExpirationEpoch:  math.MaxUint64,
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	conns, err := newNodePool(ctx, peers, opts, client.New)
+	if err != nil {
+		cancel()
+		return nil, err
 	}
+
+	return &connections{
+		Pool:    conns,
+		cancel:  cancel,
+		clients: conns.clients(),
+	}, nil
 }
 
 // Wait waits for application to finish.
@@ -146,74 +240,174 @@ func (a *App) Wait() {
 
 	<-a.webDone // wait for web-server to be stopped
 
+	if a.audit != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+		if err := a.audit.Seal(ctx); err != nil {
+			a.log.Error("couldn't seal audit records", zap.Error(err))
+		}
+		cancel()
+		if err := a.audit.Close(); err != nil {
+			a.log.Error("couldn't close audit journal", zap.Error(err))
+		}
+	}
+
+	if a.stopTracing != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+		if err := a.stopTracing(ctx); err != nil {
+			a.log.Warn("could not flush traces", zap.Error(err))
+		}
+		cancel()
+	}
+
 	a.log.Info("application finished")
 }
 
-// Server runs HTTP server to handle S3 API requests.
+// Server runs HTTP servers to handle S3 API requests.
 func (a *App) Server(ctx context.Context) {
 	var (
-		err  error
-		lis  net.Listener
-		lic  net.ListenConfig
-		srv  = new(http.Server)
-		addr = a.cfg.GetString(cfgListenAddress)
+		servers  = fetchServers(a.cfg)
+		domains  = fetchDomains(a.cfg)
+		interval = defaultCertReloadInterval
+		srvs     = make([]*http.Server, 0, len(servers))
 	)
 
-	if lis, err = lic.Listen(ctx, "tcp", addr); err != nil {
-		a.log.Fatal("could not prepare listener",
-			zap.Error(err))
+	if v := a.cfg.GetDuration(cfgTLSReloadInterval); v > 0 {
+		interval = v
+	}
+
+	go a.hc.Start(ctx)
+	go a.reconcileUsage(ctx)
+	if a.audit != nil {
+		go a.audit.Run(ctx, a.cfg.GetDuration(cfgAuditSealInterval))
 	}
+	adminDone := a.startAdmin(ctx)
 
 	router := newS3Router()
 
 	// Attach app-specific routes:
-	//	attachHealthy(router, a.cli)
-	attachMetrics(router, a.cfg, a.log)
+	attachHealthy(router, drainAwareHealthy{Healthy: a.hc, inFlight: a.inFlight}, a.obj)
+	attachMetrics(router, a.cfg, a.log, a.obj)
 	attachProfiler(router, a.cfg, a.log)
 
 	// Attach S3 API:
-	domains := fetchDomains(a.cfg)
 	a.log.Info("fetch domains, prepare to use API",
 		zap.Strings("domains", domains))
-	api.Attach(router, domains, a.maxClients, a.api, a.ctr, a.log)
+	mws := []mux.MiddlewareFunc{a.inFlight.Middleware}
+	if a.accessLog != nil {
+		// access log goes first to see requests rejected while draining
+		mws = append([]mux.MiddlewareFunc{a.accessLog}, mws...)
+	}
+	api.Attach(router, domains, a.maxClients, a.rateLimiter, a.authorizer, a.api, a.ctr, a.log, mws...)
 
-	// Use mux.Router as http.Handler
-	srv.Handler = router
-	srv.ErrorLog = zap.NewStdLog(a.log)
+	for _, info := range servers {
+		srv, lis := a.prepareServer(ctx, info, domains, interval)
 
-	go func() {
-		a.log.Info("starting server",
-			zap.String("bind", addr))
+		// Use mux.Router as http.Handler
+		srv.Handler = router
+		srv.ErrorLog = zap.NewStdLog(a.log)
+		srvs = append(srvs, srv)
 
-		switch a.tls {
-		case nil:
-			if err = srv.Serve(lis); err != nil && err != http.ErrServerClosed {
-				a.log.Fatal("listen and serve",
-					zap.Error(err))
+		go func(info serverInfo) {
+			var err error
+
+			a.log.Info("starting server",
+				zap.String("bind", info.Address),
+				zap.Bool("tls", info.TLS()))
+
+			if info.TLS() {
+				// certificates are provided by tls.Config
+				err = srv.ServeTLS(lis, "", "")
+			} else {
+				err = srv.Serve(lis)
 			}
-		default:
-			a.log.Info("using certificate",
-				zap.String("key", a.tls.KeyFile),
-				zap.String("cert", a.tls.CertFile))
 
-			if err = srv.ServeTLS(lis, a.tls.CertFile, a.tls.KeyFile); err != nil && err != http.ErrServerClosed {
+			if err != nil && err != http.ErrServerClosed {
 				a.log.Fatal("listen and serve",
+					zap.String("bind", info.Address),
 					zap.Error(err))
 			}
-		}
-	}()
+		}(info)
+	}
 
 	<-ctx.Done()
 
 	ctx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
 	defer cancel()
 
-	a.log.Info("stopping server",
-		zap.Error(srv.Shutdown(ctx)))
+	for i := range srvs {
+		a.log.Info("stopping server",
+			zap.String("bind", servers[i].Address),
+			zap.Error(srvs[i].Shutdown(ctx)))
+	}
+	<-adminDone
 
 	close(a.webDone)
 }
 
+func (a *App) prepareServer(ctx context.Context, info serverInfo, domains []string, interval time.Duration) (*http.Server, net.Listener) {
+	var (
+		err error
+		lis net.Listener
+		lic net.ListenConfig
+		srv = new(http.Server)
+	)
+
+	if lis, err = lic.Listen(ctx, "tcp", info.Address); err != nil {
+		a.log.Fatal("could not prepare listener",
+			zap.String("bind", info.Address),
+			zap.Error(err))
+	}
+
+	if !info.TLS() {
+		if info.ClientCAFile != "" {
+			a.log.Warn("client CA is ignored for listener without TLS",
+				zap.String("bind", info.Address))
+		}
+		return srv, lis
+	}
+
+	store, err := newCertStore(a.log, info.Certificates)
+	if err != nil {
+		a.log.Fatal("could not load certificates",
+			zap.String("bind", info.Address),
+			zap.Error(err))
+	}
+
+	for _, cert := range info.Certificates {
+		a.log.Info("using certificate",
+			zap.String("bind", info.Address),
+			zap.String("key", cert.KeyFile),
+			zap.String("cert", cert.CertFile))
+	}
+
+	for _, domain := range domains {
+		if !store.Covers(domain) {
+			a.log.Warn("no certificate for domain, the default one will be used",
+				zap.String("bind", info.Address),
+				zap.String("domain", domain))
+		}
+	}
+
+	if srv.TLSConfig, err = store.tlsConfig(info.ClientCAFile); err != nil {
+		a.log.Fatal("could not prepare TLS config",
+			zap.String("bind", info.Address),
+			zap.Error(err))
+	}
+
+	go store.Start(ctx, interval)
+
+	return srv, lis
+}
+
+func getSessionFallback(v *viper.Viper, l *zap.Logger) layer.SessionFallback {
+	fallback := layer.SessionFallback(v.GetString(cfgContainerSessionFallback))
+	if !layer.ValidSessionFallback(fallback) {
+		l.Fatal("invalid container session fallback",
+			zap.String("value in config", string(fallback)))
+	}
+	return fallback
+}
+
 func getCacheOptions(v *viper.Viper, l *zap.Logger) *layer.CacheConfig {
 	cacheCfg := layer.CacheConfig{
 		ListObjectsLifetime: cache.DefaultObjectsListCacheLifetime,
@@ -262,28 +456,193 @@ func getCacheOptions(v *viper.Viper, l *zap.Logger) *layer.CacheConfig {
 				zap.Int("value in config", size),
 				zap.Int("default", cacheCfg.ListObjectsSize))
 		} else {
-			cacheCfg.Size = size
+			cacheCfg.ListObjectsSize = size
 		}
 	}
 
 	return &cacheCfg
 }
 
-func getHandlerOptions(v *viper.Viper, l *zap.Logger) *handler.Config {
+func getMaxClientsOptions(v *viper.Viper) (int, time.Duration) {
 	var (
-		cfg       handler.Config
-		err       error
-		policyStr = handler.DefaultPolicy
+		count    = defaultMaxClientsCount
+		deadline = defaultMaxClientsDeadline
 	)
 
+	if v := v.GetInt(cfgMaxClientsCount); v > 0 {
+		count = v
+	}
+
+	if v := v.GetDuration(cfgMaxClientsDeadline); v > 0 {
+		deadline = v
+	}
+
+	return count, deadline
+}
+
+func getRateLimits(v *viper.Viper, l *zap.Logger) *api.RateLimits {
+	cfg := &api.RateLimits{
+		Default:        getLimits(v, cfgRateLimitsDefault+"."),
+		SourceIP:       getLimits(v, cfgRateLimitsSourceIP+"."),
+		Overrides:      make(map[string]api.Limits),
+		TrustedProxies: getTrustedProxies(v, l),
+	}
+
+	for i := 0; ; i++ {
+		key := cfgRateLimitsOverrides + "." + strconv.Itoa(i) + "."
+		client := v.GetString(key + "key")
+		if client == "" {
+			break
+		}
+
+		cfg.Overrides[client] = getLimits(v, key)
+	}
+
+	return cfg
+}
+
+func getTrustedProxies(v *viper.Viper, l *zap.Logger) api.TrustedProxies {
+	proxies, err := api.ParseTrustedProxies(v.GetStringSlice(cfgTrustedProxies))
+	if err != nil {
+		l.Fatal("invalid trusted proxies", zap.Error(err))
+	}
+	return proxies
+}
+
+func getLimits(v *viper.Viper, prefix string) api.Limits {
+	return api.Limits{
+		RequestsPerSecond: v.GetFloat64(prefix + "requests_per_second"),
+		Burst:             v.GetInt(prefix + "burst"),
+		Concurrency:       v.GetInt(prefix + "concurrency"),
+		Bandwidth:         v.GetInt64(prefix + "bandwidth"),
+	}
+}
+
+func getDefaultPolicy(v *viper.Viper) (*netmap.PlacementPolicy, error) {
+	policyStr := handler.DefaultPolicy
 	if v.IsSet(cfgDefaultPolicy) {
 		policyStr = v.GetString(cfgDefaultPolicy)
 	}
 
-	if cfg.DefaultPolicy, err = policy.Parse(policyStr); err != nil {
+	return policy.Parse(policyStr)
+}
+
+func getHandlerOptions(v *viper.Viper, l *zap.Logger) *handler.Config {
+	var (
+		cfg handler.Config
+		err error
+	)
+
+	if cfg.DefaultPolicy, err = getDefaultPolicy(v); err != nil {
 		l.Fatal("couldn't parse container default policy",
 			zap.Error(err))
 	}
 
+	cfg.QuotaAdmins = make(map[string]struct{})
+	for _, key := range v.GetStringSlice(cfgQuotaAdminKeys) {
+		cfg.QuotaAdmins[key] = struct{}{}
+	}
+
+	return &cfg
+}
+
+func getAuthOptions(v *viper.Viper, l *zap.Logger) *auth.Config {
+	var (
+		cfg auth.Config
+		err error
+	)
+
+	if path := v.GetString(cfgClientCertMapping); path != "" {
+		if cfg.ClientCerts, err = auth.ReadCertMapping(path); err != nil {
+			l.Fatal("couldn't load client certificate mapping",
+				zap.Error(err))
+		}
+		l.Info("client certificate authentication enabled",
+			zap.String("mapping", path))
+	}
+
+	cfg.RetiredKeys = getRetiredKeys(v, l)
+
+	if cfg.SignatureV2 = v.GetBool(cfgSignatureV2Enabled); cfg.SignatureV2 {
+		l.Warn("legacy signature v2 authentication enabled")
+	}
+
 	return &cfg
 }
+
+// getRetiredKeys loads keys of the gateway used before rotation, they're
+// needed to decrypt access boxes issued for them.
+func getRetiredKeys(v *viper.Viper, l *zap.Logger) []*keys.PrivateKey {
+	var res []*keys.PrivateKey
+
+	for i := 0; ; i++ {
+		prefix := cfgWalletRetired + "." + strconv.Itoa(i) + "."
+		path := v.GetString(prefix + "path")
+		if path == "" {
+			break
+		}
+
+		password := wallet.GetPassword(v, prefix+"passphrase")
+		key, err := wallet.GetKeyFromPath(path, v.GetString(prefix+"address"), password)
+		if err != nil {
+			l.Fatal("could not load retired NeoFS private key",
+				zap.String("wallet", path),
+				zap.Error(err))
+		}
+
+		l.Info("using retired credentials",
+			zap.String("NeoFS", hex.EncodeToString(key.PublicKey().Bytes())))
+		res = append(res, key)
+	}
+
+	return res
+}
+
+// initTracing sets up tracing if it's enabled and returns a function to stop it.
+func initTracing(ctx context.Context, v *viper.Viper, l *zap.Logger) func(context.Context) error {
+	if !v.GetBool(cfgTracingEnabled) {
+		return nil
+	}
+
+	cfg := &tracing.Config{
+		Exporter:    v.GetString(cfgTracingExporter),
+		Endpoint:    v.GetString(cfgTracingEndpoint),
+		Insecure:    v.GetBool(cfgTracingInsecure),
+		SampleRatio: v.GetFloat64(cfgTracingSampleRatio),
+		Service:     v.GetString(cfgApplicationName),
+		Version:     v.GetString(cfgApplicationVersion),
+	}
+
+	stop, err := tracing.Init(ctx, cfg)
+	if err != nil {
+		l.Fatal("could not initialize tracing", zap.Error(err))
+	}
+
+	l.Info("tracing enabled",
+		zap.String("exporter", cfg.Exporter),
+		zap.String("endpoint", cfg.Endpoint),
+		zap.Float64("sample_ratio", cfg.SampleRatio))
+
+	return stop
+}
+
+// reconcileUsage periodically recalculates usage of the buckets with quota
+// to fix the drift of the counters updated by requests.
+func (a *App) reconcileUsage(ctx context.Context) {
+	interval := a.cfg.GetDuration(cfgQuotaReconcileInterval)
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.obj.ReconcileUsage(ctx)
+		}
+	}
+}
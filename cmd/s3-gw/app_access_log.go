@@ -0,0 +1,61 @@
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+const (
+	accessLogStdout = "stdout"
+	accessLogStderr = "stderr"
+)
+
+// newAccessLog returns access log middleware writing JSON lines to the
+// configured output or nil if the access log is disabled.
+func newAccessLog(v *viper.Viper, l *zap.Logger) mux.MiddlewareFunc {
+	if !v.GetBool(cfgAccessLogEnabled) {
+		return nil
+	}
+
+	var out io.Writer
+	switch output := v.GetString(cfgAccessLogOutput); output {
+	case accessLogStdout:
+		out = os.Stdout
+	case accessLogStderr:
+		out = os.Stderr
+	default:
+		out = &lumberjack.Logger{
+			Filename:   output,
+			MaxSize:    v.GetInt(cfgAccessLogMaxSize),
+			MaxBackups: v.GetInt(cfgAccessLogMaxBackups),
+			MaxAge:     v.GetInt(cfgAccessLogMaxAge),
+			Compress:   v.GetBool(cfgAccessLogCompress),
+		}
+	}
+
+	encoderCfg := zapcore.EncoderConfig{
+		TimeKey:    "time",
+		EncodeTime: zapcore.ISO8601TimeEncoder,
+	}
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderCfg), zapcore.AddSync(out), zap.InfoLevel)
+
+	al, err := api.AccessLog(zap.New(core), &api.AccessLogConfig{
+		SampleRatio: v.GetFloat64(cfgAccessLogSampleRatio),
+		Redact:      v.GetStringSlice(cfgAccessLogRedact),
+	})
+	if err != nil {
+		l.Fatal("couldn't initialize access log", zap.Error(err))
+	}
+
+	l.Info("access log enabled",
+		zap.String("output", v.GetString(cfgAccessLogOutput)))
+
+	return al
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	s3errors "github.com/nspcc-dev/neofs-s3-gw/api/errors"
+	"go.uber.org/zap"
+)
+
+type (
+	// drainAwareHealthy reports the gateway as not ready while it's drained.
+	drainAwareHealthy struct {
+		Healthy
+		inFlight *api.InFlight
+	}
+
+	adminLogLevel struct {
+		Level string `json:"level"`
+	}
+
+	adminDrainStatus struct {
+		Draining bool `json:"draining"`
+		InFlight int  `json:"in_flight"`
+	}
+
+	adminBucketUsage struct {
+		Bucket     string    `json:"bucket"`
+		MaxBytes   uint64    `json:"max_bytes"`
+		MaxObjects uint64    `json:"max_objects"`
+		Bytes      uint64    `json:"bytes"`
+		Objects    uint64    `json:"objects"`
+		Reconciled time.Time `json:"reconciled"`
+		// Scope shows the usage is counted by this gateway only.
+		Scope string `json:"scope"`
+	}
+
+	adminError struct {
+		Error string `json:"error"`
+	}
+)
+
+const (
+	adminPath       = "/admin"
+	redactedSetting = "REDACTED"
+	// usageScopeInstance is the scope of bucket usage counted by every
+	// gateway on its own.
+	usageScopeInstance = "instance"
+)
+
+var errDraining = errors.New("gateway is drained")
+
+// secretSettings are hidden in the configuration dump as well as
+// passphrases of retired wallets.
+var secretSettings = map[string]struct{}{
+	cfgWalletPassphrase: {},
+	cfgAdminKeys:        {},
+	// the URL may contain credentials of the webhook
+	cfgAuthzWebhook: {},
+}
+
+func secretSetting(key string) bool {
+	if _, ok := secretSettings[key]; ok {
+		return true
+	}
+	return strings.HasPrefix(key, cfgWalletRetired+".") && strings.HasSuffix(key, ".passphrase")
+}
+
+// Ready returns an error if the gateway is drained or isn't ready itself.
+func (h drainAwareHealthy) Ready() error {
+	if h.inFlight.Draining() {
+		return errDraining
+	}
+	return h.Healthy.Ready()
+}
+
+// startAdmin runs the admin API server if it's enabled. The server is stopped
+// when ctx is done, done is closed after that.
+func (a *App) startAdmin(ctx context.Context) (done <-chan struct{}) {
+	stopped := make(chan struct{})
+
+	address := a.cfg.GetString(cfgAdminAddress)
+	if address == "" {
+		close(stopped)
+		return stopped
+	}
+
+	keys := a.cfg.GetStringSlice(cfgAdminKeys)
+	if len(keys) == 0 {
+		a.log.Fatal("admin API keys are not set",
+			zap.String("bind", address))
+	}
+
+	srv := &http.Server{
+		Addr:     address,
+		Handler:  a.adminRouter(keys),
+		ErrorLog: zap.NewStdLog(a.log),
+	}
+
+	go func() {
+		a.log.Info("starting admin API server",
+			zap.String("bind", address))
+
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			a.log.Fatal("admin API listen and serve",
+				zap.String("bind", address),
+				zap.Error(err))
+		}
+	}()
+
+	go func() {
+		<-ctx.Done()
+
+		ctx, cancel := context.WithTimeout(context.Background(), defaultShutdownTimeout)
+		defer cancel()
+
+		a.log.Info("stopping admin API server",
+			zap.String("bind", address),
+			zap.Error(srv.Shutdown(ctx)))
+		close(stopped)
+	}()
+
+	return stopped
+}
+
+func (a *App) adminRouter(keys []string) *mux.Router {
+	r := mux.NewRouter()
+	admin := r.PathPrefix(adminPath).Subrouter()
+	admin.Use(adminAuth(keys))
+
+	admin.Methods(http.MethodGet).Path("/caches").HandlerFunc(a.adminGetCaches)
+	admin.Methods(http.MethodDelete).Path("/caches").HandlerFunc(a.adminFlushCaches)
+	admin.Methods(http.MethodGet).Path("/buckets/{bucket}/usage").HandlerFunc(a.adminGetBucketUsage)
+	admin.Methods(http.MethodGet).Path("/config").HandlerFunc(a.adminGetConfig)
+	admin.Methods(http.MethodGet).Path("/requests").HandlerFunc(a.adminGetRequests)
+	admin.Methods(http.MethodGet).Path("/log_level").HandlerFunc(a.adminGetLogLevel)
+	admin.Methods(http.MethodPut).Path("/log_level").HandlerFunc(a.adminSetLogLevel)
+	admin.Methods(http.MethodGet).Path("/drain").HandlerFunc(a.adminGetDrain)
+	admin.Methods(http.MethodPost).Path("/drain").HandlerFunc(a.adminDrain)
+	admin.Methods(http.MethodDelete).Path("/drain").HandlerFunc(a.adminResume)
+
+	return r
+}
+
+// adminAuth allows requests with one of the keys in `Authorization: Bearer <key>` header.
+func adminAuth(keys []string) mux.MiddlewareFunc {
+	return func(h http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+
+			var ok bool
+			for _, key := range keys {
+				if key != "" && subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1 {
+					ok = true
+				}
+			}
+
+			if !ok {
+				writeAdminError(w, http.StatusUnauthorized, errors.New("invalid admin key"))
+				return
+			}
+
+			h.ServeHTTP(w, r)
+		})
+	}
+}
+
+func writeAdminJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set(hdrContentType, jsonContentType)
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+func writeAdminError(w http.ResponseWriter, code int, err error) {
+	writeAdminJSON(w, code, adminError{Error: err.Error()})
+}
+
+func (a *App) adminGetCaches(w http.ResponseWriter, _ *http.Request) {
+	writeAdminJSON(w, http.StatusOK, a.obj.CacheStats())
+}
+
+func (a *App) adminFlushCaches(w http.ResponseWriter, _ *http.Request) {
+	a.mu.RLock()
+	a.obj.UpdateCaches(a.cacheCfg)
+	a.mu.RUnlock()
+
+	a.log.Info("caches flushed via admin API")
+	writeAdminJSON(w, http.StatusOK, a.obj.CacheStats())
+}
+
+func (a *App) adminGetBucketUsage(w http.ResponseWriter, r *http.Request) {
+	bucket := mux.Vars(r)["bucket"]
+
+	info, err := a.obj.GetBucketQuota(r.Context(), bucket)
+	if err != nil {
+		code := http.StatusInternalServerError
+		var s3err s3errors.Error
+		if errors.As(err, &s3err) {
+			code = s3err.HTTPStatusCode
+		}
+		writeAdminError(w, code, err)
+		return
+	}
+
+	writeAdminJSON(w, http.StatusOK, adminBucketUsage{
+		Bucket:     bucket,
+		MaxBytes:   info.Quota.MaxBytes,
+		MaxObjects: info.Quota.MaxObjects,
+		Bytes:      info.Usage.Bytes,
+		Objects:    info.Usage.Objects,
+		Reconciled: info.Usage.Reconciled,
+		Scope:      usageScopeInstance,
+	})
+}
+
+// adminGetConfig returns the effective settings with secrets redacted.
+func (a *App) adminGetConfig(w http.ResponseWriter, _ *http.Request) {
+	a.mu.RLock()
+	settings := make(map[string]interface{})
+	for _, key := range a.cfg.AllKeys() {
+		if secretSetting(key) {
+			settings[key] = redactedSetting
+			continue
+		}
+		settings[key] = a.cfg.Get(key)
+	}
+	a.mu.RUnlock()
+
+	writeAdminJSON(w, http.StatusOK, settings)
+}
+
+func (a *App) adminGetRequests(w http.ResponseWriter, _ *http.Request) {
+	writeAdminJSON(w, http.StatusOK, a.inFlight.List())
+}
+
+func (a *App) adminGetLogLevel(w http.ResponseWriter, _ *http.Request) {
+	writeAdminJSON(w, http.StatusOK, adminLogLevel{Level: a.lvl.Level().String()})
+}
+
+// adminSetLogLevel changes the log level until the next reload of the configuration.
+func (a *App) adminSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req adminLogLevel
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	lvl, err := parseLogLevel(req.Level)
+	if err != nil {
+		writeAdminError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	a.lvl.SetLevel(lvl)
+	a.log.Info("log level changed via admin API",
+		zap.Stringer("level", lvl))
+
+	writeAdminJSON(w, http.StatusOK, adminLogLevel{Level: lvl.String()})
+}
+
+func (a *App) drainStatus() adminDrainStatus {
+	return adminDrainStatus{
+		Draining: a.inFlight.Draining(),
+		InFlight: a.inFlight.Count(),
+	}
+}
+
+func (a *App) adminGetDrain(w http.ResponseWriter, _ *http.Request) {
+	writeAdminJSON(w, http.StatusOK, a.drainStatus())
+}
+
+// adminDrain stops accepting S3 requests and waits for the ones being
+// served for the time set in `timeout` query parameter.
+func (a *App) adminDrain(w http.ResponseWriter, r *http.Request) {
+	var timeout time.Duration
+	if v := r.URL.Query().Get("timeout"); v != "" {
+		var err error
+		if timeout, err = time.ParseDuration(v); err != nil {
+			writeAdminError(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	a.log.Info("draining via admin API",
+		zap.Int("in_flight", a.inFlight.Count()))
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+	_ = a.inFlight.Drain(ctx)
+
+	writeAdminJSON(w, http.StatusOK, a.drainStatus())
+}
+
+func (a *App) adminResume(w http.ResponseWriter, _ *http.Request) {
+	a.inFlight.Resume()
+	a.log.Info("resumed via admin API")
+
+	writeAdminJSON(w, http.StatusOK, a.drainStatus())
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAdminAPI(t *testing.T) {
+	const key = "admin-secret"
+
+	v := viper.New()
+	v.Set(cfgWalletPassphrase, "wallet-secret")
+	v.Set(cfgWalletRetired+".0.path", "old-wallet.json")
+	v.Set(cfgWalletRetired+".0.passphrase", "old-wallet-secret")
+	v.Set(cfgAdminKeys, []string{key})
+	v.Set(cfgListenAddress, "0.0.0.0:8080")
+
+	a := &App{
+		log:      zap.NewNop(),
+		lvl:      zap.NewAtomicLevelAt(zap.InfoLevel),
+		cfg:      v,
+		inFlight: api.NewInFlight(),
+	}
+	r := a.adminRouter(v.GetStringSlice(cfgAdminKeys))
+
+	do := func(method, path, auth, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if auth != "" {
+			req.Header.Set("Authorization", "Bearer "+auth)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("auth", func(t *testing.T) {
+		require.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/admin/config", "", "").Code)
+		require.Equal(t, http.StatusUnauthorized, do(http.MethodGet, "/admin/config", "wrong", "").Code)
+	})
+
+	t.Run("config", func(t *testing.T) {
+		w := do(http.MethodGet, "/admin/config", key, "")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var settings map[string]interface{}
+		require.NoError(t, json.NewDecoder(w.Body).Decode(&settings))
+		require.Equal(t, "0.0.0.0:8080", settings[cfgListenAddress])
+		require.Equal(t, redactedSetting, settings[cfgWalletPassphrase])
+		require.Equal(t, redactedSetting, settings[cfgAdminKeys])
+		require.Equal(t, redactedSetting, settings[cfgWalletRetired+".0.passphrase"])
+		require.Equal(t, "old-wallet.json", settings[cfgWalletRetired+".0.path"])
+		require.NotContains(t, w.Body.String(), "secret")
+	})
+
+	t.Run("log level", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, do(http.MethodPut, "/admin/log_level", key, `{"level":"loud"}`).Code)
+		require.Equal(t, http.StatusOK, do(http.MethodPut, "/admin/log_level", key, `{"level":"debug"}`).Code)
+		require.Equal(t, zap.DebugLevel, a.lvl.Level())
+	})
+
+	t.Run("drain", func(t *testing.T) {
+		w := do(http.MethodPost, "/admin/drain", key, "")
+		require.Equal(t, http.StatusOK, w.Code)
+		require.JSONEq(t, `{"draining":true,"in_flight":0}`, w.Body.String())
+		require.ErrorIs(t, drainAwareHealthy{inFlight: a.inFlight}.Ready(), errDraining)
+
+		w = do(http.MethodDelete, "/admin/drain", key, "")
+		require.JSONEq(t, `{"draining":false,"in_flight":0}`, w.Body.String())
+	})
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"os"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/audit"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// newAuditTrail returns audit trail sealed into the configured container or
+// nil if the audit is disabled.
+func newAuditTrail(ctx context.Context, v *viper.Viper, l *zap.Logger, conns pool.Pool, key *keys.PrivateKey) *audit.Trail {
+	containerID := v.GetString(cfgAuditContainerID)
+	if containerID == "" {
+		return nil
+	}
+
+	id := cid.New()
+	if err := id.Parse(containerID); err != nil {
+		l.Fatal("invalid audit container ID", zap.String("container_id", containerID), zap.Error(err))
+	}
+
+	if interval := v.GetDuration(cfgAuditSealInterval); interval <= 0 {
+		l.Fatal("invalid audit seal interval", zap.Duration("seal_interval", interval))
+	}
+
+	journalPath := v.GetString(cfgAuditJournal)
+	if journalPath == "" {
+		l.Fatal("audit journal isn't set")
+	}
+
+	instance := v.GetString(cfgAuditInstance)
+	if instance == "" {
+		hostname, err := os.Hostname()
+		if err != nil || hostname == "" {
+			l.Fatal("audit instance isn't set and hostname is unknown", zap.Error(err))
+		}
+		instance = hostname
+	}
+
+	journal, err := audit.NewFileJournal(journalPath)
+	if err != nil {
+		l.Fatal("couldn't open audit journal", zap.String("journal", journalPath), zap.Error(err))
+	}
+
+	sealer := audit.NewNeoFSSealer(conns, id, key.PublicKey(), instance)
+	trail, err := audit.NewTrail(ctx, key, instance, sealer, journal, l)
+	if err != nil {
+		l.Fatal("couldn't initialize audit trail", zap.Error(err))
+	}
+
+	l.Info("audit trail enabled",
+		zap.String("container_id", containerID),
+		zap.String("instance", instance),
+		zap.String("journal", journalPath))
+
+	return trail
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// newAuthorizer returns authorizer asking the configured webhook or policy
+// file or nil if external authorization is disabled.
+func newAuthorizer(v *viper.Viper, l *zap.Logger) api.Authorizer {
+	webhook, policyFile := v.GetString(cfgAuthzWebhook), v.GetString(cfgAuthzPolicyFile)
+
+	cfg := &api.AuthzConfig{
+		CacheTTL:       v.GetDuration(cfgAuthzCacheTTL),
+		CacheSize:      v.GetInt(cfgAuthzCacheSize),
+		FailOpen:       v.GetBool(cfgAuthzFailOpen),
+		Headers:        v.GetStringSlice(cfgAuthzHeaders),
+		TrustedProxies: getTrustedProxies(v, l),
+	}
+
+	switch {
+	case webhook != "" && policyFile != "":
+		l.Fatal("authorization webhook and policy file can't be used together")
+	case webhook != "":
+		cfg.Evaluator = api.NewWebhookEvaluator(webhook, v.GetDuration(cfgAuthzTimeout))
+		l.Info("authorization webhook enabled",
+			zap.String("url", webhook),
+			zap.Bool("fail_open", cfg.FailOpen))
+	case policyFile != "":
+		data, err := os.ReadFile(policyFile)
+		if err != nil {
+			l.Fatal("couldn't read authorization policy", zap.Error(err))
+		}
+		if cfg.Evaluator, err = api.ParseAuthzPolicy(data); err != nil {
+			l.Fatal("couldn't load authorization policy", zap.Error(err))
+		}
+		l.Info("authorization policy enabled",
+			zap.String("file", policyFile))
+	default:
+		return nil
+	}
+
+	return api.NewAuthorizer(cfg, l)
+}
@@ -1,33 +1,356 @@
 package main
 
 import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
+	"github.com/nspcc-dev/neofs-api-go/pkg/netmap"
+	"github.com/nspcc-dev/neofs-s3-gw/api/cache"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
 )
 
 // Healthy is a health check interface.
 type Healthy interface {
+	// Ready returns an error if the gateway can't serve requests yet.
+	Ready() error
+	// Status returns an error if the gateway is stuck and should be restarted.
 	Status() error
+	// Peers returns the latest health state of NeoFS peers.
+	Peers() []PeerStatus
+	// Epoch returns the latest known NeoFS network epoch.
+	Epoch() uint64
 }
 
+type (
+	// PeerStatus describes health of a NeoFS peer.
+	PeerStatus struct {
+		Address   string    `json:"address"`
+		Healthy   bool      `json:"healthy"`
+		LastError string    `json:"last_error,omitempty"`
+		LastCheck time.Time `json:"last_check"`
+	}
+
+	// HealthStatus is a detailed gateway health state.
+	HealthStatus struct {
+		Ready   bool                   `json:"ready"`
+		Error   string                 `json:"error,omitempty"`
+		Epoch   uint64                 `json:"epoch"`
+		Peers   []PeerStatus           `json:"peers"`
+		Caches  map[string]cache.Stats `json:"caches,omitempty"`
+		Checked time.Time              `json:"checked"`
+	}
+
+	// networkInfoFetcher is a part of NeoFS client used to probe peers.
+	networkInfoFetcher interface {
+		NetworkInfo(context.Context, ...client.CallOption) (*netmap.NetworkInfo, error)
+	}
+
+	peerProbe struct {
+		address string
+		cli     networkInfoFetcher
+	}
+
+	// stallDetector checks if the gateway serves requests.
+	stallDetector interface {
+		Stalled() bool
+	}
+
+	healthChecker struct {
+		log      *zap.Logger
+		key      *ecdsa.PrivateKey
+		interval time.Duration
+		timeout  time.Duration
+		probes   []peerProbe
+		serving  stallDetector
+
+		// checkMu is held during the check, so clients of the removed peers
+		// are closed when they aren't used anymore.
+		checkMu sync.Mutex
+
+		mu        sync.RWMutex
+		peers     []PeerStatus
+		epoch     uint64
+		heartbeat time.Time
+	}
+)
+
 const (
 	healthyState       = "NeoFS S3 Gateway is "
 	hdrContentType     = "Content-Type"
 	defaultContentType = "text/plain; charset=utf-8"
+	jsonContentType    = "application/json"
+
+	// livenessFactor is a number of missed check rounds after which
+	// the gateway is considered stuck.
+	livenessFactor = 3
+)
+
+var (
+	errKeyNotLoaded    = errors.New("wallet key is not loaded")
+	errNotCheckedYet   = errors.New("NeoFS peers have not been checked yet")
+	errNoHealthyPeers  = errors.New("no healthy NeoFS peers")
+	errHealthcheckHang = errors.New("health check loop is stuck")
+	errServingStalled  = errors.New("requests are not served")
 )
 
-//nolint:deadcode,unused // TODO
-func attachHealthy(r *mux.Router, h Healthy) {
+func newHealthChecker(l *zap.Logger, key *ecdsa.PrivateKey, addresses []string, interval, timeout time.Duration) *healthChecker {
+	h := &healthChecker{
+		log:      l,
+		key:      key,
+		interval: interval,
+		timeout:  timeout,
+	}
+	h.UpdatePeers(addresses)
+
+	return h
+}
+
+// UpdatePeers replaces the list of checked NeoFS peers. Clients of the
+// peers which are still checked are kept, the ones of the removed peers are
+// closed. Health of the new peers is unknown until the next check.
+func (h *healthChecker) UpdatePeers(addresses []string) {
+	h.mu.RLock()
+	removed := make(map[string]peerProbe, len(h.probes))
+	for _, probe := range h.probes {
+		removed[probe.address] = probe
+	}
+	h.mu.RUnlock()
+
+	probes := make([]peerProbe, 0, len(addresses))
+	for _, address := range addresses {
+		if probe, ok := removed[address]; ok && probe.cli != nil {
+			delete(removed, address)
+			probes = append(probes, probe)
+			continue
+		}
+
+		probe := peerProbe{address: address}
+
+		cli, err := client.New(
+			client.WithDefaultPrivateKey(h.key),
+			client.WithURIAddress(address, nil),
+			client.WithDialTimeout(h.timeout),
+		)
+		if err != nil {
+			h.log.Error("could not create health check client",
+				zap.String("address", address),
+				zap.Error(err))
+		} else {
+			probe.cli = cli
+		}
+
+		probes = append(probes, probe)
+	}
+
+	h.mu.Lock()
+	h.probes = probes
+	h.mu.Unlock()
+
+	// wait for the check using the old clients
+	h.checkMu.Lock()
+	defer h.checkMu.Unlock()
+
+	for _, probe := range removed {
+		if err := closeClientConn(probe.cli); err != nil {
+			h.log.Warn("could not close health check client",
+				zap.String("address", probe.address),
+				zap.Error(err))
+		}
+	}
+}
+
+// closeClientConn closes the connection of NeoFS client if it was
+// established.
+func closeClientConn(cli interface{}) error {
+	c, ok := cli.(interface{ Conn() io.Closer })
+	if !ok {
+		return nil
+	}
+
+	switch conn := c.Conn().(type) {
+	case nil:
+		return nil
+	case *grpc.ClientConn:
+		// the client returns nil connection before the first call
+		if conn == nil {
+			return nil
+		}
+		return conn.Close()
+	default:
+		return conn.Close()
+	}
+}
+
+// Start runs periodic checks of NeoFS peers until the context is done.
+func (h *healthChecker) Start(ctx context.Context) {
+	h.check(ctx)
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			h.check(ctx)
+		}
+	}
+}
+
+func (h *healthChecker) check(ctx context.Context) {
+	h.checkMu.Lock()
+	defer h.checkMu.Unlock()
+
+	h.mu.RLock()
+	probes := h.probes
+	h.mu.RUnlock()
+
+	var (
+		wg     sync.WaitGroup
+		peers  = make([]PeerStatus, len(probes))
+		epochs = make([]uint64, len(probes))
+	)
+
+	for i := range probes {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			peers[i], epochs[i] = h.probe(ctx, probes[i])
+		}(i)
+	}
+	wg.Wait()
+
+	health := make(map[string]bool, len(peers))
+	for i := range peers {
+		health[peers[i].Address] = peers[i].Healthy
+	}
+	metrics.SetNodesHealth(health)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for i := range peers {
+		if !peers[i].Healthy {
+			h.log.Warn("NeoFS peer is unhealthy",
+				zap.String("address", peers[i].Address),
+				zap.String("error", peers[i].LastError))
+		} else if epochs[i] > h.epoch {
+			h.epoch = epochs[i]
+		}
+	}
+
+	h.peers = peers
+	h.heartbeat = time.Now()
+}
+
+func (h *healthChecker) probe(ctx context.Context, p peerProbe) (PeerStatus, uint64) {
+	status := PeerStatus{Address: p.address, LastCheck: time.Now()}
+	if p.cli == nil {
+		status.LastError = "client is not initialized"
+		return status, 0
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, h.timeout)
+	defer cancel()
+
+	info, err := p.cli.NetworkInfo(ctx)
+	if err != nil {
+		status.LastError = err.Error()
+		return status, 0
+	}
+
+	status.Healthy = true
+	return status, info.CurrentEpoch()
+}
+
+// Ready returns nil if the wallet key is loaded and at least one NeoFS peer is healthy.
+func (h *healthChecker) Ready() error {
+	if h.key == nil {
+		return errKeyNotLoaded
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if h.heartbeat.IsZero() {
+		return errNotCheckedYet
+	}
+
+	for i := range h.peers {
+		if h.peers[i].Healthy {
+			return nil
+		}
+	}
+
+	return errNoHealthyPeers
+}
+
+// Status returns nil if peer checks are still performed regularly and
+// requests are served.
+func (h *healthChecker) Status() error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if !h.heartbeat.IsZero() && time.Since(h.heartbeat) > livenessFactor*(h.interval+h.timeout) {
+		return errHealthcheckHang
+	}
+
+	if h.serving != nil && h.serving.Stalled() {
+		return errServingStalled
+	}
+
+	return nil
+}
+
+// Peers returns the results of the latest check of NeoFS peers.
+func (h *healthChecker) Peers() []PeerStatus {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	res := make([]PeerStatus, len(h.peers))
+	copy(res, h.peers)
+
+	return res
+}
+
+// Epoch returns the latest NeoFS epoch reported by healthy peers.
+func (h *healthChecker) Epoch() uint64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return h.epoch
+}
+
+func attachHealthy(r *mux.Router, h Healthy, c layer.Caches) {
 	healthy := r.PathPrefix(systemPath + "/-").
 		Subrouter().
 		StrictSlash(true)
 
 	healthy.HandleFunc("/ready", func(w http.ResponseWriter, r *http.Request) {
+		code := http.StatusOK
+		msg := "ready"
+
+		if err := h.Ready(); err != nil {
+			msg = "not ready: " + err.Error()
+			code = http.StatusServiceUnavailable
+		}
+
 		w.Header().Set(hdrContentType, defaultContentType)
-		w.WriteHeader(http.StatusOK)
-		_, _ = fmt.Fprintln(w, healthyState+"ready")
+		w.WriteHeader(code)
+		_, _ = fmt.Fprintln(w, healthyState+msg)
 	})
 
 	healthy.HandleFunc("/healthy", func(w http.ResponseWriter, r *http.Request) {
@@ -36,11 +359,34 @@ func attachHealthy(r *mux.Router, h Healthy) {
 
 		if err := h.Status(); err != nil {
 			msg = "unhealthy: " + err.Error()
-			code = http.StatusBadRequest
+			code = http.StatusServiceUnavailable
 		}
 
 		w.Header().Set(hdrContentType, defaultContentType)
 		w.WriteHeader(code)
 		_, _ = fmt.Fprintln(w, healthyState+msg)
 	})
+
+	healthy.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		status := HealthStatus{
+			Ready:   true,
+			Epoch:   h.Epoch(),
+			Peers:   h.Peers(),
+			Checked: time.Now(),
+		}
+		if c != nil {
+			status.Caches = c.CacheStats()
+		}
+
+		code := http.StatusOK
+		if err := h.Ready(); err != nil {
+			status.Ready = false
+			status.Error = err.Error()
+			code = http.StatusServiceUnavailable
+		}
+
+		w.Header().Set(hdrContentType, jsonContentType)
+		w.WriteHeader(code)
+		_ = json.NewEncoder(w).Encode(status)
+	})
 }
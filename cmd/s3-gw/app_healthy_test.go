@@ -0,0 +1,167 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
+	"github.com/nspcc-dev/neofs-api-go/pkg/netmap"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+type testFetcher struct {
+	epoch uint64
+	err   error
+}
+
+func (f *testFetcher) NetworkInfo(context.Context, ...client.CallOption) (*netmap.NetworkInfo, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+
+	info := netmap.NewNetworkInfo()
+	info.SetCurrentEpoch(f.epoch)
+	return info, nil
+}
+
+type stalledMock bool
+
+func (s stalledMock) Stalled() bool { return bool(s) }
+
+type closerMock struct {
+	closed bool
+}
+
+func (c *closerMock) Close() error {
+	c.closed = true
+	return nil
+}
+
+// connFetcher is a peer client with a connection.
+type connFetcher struct {
+	testFetcher
+	conn io.Closer
+}
+
+func (f *connFetcher) Conn() io.Closer {
+	return f.conn
+}
+
+func newTestChecker(probes ...peerProbe) *healthChecker {
+	return &healthChecker{
+		log:      zap.NewNop(),
+		key:      new(ecdsa.PrivateKey),
+		interval: time.Second,
+		timeout:  time.Second,
+		probes:   probes,
+	}
+}
+
+func TestHealthChecker(t *testing.T) {
+	ctx := context.Background()
+	good := &testFetcher{epoch: 10}
+	bad := &testFetcher{err: errors.New("connection refused")}
+
+	t.Run("not ready before first check", func(t *testing.T) {
+		hc := newTestChecker(peerProbe{address: "s01", cli: good})
+		require.Equal(t, errNotCheckedYet, hc.Ready())
+		require.NoError(t, hc.Status())
+	})
+
+	t.Run("not ready without key", func(t *testing.T) {
+		hc := newTestChecker(peerProbe{address: "s01", cli: good})
+		hc.key = nil
+		hc.check(ctx)
+		require.Equal(t, errKeyNotLoaded, hc.Ready())
+	})
+
+	t.Run("no healthy peers", func(t *testing.T) {
+		hc := newTestChecker(peerProbe{address: "s01", cli: bad}, peerProbe{address: "s02"})
+		hc.check(ctx)
+		require.Equal(t, errNoHealthyPeers, hc.Ready())
+
+		peers := hc.Peers()
+		require.Len(t, peers, 2)
+		require.Equal(t, "connection refused", peers[0].LastError)
+		require.NotEmpty(t, peers[1].LastError)
+	})
+
+	t.Run("ready with one healthy peer", func(t *testing.T) {
+		hc := newTestChecker(peerProbe{address: "s01", cli: bad}, peerProbe{address: "s02", cli: good})
+		hc.check(ctx)
+		require.NoError(t, hc.Ready())
+		require.Equal(t, uint64(10), hc.Epoch())
+	})
+
+	t.Run("stuck checker", func(t *testing.T) {
+		hc := newTestChecker(peerProbe{address: "s01", cli: good})
+		hc.check(ctx)
+		require.NoError(t, hc.Status())
+
+		hc.heartbeat = time.Now().Add(-livenessFactor * 3 * time.Second)
+		require.Equal(t, errHealthcheckHang, hc.Status())
+	})
+
+	t.Run("stalled requests", func(t *testing.T) {
+		hc := newTestChecker(peerProbe{address: "s01", cli: good})
+		hc.check(ctx)
+
+		hc.serving = stalledMock(false)
+		require.NoError(t, hc.Status())
+
+		hc.serving = stalledMock(true)
+		require.Equal(t, errServingStalled, hc.Status())
+	})
+}
+
+func TestHealthCheckerUpdatePeers(t *testing.T) {
+	kept := &connFetcher{conn: new(closerMock)}
+	removed := &connFetcher{conn: new(closerMock)}
+	notConnected := &connFetcher{conn: (*grpc.ClientConn)(nil)}
+	hc := newTestChecker(
+		peerProbe{address: "grpc://s01:8080", cli: kept},
+		peerProbe{address: "grpc://s02:8080", cli: removed},
+		peerProbe{address: "grpc://s03:8080", cli: notConnected},
+	)
+
+	hc.UpdatePeers([]string{"grpc://s01:8080", "grpc://s04:8080"})
+
+	require.Len(t, hc.probes, 2)
+	require.Equal(t, kept, hc.probes[0].cli)
+	require.Equal(t, "grpc://s04:8080", hc.probes[1].address)
+	require.False(t, kept.conn.(*closerMock).closed)
+	require.True(t, removed.conn.(*closerMock).closed)
+}
+
+func TestStatusEndpoint(t *testing.T) {
+	hc := newTestChecker(peerProbe{address: "s01", cli: &testFetcher{epoch: 7}})
+	r := mux.NewRouter()
+	attachHealthy(r, hc, nil)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, systemPath+"/-/ready", nil))
+	require.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	hc.check(context.Background())
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, systemPath+"/-/status", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	var status HealthStatus
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&status))
+	require.True(t, status.Ready)
+	require.Equal(t, uint64(7), status.Epoch)
+	require.Len(t, status.Peers, 1)
+	require.True(t, status.Peers[0].Healthy)
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-s3-gw/authmate"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/memory"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/wallet"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/spf13/viper"
+	"go.uber.org/zap"
+)
+
+// Storage backends.
+const (
+	backendNeoFS  = "neofs"
+	backendMemory = "memory"
+
+	// memoryAuthContainer is a name of the container keeping the access box
+	// of memory backend credentials.
+	memoryAuthContainer = "auth"
+)
+
+// useMemoryBackend checks the configured backend.
+func useMemoryBackend(v *viper.Viper, l *zap.Logger) bool {
+	switch backend := v.GetString(cfgBackend); backend {
+	case "", backendNeoFS:
+		return false
+	case backendMemory:
+		l.Warn("using memory backend, all the data is lost on exit")
+		return true
+	default:
+		l.Fatal("unknown backend", zap.String("backend", backend))
+		return false
+	}
+}
+
+// getMemoryBackendKey returns the wallet key if the wallet is set, otherwise
+// a new one.
+func getMemoryBackendKey(v *viper.Viper, l *zap.Logger) *keys.PrivateKey {
+	if v.GetString(cfgWallet) != "" {
+		password := wallet.GetPassword(v, cfgWalletPassphrase)
+		key, err := wallet.GetKeyFromPath(v.GetString(cfgWallet), v.GetString(cfgAddress), password)
+		if err != nil {
+			l.Fatal("could not load NeoFS private key", zap.Error(err))
+		}
+		return key
+	}
+
+	key, err := keys.NewPrivateKey()
+	if err != nil {
+		l.Fatal("could not generate private key", zap.Error(err))
+	}
+	return key
+}
+
+// newMemoryPool returns the pool keeping the data in memory.
+func newMemoryPool(l *zap.Logger, key *keys.PrivateKey) *memory.Pool {
+	p, err := memory.NewPool(key)
+	if err != nil {
+		l.Fatal("could not create memory backend", zap.Error(err))
+	}
+	return p
+}
+
+// issueMemoryCredentials issues the credentials of the gateway owner, so
+// S3 clients can use the memory backend right after the start. The secret
+// access key isn't logged, it's printed to out once.
+func issueMemoryCredentials(ctx context.Context, l *zap.Logger, out io.Writer, conns pool.Pool, key *keys.PrivateKey) {
+	buf := new(bytes.Buffer)
+	err := authmate.New(l, conns).IssueSecret(ctx, buf, &authmate.IssueSecretOptions{
+		ContainerFriendlyName: memoryAuthContainer,
+		NeoFSKey:              key,
+		GatesPublicKeys:       []*keys.PublicKey{key.PublicKey()},
+		Lifetime:              math.MaxUint64,
+	})
+	if err != nil {
+		l.Fatal("could not issue memory backend credentials", zap.Error(err))
+	}
+
+	var creds struct {
+		AccessKeyID     string `json:"access_key_id"`
+		SecretAccessKey string `json:"secret_access_key"`
+	}
+	if err = json.Unmarshal(buf.Bytes(), &creds); err != nil {
+		l.Fatal("could not read memory backend credentials", zap.Error(err))
+	}
+
+	if _, err = fmt.Fprintf(out, "AWS_ACCESS_KEY_ID=%s\nAWS_SECRET_ACCESS_KEY=%s\n",
+		creds.AccessKeyID, creds.SecretAccessKey); err != nil {
+		l.Fatal("could not print memory backend credentials", zap.Error(err))
+	}
+	l.Info("memory backend credentials are printed to stderr",
+		zap.String("access_key_id", creds.AccessKeyID))
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-api-go/pkg/container"
+	"github.com/nspcc-dev/neofs-api-go/pkg/token"
+	"github.com/nspcc-dev/neofs-api-go/v2/refs"
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/auth"
+	"github.com/nspcc-dev/neofs-s3-gw/api/handler"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/tokens"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/memory"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// newMemoryGateway starts the gateway with memory backend and returns S3
+// client using credentials of the gateway owner.
+func newMemoryGateway(t *testing.T) *s3.S3 {
+	ctx := context.Background()
+	l := zap.NewNop()
+	v := viper.New()
+
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	p, err := memory.NewPool(key)
+	require.NoError(t, err)
+
+	// bearer token isn't checked by memory backend, so it has only the key
+	// to make the gateway owner the issuer
+	sig := new(refs.Signature)
+	sig.SetKey(key.PublicKey().Bytes())
+	bearer := token.NewBearerToken()
+	bearer.ToV2().SetSignature(sig)
+
+	box, secrets, err := accessbox.PackTokens([]*accessbox.GateData{accessbox.NewGateData(key.PublicKey(), bearer)})
+	require.NoError(t, err)
+	authCnrID, err := p.PutContainer(ctx, container.New())
+	require.NoError(t, err)
+	address, err := tokens.New(p, secrets.EphemeralKey).Put(ctx, authCnrID, p.OwnerID(), box, key.PublicKey())
+	require.NoError(t, err)
+
+	obj := layer.NewLayer(l, p, &layer.Config{
+		Caches:          getCacheOptions(v, l),
+		SessionFallback: layer.SessionFallbackGateway,
+	})
+	h, err := handler.New(l, obj, getHandlerOptions(v, l))
+	require.NoError(t, err)
+
+	router := newS3Router()
+	api.Attach(router, nil, api.NewMaxClientsMiddleware(defaultMaxClientsCount, defaultMaxClientsDeadline),
+		api.NewRateLimiter(getRateLimits(v, l)), nil, h, auth.New(p, key, getAuthOptions(v, l)), l)
+
+	srv := httptest.NewServer(router)
+	t.Cleanup(srv.Close)
+
+	accessKeyID := address.ContainerID().String() + "0" + address.ObjectID().String()
+	sess, err := session.NewSession(&aws.Config{
+		Endpoint:         aws.String(srv.URL),
+		Region:           aws.String("us-east-1"),
+		S3ForcePathStyle: aws.Bool(true),
+		Credentials:      credentials.NewStaticCredentials(accessKeyID, secrets.AccessKey, ""),
+	})
+	require.NoError(t, err)
+
+	return s3.New(sess)
+}
+
+func TestMemoryBackend(t *testing.T) {
+	if testing.Short() {
+		t.Skip("bucket creation waits for eACL")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	client := newMemoryGateway(t)
+	bucket := aws.String("bucket")
+
+	_, err := client.CreateBucketWithContext(ctx, &s3.CreateBucketInput{Bucket: bucket})
+	require.NoError(t, err)
+
+	_, err = client.PutBucketVersioningWithContext(ctx, &s3.PutBucketVersioningInput{
+		Bucket:                  bucket,
+		VersioningConfiguration: &s3.VersioningConfiguration{Status: aws.String(s3.BucketVersioningStatusEnabled)},
+	})
+	require.NoError(t, err)
+
+	for _, content := range []string{"first version", "second version"} {
+		_, err = client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+			Bucket: bucket,
+			Key:    aws.String("dir/object"),
+			Body:   bytes.NewReader([]byte(content)),
+		})
+		require.NoError(t, err)
+	}
+
+	res, err := client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: bucket,
+		Key:    aws.String("dir/object"),
+		Range:  aws.String("bytes=0-5"),
+	})
+	require.NoError(t, err)
+	data, err := io.ReadAll(res.Body)
+	require.NoError(t, err)
+	require.NoError(t, res.Body.Close())
+	require.Equal(t, "second", string(data))
+
+	versions, err := client.ListObjectVersionsWithContext(ctx, &s3.ListObjectVersionsInput{Bucket: bucket})
+	require.NoError(t, err)
+	require.Len(t, versions.Versions, 2)
+
+	list, err := client.ListObjectsV2WithContext(ctx, &s3.ListObjectsV2Input{Bucket: bucket, Prefix: aws.String("dir/")})
+	require.NoError(t, err)
+	require.Len(t, list.Contents, 1)
+	require.Equal(t, "dir/object", *list.Contents[0].Key)
+
+	_, err = client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{Bucket: bucket, Key: aws.String("dir/object")})
+	require.NoError(t, err)
+	_, err = client.HeadObjectWithContext(ctx, &s3.HeadObjectInput{Bucket: bucket, Key: aws.String("dir/object")})
+	require.Error(t, err)
+}
@@ -2,19 +2,72 @@ package main
 
 import (
 	"github.com/gorilla/mux"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
 )
 
-func attachMetrics(r *mux.Router, v *viper.Viper, l *zap.Logger) {
+// cacheCollector exposes statistics of the gateway caches.
+type cacheCollector struct {
+	caches layer.Caches
+
+	entries  *prometheus.Desc
+	hits     *prometheus.Desc
+	misses   *prometheus.Desc
+	hitRatio *prometheus.Desc
+}
+
+func attachMetrics(r *mux.Router, v *viper.Viper, l *zap.Logger, c layer.Caches) {
 	if !v.GetBool(cfgEnableMetrics) {
 		return
 	}
 
+	metrics.EnableBucketMetrics(v.GetBool(cfgBucketMetrics))
+	if err := prometheus.Register(newCacheCollector(c)); err != nil {
+		l.Warn("couldn't register cache metrics", zap.Error(err))
+	}
+
 	l.Info("enable metrics")
 	r.PathPrefix(systemPath+"/metrics").
 		Subrouter().
 		StrictSlash(true).
 		Handle("", promhttp.Handler())
 }
+
+func newCacheCollector(c layer.Caches) *cacheCollector {
+	return &cacheCollector{
+		caches: c,
+		entries: prometheus.NewDesc("neofs_s3_cache_entries",
+			"Number of entries in the cache", []string{"cache"}, nil),
+		hits: prometheus.NewDesc("neofs_s3_cache_hits_total",
+			"Number of cache hits", []string{"cache"}, nil),
+		misses: prometheus.NewDesc("neofs_s3_cache_misses_total",
+			"Number of cache misses", []string{"cache"}, nil),
+		hitRatio: prometheus.NewDesc("neofs_s3_cache_hit_ratio",
+			"Ratio of cache hits to all cache lookups", []string{"cache"}, nil),
+	}
+}
+
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.entries
+	ch <- c.hits
+	ch <- c.misses
+	ch <- c.hitRatio
+}
+
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	for name, st := range c.caches.CacheStats() {
+		ch <- prometheus.MustNewConstMetric(c.entries, prometheus.GaugeValue, float64(st.Size), name)
+		ch <- prometheus.MustNewConstMetric(c.hits, prometheus.CounterValue, float64(st.HitCount), name)
+		ch <- prometheus.MustNewConstMetric(c.misses, prometheus.CounterValue, float64(st.MissCount), name)
+
+		var ratio float64
+		if total := st.HitCount + st.MissCount; total > 0 {
+			ratio = float64(st.HitCount) / float64(total)
+		}
+		ch <- prometheus.MustNewConstMetric(c.hitRatio, prometheus.GaugeValue, ratio, name)
+	}
+}
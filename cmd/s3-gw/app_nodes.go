@@ -0,0 +1,388 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-api-go/pkg/acl/eacl"
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
+	"github.com/nspcc-dev/neofs-api-go/pkg/container"
+	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/nspcc-dev/neofs-api-go/pkg/netmap"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"github.com/nspcc-dev/neofs-api-go/pkg/owner"
+	"github.com/nspcc-dev/neofs-api-go/pkg/session"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+)
+
+type (
+	// peer is a NeoFS node the gateway connects to.
+	peer struct {
+		Address string
+		Weight  float64
+	}
+
+	// dialFunc creates a client of the NeoFS node.
+	dialFunc func(opts ...client.Option) (client.Client, error)
+
+	// nodePool is a pool.Pool over the clients it creates itself, so their
+	// connections can be closed with the pool. Calls are balanced between
+	// healthy nodes by weights like in the SDK pool.
+	nodePool struct {
+		owner   *owner.ID
+		opts    pool.BuilderOptions
+		weights []float64
+		nodes   []*node
+
+		mu      sync.RWMutex
+		sampler *pool.Sampler
+	}
+
+	node struct {
+		address string
+		client  client.Client
+		token   *session.Token
+		healthy bool
+	}
+)
+
+var errNoHealthyNode = errors.New("no healthy client")
+
+var _ pool.Pool = (*nodePool)(nil)
+
+// newNodePool creates clients of the peers with the dial function and starts
+// checking health of the nodes until the context is done. All the nodes must
+// be available to create the pool.
+func newNodePool(ctx context.Context, peers []peer, opts *pool.BuilderOptions, dial dialFunc) (*nodePool, error) {
+	if len(peers) == 0 {
+		return nil, errors.New("no NeoFS peers configured")
+	}
+
+	wallet, err := owner.NEO3WalletFromPublicKey(&opts.Key.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &nodePool{
+		owner:   owner.NewIDFromNeo3Wallet(wallet),
+		opts:    *opts,
+		weights: make([]float64, 0, len(peers)),
+		nodes:   make([]*node, 0, len(peers)),
+	}
+
+	for _, peer := range peers {
+		cli, err := dial(client.WithDefaultPrivateKey(opts.Key),
+			client.WithURIAddress(peer.Address, nil),
+			client.WithDialTimeout(opts.NodeConnectionTimeout))
+		if err != nil {
+			p.close()
+			return nil, fmt.Errorf("could not create client of %s: %w", peer.Address, err)
+		}
+		n := &node{address: peer.Address, client: cli}
+		p.nodes = append(p.nodes, n)
+		p.weights = append(p.weights, peer.Weight)
+
+		if n.token, err = cli.CreateSession(ctx, opts.SessionExpirationEpoch); err != nil {
+			p.close()
+			return nil, fmt.Errorf("failed to create neofs session token for client %s: %w", peer.Address, err)
+		}
+		n.healthy = true
+	}
+
+	p.sampler = pool.NewSampler(adjustWeights(p.weights), rand.NewSource(time.Now().UnixNano()))
+
+	go p.rebalance(ctx)
+
+	return p, nil
+}
+
+// clients returns the clients of all the nodes.
+func (p *nodePool) clients() []client.Client {
+	res := make([]client.Client, 0, len(p.nodes))
+	for _, n := range p.nodes {
+		res = append(res, n.client)
+	}
+	return res
+}
+
+// close closes connections of all the nodes.
+func (p *nodePool) close() {
+	for _, n := range p.nodes {
+		_ = closeClientConn(n.client)
+	}
+}
+
+func (p *nodePool) rebalance(ctx context.Context) {
+	if p.opts.ClientRebalanceInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(p.opts.ClientRebalanceInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.updateHealth(ctx)
+		}
+	}
+}
+
+// updateHealth checks the nodes and rebuilds the sampler if the health of any
+// node changed. A session is created with the node which gets healthy.
+func (p *nodePool) updateHealth(ctx context.Context) {
+	var (
+		wg      sync.WaitGroup
+		weights = make([]float64, len(p.nodes))
+		healthy = make([]bool, len(p.nodes))
+		tokens  = make([]*session.Token, len(p.nodes))
+	)
+
+	p.mu.RLock()
+	for i, n := range p.nodes {
+		healthy[i], tokens[i] = n.healthy, n.token
+	}
+	p.mu.RUnlock()
+
+	for i, n := range p.nodes {
+		wg.Add(1)
+		go func(i int, n *node) {
+			defer wg.Done()
+
+			tctx, cancel := context.WithTimeout(ctx, p.opts.NodeRequestTimeout)
+			defer cancel()
+
+			_, err := n.client.EndpointInfo(tctx)
+			if err == nil && !healthy[i] {
+				tokens[i], err = n.client.CreateSession(ctx, p.opts.SessionExpirationEpoch)
+			}
+			healthy[i] = err == nil
+			if healthy[i] {
+				weights[i] = p.weights[i]
+			}
+		}(i, n)
+	}
+	wg.Wait()
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	changed := false
+	for i, n := range p.nodes {
+		if n.healthy != healthy[i] {
+			n.healthy, n.token = healthy[i], tokens[i]
+			changed = true
+		}
+	}
+	if changed {
+		p.sampler = pool.NewSampler(adjustWeights(weights), rand.NewSource(time.Now().UnixNano()))
+	}
+}
+
+func adjustWeights(weights []float64) []float64 {
+	adjusted := make([]float64, len(weights))
+	sum := 0.0
+	for _, weight := range weights {
+		sum += weight
+	}
+	if sum > 0 {
+		for i, weight := range weights {
+			adjusted[i] = weight / sum
+		}
+	}
+	return adjusted
+}
+
+// Connection returns a client of the healthy node chosen by weights.
+func (p *nodePool) Connection() (client.Client, *session.Token, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if len(p.nodes) == 1 {
+		if n := p.nodes[0]; n.healthy {
+			return n.client, n.token, nil
+		}
+		return nil, nil, errNoHealthyNode
+	}
+
+	for k := 0; k < 3*len(p.nodes); k++ {
+		if n := p.nodes[p.sampler.Next()]; n.healthy {
+			return n.client, n.token, nil
+		}
+	}
+	return nil, nil, errNoHealthyNode
+}
+
+func (p *nodePool) OwnerID() *owner.ID {
+	return p.owner
+}
+
+func (p *nodePool) conn(opts []client.CallOption) (client.Client, []client.CallOption, error) {
+	conn, token, err := p.Connection()
+	if err != nil {
+		return nil, nil, err
+	}
+	return conn, append([]client.CallOption{client.WithSession(token)}, opts...), nil
+}
+
+func (p *nodePool) PutObject(ctx context.Context, params *client.PutObjectParams, opts ...client.CallOption) (*object.ID, error) {
+	conn, opts, err := p.conn(opts)
+	if err != nil {
+		return nil, err
+	}
+	return conn.PutObject(ctx, params, opts...)
+}
+
+func (p *nodePool) DeleteObject(ctx context.Context, params *client.DeleteObjectParams, opts ...client.CallOption) error {
+	conn, opts, err := p.conn(opts)
+	if err != nil {
+		return err
+	}
+	return conn.DeleteObject(ctx, params, opts...)
+}
+
+func (p *nodePool) GetObject(ctx context.Context, params *client.GetObjectParams, opts ...client.CallOption) (*object.Object, error) {
+	conn, opts, err := p.conn(opts)
+	if err != nil {
+		return nil, err
+	}
+	return conn.GetObject(ctx, params, opts...)
+}
+
+func (p *nodePool) GetObjectHeader(ctx context.Context, params *client.ObjectHeaderParams, opts ...client.CallOption) (*object.Object, error) {
+	conn, opts, err := p.conn(opts)
+	if err != nil {
+		return nil, err
+	}
+	return conn.GetObjectHeader(ctx, params, opts...)
+}
+
+func (p *nodePool) ObjectPayloadRangeData(ctx context.Context, params *client.RangeDataParams, opts ...client.CallOption) ([]byte, error) {
+	conn, opts, err := p.conn(opts)
+	if err != nil {
+		return nil, err
+	}
+	return conn.ObjectPayloadRangeData(ctx, params, opts...)
+}
+
+func (p *nodePool) ObjectPayloadRangeSHA256(ctx context.Context, params *client.RangeChecksumParams, opts ...client.CallOption) ([][sha256.Size]byte, error) {
+	conn, opts, err := p.conn(opts)
+	if err != nil {
+		return nil, err
+	}
+	return conn.ObjectPayloadRangeSHA256(ctx, params, opts...)
+}
+
+func (p *nodePool) ObjectPayloadRangeTZ(ctx context.Context, params *client.RangeChecksumParams, opts ...client.CallOption) ([][client.TZSize]byte, error) {
+	conn, opts, err := p.conn(opts)
+	if err != nil {
+		return nil, err
+	}
+	return conn.ObjectPayloadRangeTZ(ctx, params, opts...)
+}
+
+func (p *nodePool) SearchObject(ctx context.Context, params *client.SearchObjectParams, opts ...client.CallOption) ([]*object.ID, error) {
+	conn, opts, err := p.conn(opts)
+	if err != nil {
+		return nil, err
+	}
+	return conn.SearchObject(ctx, params, opts...)
+}
+
+func (p *nodePool) PutContainer(ctx context.Context, cnr *container.Container, opts ...client.CallOption) (*cid.ID, error) {
+	conn, opts, err := p.conn(opts)
+	if err != nil {
+		return nil, err
+	}
+	return conn.PutContainer(ctx, cnr, opts...)
+}
+
+func (p *nodePool) GetContainer(ctx context.Context, id *cid.ID, opts ...client.CallOption) (*container.Container, error) {
+	conn, opts, err := p.conn(opts)
+	if err != nil {
+		return nil, err
+	}
+	return conn.GetContainer(ctx, id, opts...)
+}
+
+func (p *nodePool) ListContainers(ctx context.Context, ownerID *owner.ID, opts ...client.CallOption) ([]*cid.ID, error) {
+	conn, opts, err := p.conn(opts)
+	if err != nil {
+		return nil, err
+	}
+	return conn.ListContainers(ctx, ownerID, opts...)
+}
+
+func (p *nodePool) DeleteContainer(ctx context.Context, id *cid.ID, opts ...client.CallOption) error {
+	conn, opts, err := p.conn(opts)
+	if err != nil {
+		return err
+	}
+	return conn.DeleteContainer(ctx, id, opts...)
+}
+
+func (p *nodePool) GetEACL(ctx context.Context, id *cid.ID, opts ...client.CallOption) (*client.EACLWithSignature, error) {
+	conn, opts, err := p.conn(opts)
+	if err != nil {
+		return nil, err
+	}
+	return conn.GetEACL(ctx, id, opts...)
+}
+
+func (p *nodePool) SetEACL(ctx context.Context, table *eacl.Table, opts ...client.CallOption) error {
+	conn, opts, err := p.conn(opts)
+	if err != nil {
+		return err
+	}
+	return conn.SetEACL(ctx, table, opts...)
+}
+
+func (p *nodePool) AnnounceContainerUsedSpace(ctx context.Context, announce []container.UsedSpaceAnnouncement, opts ...client.CallOption) error {
+	conn, opts, err := p.conn(opts)
+	if err != nil {
+		return err
+	}
+	return conn.AnnounceContainerUsedSpace(ctx, announce, opts...)
+}
+
+// NetworkInfo requests the network info from the healthy node.
+func (p *nodePool) NetworkInfo(ctx context.Context, opts ...client.CallOption) (*netmap.NetworkInfo, error) {
+	conn, _, err := p.Connection()
+	if err != nil {
+		return nil, err
+	}
+	return conn.NetworkInfo(ctx, opts...)
+}
+
+// WaitForContainerPresence polls the container until it's available or the
+// creation timeout expires.
+func (p *nodePool) WaitForContainerPresence(ctx context.Context, id *cid.ID, params *pool.ContainerPollingParams) error {
+	conn, _, err := p.Connection()
+	if err != nil {
+		return err
+	}
+
+	wctx, cancel := context.WithTimeout(ctx, params.CreationTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(params.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-wctx.Done():
+			return wctx.Err()
+		case <-ticker.C:
+			if _, err = conn.GetContainer(wctx, id); err == nil {
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"context"
+	"crypto/sha256"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neofs-api-go/pkg/acl/eacl"
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
+	"github.com/nspcc-dev/neofs-api-go/pkg/container"
+	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/nspcc-dev/neofs-api-go/pkg/netmap"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"github.com/nspcc-dev/neofs-api-go/pkg/owner"
+	"github.com/nspcc-dev/neofs-api-go/pkg/session"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/neofs"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"go.uber.org/zap"
+)
+
+type (
+	// reloadablePool is a pool.Pool which allows to replace the underlying
+	// connection pool without restarting the gateway. Requests started before
+	// the replacement are finished using the old pool.
+	reloadablePool struct {
+		log   *zap.Logger
+		mu    sync.RWMutex
+		conns *connections
+	}
+
+	// connections is a connection pool with the means to stop it.
+	connections struct {
+		pool.Pool
+		cancel context.CancelFunc
+		// clients of the pool nodes, their connections are closed with the pool.
+		clients []client.Client
+		// calls made with the pool.
+		calls sync.WaitGroup
+	}
+)
+
+// poolDrainTimeout limits the time the replaced pool is waited to finish
+// the calls before its connections are closed.
+const poolDrainTimeout = time.Minute
+
+var _ pool.Pool = (*reloadablePool)(nil)
+
+func newReloadablePool(log *zap.Logger, conns *connections) *reloadablePool {
+	return &reloadablePool{log: log, conns: conns}
+}
+
+// Swap replaces the underlying pool and stops rebalancing of the previous one.
+// Connections of the previous pool are closed when the calls made with it are
+// finished.
+func (r *reloadablePool) Swap(conns *connections) {
+	r.mu.Lock()
+	old := r.conns
+	r.conns = conns
+	r.mu.Unlock()
+
+	if old.cancel != nil {
+		old.cancel()
+	}
+
+	go r.close(old, poolDrainTimeout)
+}
+
+// close waits for the calls made with the pool during timeout at most and
+// closes its connections.
+func (r *reloadablePool) close(conns *connections, timeout time.Duration) {
+	drained := make(chan struct{})
+	go func() {
+		conns.calls.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+	case <-time.After(timeout):
+		r.log.Warn("closing connection pool with calls in progress")
+	}
+
+	for _, cli := range conns.clients {
+		if err := closeClientConn(cli); err != nil {
+			r.log.Warn("could not close connection of the replaced pool", zap.Error(err))
+		}
+	}
+}
+
+// acquire returns the current pool, the call must be finished with Done
+// of the calls.
+func (r *reloadablePool) acquire() *connections {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	r.conns.calls.Add(1)
+	return r.conns
+}
+
+func (r *reloadablePool) PutObject(ctx context.Context, params *client.PutObjectParams, opts ...client.CallOption) (*object.ID, error) {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.PutObject(ctx, params, opts...)
+}
+
+func (r *reloadablePool) DeleteObject(ctx context.Context, params *client.DeleteObjectParams, opts ...client.CallOption) error {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.DeleteObject(ctx, params, opts...)
+}
+
+func (r *reloadablePool) GetObject(ctx context.Context, params *client.GetObjectParams, opts ...client.CallOption) (*object.Object, error) {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.GetObject(ctx, params, opts...)
+}
+
+func (r *reloadablePool) GetObjectHeader(ctx context.Context, params *client.ObjectHeaderParams, opts ...client.CallOption) (*object.Object, error) {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.GetObjectHeader(ctx, params, opts...)
+}
+
+func (r *reloadablePool) ObjectPayloadRangeData(ctx context.Context, params *client.RangeDataParams, opts ...client.CallOption) ([]byte, error) {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.ObjectPayloadRangeData(ctx, params, opts...)
+}
+
+func (r *reloadablePool) ObjectPayloadRangeSHA256(ctx context.Context, params *client.RangeChecksumParams, opts ...client.CallOption) ([][sha256.Size]byte, error) {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.ObjectPayloadRangeSHA256(ctx, params, opts...)
+}
+
+func (r *reloadablePool) ObjectPayloadRangeTZ(ctx context.Context, params *client.RangeChecksumParams, opts ...client.CallOption) ([][client.TZSize]byte, error) {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.ObjectPayloadRangeTZ(ctx, params, opts...)
+}
+
+func (r *reloadablePool) SearchObject(ctx context.Context, params *client.SearchObjectParams, opts ...client.CallOption) ([]*object.ID, error) {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.SearchObject(ctx, params, opts...)
+}
+
+func (r *reloadablePool) PutContainer(ctx context.Context, cnr *container.Container, opts ...client.CallOption) (*cid.ID, error) {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.PutContainer(ctx, cnr, opts...)
+}
+
+func (r *reloadablePool) GetContainer(ctx context.Context, id *cid.ID, opts ...client.CallOption) (*container.Container, error) {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.GetContainer(ctx, id, opts...)
+}
+
+func (r *reloadablePool) ListContainers(ctx context.Context, ownerID *owner.ID, opts ...client.CallOption) ([]*cid.ID, error) {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.ListContainers(ctx, ownerID, opts...)
+}
+
+func (r *reloadablePool) DeleteContainer(ctx context.Context, id *cid.ID, opts ...client.CallOption) error {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.DeleteContainer(ctx, id, opts...)
+}
+
+func (r *reloadablePool) GetEACL(ctx context.Context, id *cid.ID, opts ...client.CallOption) (*client.EACLWithSignature, error) {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.GetEACL(ctx, id, opts...)
+}
+
+// GetEACLTable requests the eACL table with the current pool.
+func (r *reloadablePool) GetEACLTable(ctx context.Context, id *cid.ID, opts ...client.CallOption) (*eacl.Table, error) {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return neofs.GetEACL(ctx, conns.Pool, id, opts...)
+}
+
+func (r *reloadablePool) SetEACL(ctx context.Context, table *eacl.Table, opts ...client.CallOption) error {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.SetEACL(ctx, table, opts...)
+}
+
+func (r *reloadablePool) AnnounceContainerUsedSpace(ctx context.Context, announce []container.UsedSpaceAnnouncement, opts ...client.CallOption) error {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.AnnounceContainerUsedSpace(ctx, announce, opts...)
+}
+
+// NetworkInfo requests the network info with the current pool.
+func (r *reloadablePool) NetworkInfo(ctx context.Context, opts ...client.CallOption) (*netmap.NetworkInfo, error) {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return neofs.NetworkInfo(ctx, conns.Pool, opts...)
+}
+
+// Connection returns a connection of the current pool. Calls made with it
+// aren't waited for when the pool is replaced, so the pool methods are to be
+// used instead.
+func (r *reloadablePool) Connection() (client.Client, *session.Token, error) {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.Connection()
+}
+
+func (r *reloadablePool) OwnerID() *owner.ID {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.OwnerID()
+}
+
+func (r *reloadablePool) WaitForContainerPresence(ctx context.Context, id *cid.ID, params *pool.ContainerPollingParams) error {
+	conns := r.acquire()
+	defer conns.calls.Done()
+
+	return conns.WaitForContainerPresence(ctx, id, params)
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
+	"github.com/nspcc-dev/neofs-api-go/pkg/netmap"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"github.com/nspcc-dev/neofs-api-go/pkg/session"
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/memory"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/neofs"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/tracing"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// chanCloser is closed once.
+type chanCloser chan struct{}
+
+func (c chanCloser) Close() error {
+	close(c)
+	return nil
+}
+
+type connClient struct {
+	client.Client
+	conn chanCloser
+	// down makes the node fail requests.
+	down bool
+}
+
+func (c *connClient) Conn() io.Closer {
+	return c.conn
+}
+
+func (c *connClient) CreateSession(context.Context, uint64, ...client.CallOption) (*session.Token, error) {
+	if c.down {
+		return nil, errors.New("node is down")
+	}
+	return session.NewToken(), nil
+}
+
+func (c *connClient) EndpointInfo(context.Context, ...client.CallOption) (*client.EndpointInfo, error) {
+	if c.down {
+		return nil, errors.New("node is down")
+	}
+	return new(client.EndpointInfo), nil
+}
+
+func newConnClient() *connClient {
+	return &connClient{conn: make(chanCloser)}
+}
+
+func isClosed(c client.Client) bool {
+	select {
+	case <-c.(*connClient).conn:
+		return true
+	default:
+		return false
+	}
+}
+
+// dialer returns the clients in turn.
+func dialer(clients ...*connClient) dialFunc {
+	next := 0
+	return func(...client.Option) (client.Client, error) {
+		cli := clients[next]
+		next++
+		return cli, nil
+	}
+}
+
+func newPoolOptions(t *testing.T) *pool.BuilderOptions {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	return &pool.BuilderOptions{Key: &key.PrivateKey, NodeRequestTimeout: time.Second}
+}
+
+func TestNodePool(t *testing.T) {
+	ctx := context.Background()
+	peers := []peer{{Address: "s01:8080", Weight: 1}, {Address: "s02:8080", Weight: 1}}
+
+	t.Run("clients of unhealthy nodes are kept", func(t *testing.T) {
+		first, second := newConnClient(), newConnClient()
+		p, err := newNodePool(ctx, peers, newPoolOptions(t), dialer(first, second))
+		require.NoError(t, err)
+		require.Equal(t, []client.Client{first, second}, p.clients())
+
+		second.down = true
+		p.updateHealth(ctx)
+		for i := 0; i < 10; i++ {
+			cli, _, err := p.Connection()
+			require.NoError(t, err)
+			require.Equal(t, first, cli)
+		}
+		require.Equal(t, []client.Client{first, second}, p.clients())
+
+		first.down = true
+		p.updateHealth(ctx)
+		_, _, err = p.Connection()
+		require.ErrorIs(t, err, errNoHealthyNode)
+
+		second.down = false
+		p.updateHealth(ctx)
+		cli, _, err := p.Connection()
+		require.NoError(t, err)
+		require.Equal(t, second, cli)
+	})
+
+	t.Run("dialled clients are closed on error", func(t *testing.T) {
+		first, second := newConnClient(), newConnClient()
+		second.down = true
+
+		_, err := newNodePool(ctx, peers, newPoolOptions(t), dialer(first, second))
+		require.Error(t, err)
+		require.True(t, isClosed(first))
+		require.True(t, isClosed(second))
+	})
+}
+
+// blockingPool blocks GetObject and NetworkInfo calls until released.
+type blockingPool struct {
+	*memory.Pool
+	started chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingPool) block() {
+	p.started <- struct{}{}
+	<-p.release
+}
+
+func (p *blockingPool) GetObject(context.Context, *client.GetObjectParams, ...client.CallOption) (*object.Object, error) {
+	p.block()
+	return nil, nil
+}
+
+func (p *blockingPool) NetworkInfo(context.Context, ...client.CallOption) (*netmap.NetworkInfo, error) {
+	p.block()
+	return netmap.NewNetworkInfo(), nil
+}
+
+func newBlockingPool(t *testing.T) *blockingPool {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	mem, err := memory.NewPool(key)
+	require.NoError(t, err)
+	return &blockingPool{Pool: mem, started: make(chan struct{}), release: make(chan struct{})}
+}
+
+func TestReloadablePoolSwap(t *testing.T) {
+	calls := map[string]func(p pool.Pool) error{
+		"GetObject": func(p pool.Pool) error {
+			_, err := p.GetObject(context.Background(), new(client.GetObjectParams))
+			return err
+		},
+		"NetworkInfo": func(p pool.Pool) error {
+			_, err := neofs.NetworkInfo(context.Background(), p)
+			return err
+		},
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			oldPool, newPool := newBlockingPool(t), newBlockingPool(t)
+			oldClient, newClient := newConnClient(), newConnClient()
+
+			r := newReloadablePool(zap.NewNop(), &connections{Pool: oldPool, clients: []client.Client{oldClient}})
+			// the pool is wrapped like in the gateway
+			wrapped := metrics.WrapPool(tracing.WrapPool(r))
+
+			done := make(chan error)
+			go func() { done <- call(wrapped) }()
+			<-oldPool.started
+
+			r.Swap(&connections{Pool: newPool, clients: []client.Client{newClient}})
+			require.Equal(t, newPool.OwnerID(), wrapped.OwnerID())
+
+			time.Sleep(50 * time.Millisecond)
+			require.False(t, isClosed(oldClient))
+
+			close(oldPool.release)
+			require.NoError(t, <-done)
+			require.Eventually(t, func() bool { return isClosed(oldClient) }, time.Second, 10*time.Millisecond)
+			require.False(t, isClosed(newClient))
+		})
+	}
+
+	t.Run("drain timeout", func(t *testing.T) {
+		cli := newConnClient()
+		r := newReloadablePool(zap.NewNop(), &connections{Pool: newBlockingPool(t)})
+		conns := &connections{Pool: newBlockingPool(t), clients: []client.Client{cli}}
+		conns.calls.Add(1)
+
+		r.close(conns, 10*time.Millisecond)
+		require.True(t, isClosed(cli))
+	})
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"syscall"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api/metrics"
+	"go.uber.org/zap"
+)
+
+// staticSettings can't be changed without restart of the gateway.
+var staticSettings = []string{
+	cfgBackend,
+	cfgWallet,
+	cfgAddress,
+	cfgWalletRetired,
+	cfgListenAddress,
+	cfgListenDomains,
+	cfgListeners,
+	cfgTLSKeyFile,
+	cfgTLSCertFile,
+	cfgTLSReloadInterval,
+	cfgClientCertMapping,
+	cfgSignatureV2Enabled,
+	cfgTrustedProxies,
+	cfgAuthzWebhook,
+	cfgAuthzPolicyFile,
+	cfgAuthzTimeout,
+	cfgAuthzCacheTTL,
+	cfgAuthzCacheSize,
+	cfgAuthzFailOpen,
+	cfgAuthzHeaders,
+	cfgAuditContainerID,
+	cfgAuditSealInterval,
+	cfgAuditJournal,
+	cfgAuditInstance,
+	cfgQuotaAdminKeys,
+	cfgAdminAddress,
+	cfgAdminKeys,
+	cfgQuotaReconcileInterval,
+	cfgContainerSessionFallback,
+	cfgAccessLogEnabled,
+	cfgAccessLogOutput,
+	cfgAccessLogMaxSize,
+	cfgAccessLogMaxBackups,
+	cfgAccessLogMaxAge,
+	cfgAccessLogCompress,
+	cfgAccessLogSampleRatio,
+	cfgAccessLogRedact,
+	cfgTracingEnabled,
+	cfgTracingExporter,
+	cfgTracingEndpoint,
+	cfgTracingInsecure,
+	cfgTracingSampleRatio,
+	cfgEnableMetrics,
+	cfgEnableProfiler,
+	cfgHealthcheckInterval,
+	cfgLoggerFormat,
+	cfgLoggerTraceLevel,
+	cfgLoggerNoCaller,
+	cfgLoggerNoDisclaimer,
+	cfgLoggerSamplingInitial,
+	cfgLoggerSamplingThereafter,
+}
+
+// poolSettings require connection pool to be rebuilt.
+var poolSettings = []string{
+	cfgConnectTimeout,
+	cfgRequestTimeout,
+	cfgRebalanceTimer,
+}
+
+// ReloadOnSignal re-reads configuration file and applies it on SIGHUP
+// until the context is done.
+func (a *App) ReloadOnSignal(ctx context.Context) {
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGHUP)
+	defer signal.Stop(sigs)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sigs:
+			a.log.Info("SIGHUP received, reloading configuration")
+			a.reload(ctx)
+		}
+	}
+}
+
+func (a *App) reload(ctx context.Context) {
+	path := a.cfg.GetString(cmdConfig)
+	if path == "" {
+		a.log.Warn("config file is not set, nothing to reload")
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	old := a.snapshotSettings(append(append(staticSettings, poolSettings...), cfgLoggerLevel))
+
+	cfgFile, err := os.Open(path)
+	if err != nil {
+		a.log.Error("could not open config file, reload rejected", zap.Error(err))
+		return
+	}
+	defer cfgFile.Close()
+
+	if err = a.cfg.ReadConfig(cfgFile); err != nil {
+		a.log.Error("could not read config file, reload rejected", zap.Error(err))
+		return
+	}
+
+	for _, key := range staticSettings {
+		if !reflect.DeepEqual(old[key], a.cfg.Get(key)) {
+			a.log.Warn("setting can't be changed without restart, change rejected",
+				zap.String("setting", key))
+			a.cfg.Set(key, old[key])
+		}
+	}
+
+	a.reloadLogLevel(old[cfgLoggerLevel])
+	a.maxClients.Update(getMaxClientsOptions(a.cfg))
+	a.rateLimiter.Update(getRateLimits(a.cfg, a.log))
+	a.reloadDefaultPolicy()
+	a.reloadCaches()
+	metrics.EnableBucketMetrics(a.cfg.GetBool(cfgBucketMetrics))
+	a.reloadPool(ctx, old)
+
+	a.log.Info("configuration reloaded")
+}
+
+func (a *App) snapshotSettings(keys []string) map[string]interface{} {
+	res := make(map[string]interface{}, len(keys))
+	for _, key := range keys {
+		res[key] = a.cfg.Get(key)
+	}
+
+	return res
+}
+
+func (a *App) reloadLogLevel(old interface{}) {
+	lvl, err := parseLogLevel(a.cfg.GetString(cfgLoggerLevel))
+	if err != nil {
+		a.log.Error("invalid log level, change rejected", zap.Error(err))
+		a.cfg.Set(cfgLoggerLevel, old)
+		return
+	}
+
+	a.lvl.SetLevel(lvl)
+}
+
+func (a *App) reloadDefaultPolicy() {
+	p, err := getDefaultPolicy(a.cfg)
+	if err != nil {
+		a.log.Error("couldn't parse container default policy, change rejected", zap.Error(err))
+		return
+	}
+
+	a.handlerCfg.SetDefaultPolicy(p)
+}
+
+func (a *App) reloadCaches() {
+	cacheCfg := getCacheOptions(a.cfg, a.log)
+	if *cacheCfg == *a.cacheCfg {
+		return
+	}
+
+	a.obj.UpdateCaches(cacheCfg)
+	a.cacheCfg = cacheCfg
+	a.log.Info("caches re-created")
+}
+
+func (a *App) reloadPool(ctx context.Context, old map[string]interface{}) {
+	if a.cfg.GetString(cfgBackend) == backendMemory {
+		return
+	}
+
+	peers := fetchPeers(a.log, a.cfg)
+
+	changed := !reflect.DeepEqual(peers, a.peers)
+	for _, key := range poolSettings {
+		changed = changed || !reflect.DeepEqual(old[key], a.cfg.Get(key))
+	}
+	if !changed {
+		return
+	}
+
+	conns, err := buildConnections(ctx, a.cfg, peers, a.key)
+	if err != nil {
+		a.log.Error("failed to create connection pool, using the old one", zap.Error(err))
+		return
+	}
+
+	a.pool.Swap(conns)
+	a.peers = peers
+	a.hc.UpdatePeers(fetchPeerAddresses(a.cfg))
+	a.log.Info("connection pool rebuilt")
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestReload(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s3-gw-reload")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "config.yaml")
+	writeConfig := func(data string) {
+		require.NoError(t, ioutil.WriteFile(path, []byte(data), 0644))
+	}
+
+	writeConfig("listen_address: 0.0.0.0:8080\nlogger:\n  level: info\n")
+
+	v := viper.New()
+	v.SetConfigType("yaml")
+	v.Set(cmdConfig, path)
+	cfgFile, err := os.Open(path)
+	require.NoError(t, err)
+	require.NoError(t, v.ReadConfig(cfgFile))
+	require.NoError(t, cfgFile.Close())
+
+	l := zap.NewNop()
+	a := &App{
+		log:        l,
+		lvl:        zap.NewAtomicLevelAt(zap.InfoLevel),
+		cfg:        v,
+		handlerCfg: getHandlerOptions(v, l),
+		cacheCfg:   getCacheOptions(v, l),
+		peers:      fetchPeers(l, v),
+		maxClients: api.NewMaxClientsMiddleware(defaultMaxClientsCount, defaultMaxClientsDeadline),
+	}
+	a.rateLimiter = api.NewRateLimiter(getRateLimits(v, l))
+
+	t.Run("apply changes", func(t *testing.T) {
+		writeConfig("listen_address: 0.0.0.0:8080\nlogger:\n  level: warn\ndefault_policy: REP 1\n")
+		a.reload(context.Background())
+
+		require.Equal(t, zap.WarnLevel, a.lvl.Level())
+		require.Equal(t, uint32(1), a.handlerCfg.DefaultPolicy.Replicas()[0].Count())
+	})
+
+	t.Run("reject static and invalid changes", func(t *testing.T) {
+		writeConfig("listen_address: 0.0.0.0:9090\nlogger:\n  level: verbose\ndefault_policy: REP\n")
+		a.reload(context.Background())
+
+		require.Equal(t, "0.0.0.0:8080", a.cfg.GetString(cfgListenAddress))
+		require.Equal(t, zap.WarnLevel, a.lvl.Level())
+		require.Equal(t, uint32(1), a.handlerCfg.DefaultPolicy.Replicas()[0].Count())
+	})
+
+	t.Run("rate limits", func(t *testing.T) {
+		writeConfig(`listen_address: 0.0.0.0:8080
+logger:
+  level: warn
+rate_limits:
+  default:
+    requests_per_second: 10
+    concurrency: 4
+  overrides:
+    0:
+      key: 10.0.0.1
+      bandwidth: 1048576
+    1:
+      key: BJeErH9MWmf52VsR1mLWKkgF3pRm3FkubYxM7TZkBP4K0ABeXKYhTJk8MJnoQm3Z5YwR4SS1N6Kfk1mtY6v4KhUj
+      requests_per_second: 100
+      burst: 200
+`)
+		a.reload(context.Background())
+
+		cfg := getRateLimits(a.cfg, a.log)
+		require.Equal(t, api.Limits{RequestsPerSecond: 10, Concurrency: 4}, cfg.Default)
+		require.Equal(t, map[string]api.Limits{
+			"10.0.0.1": {Bandwidth: 1048576},
+			"BJeErH9MWmf52VsR1mLWKkgF3pRm3FkubYxM7TZkBP4K0ABeXKYhTJk8MJnoQm3Z5YwR4SS1N6Kfk1mtY6v4KhUj": {RequestsPerSecond: 100, Burst: 200},
+		}, cfg.Overrides)
+	})
+}
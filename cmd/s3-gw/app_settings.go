@@ -8,8 +8,10 @@ import (
 	"strings"
 	"time"
 
+	"github.com/nspcc-dev/neofs-s3-gw/api"
+	"github.com/nspcc-dev/neofs-s3-gw/api/layer"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/tracing"
 	"github.com/nspcc-dev/neofs-s3-gw/internal/version"
-	"github.com/nspcc-dev/neofs-sdk-go/pool"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
@@ -27,6 +29,17 @@ const (
 
 	defaultMaxClientsCount    = 100
 	defaultMaxClientsDeadline = time.Second * 30
+
+	defaultHealthcheckInterval = 10 * time.Second
+
+	defaultCertReloadInterval = time.Minute
+
+	defaultQuotaReconcileInterval = time.Hour
+
+	defaultAuditSealInterval = time.Minute
+
+	defaultAuthzTimeout  = time.Second
+	defaultAuthzCacheTTL = 30 * time.Second
 )
 
 const ( // Settings.
@@ -43,10 +56,41 @@ const ( // Settings.
 	cfgWallet           = "wallet"
 	cfgAddress          = "address"
 	cfgWalletPassphrase = "wallet.passphrase"
+	cfgWalletRetired    = "wallet.retired"
 
 	// HTTPS/TLS.
-	cfgTLSKeyFile  = "tls.key_file"
-	cfgTLSCertFile = "tls.cert_file"
+	cfgTLSKeyFile        = "tls.key_file"
+	cfgTLSCertFile       = "tls.cert_file"
+	cfgTLSReloadInterval = "tls.reload_interval"
+
+	// Listeners.
+	cfgListeners = "listeners"
+
+	// Authentication.
+	cfgClientCertMapping  = "client_certs.mapping_file"
+	cfgSignatureV2Enabled = "signature_v2.enabled"
+
+	// External authorization.
+	cfgAuthzWebhook    = "authz.webhook"
+	cfgAuthzPolicyFile = "authz.policy_file"
+	cfgAuthzTimeout    = "authz.timeout"
+	cfgAuthzCacheTTL   = "authz.cache_ttl"
+	cfgAuthzCacheSize  = "authz.cache_size"
+	cfgAuthzFailOpen   = "authz.fail_open"
+	cfgAuthzHeaders    = "authz.headers"
+
+	// Audit trail.
+	cfgAuditContainerID  = "audit.container_id"
+	cfgAuditSealInterval = "audit.seal_interval"
+	cfgAuditJournal      = "audit.journal"
+	cfgAuditInstance     = "audit.instance"
+
+	// Tracing.
+	cfgTracingEnabled     = "tracing.enabled"
+	cfgTracingExporter    = "tracing.exporter"
+	cfgTracingEndpoint    = "tracing.endpoint"
+	cfgTracingInsecure    = "tracing.insecure"
+	cfgTracingSampleRatio = "tracing.sample_ratio"
 
 	// Timeouts.
 	cfgConnectionTTL  = "con_ttl"
@@ -63,15 +107,48 @@ const ( // Settings.
 	// Policy.
 	cfgDefaultPolicy = "default_policy"
 
+	// Container operations without session token.
+	cfgContainerSessionFallback = "container_session.fallback"
+
 	// MaxClients.
 	cfgMaxClientsCount    = "max_clients_count"
 	cfgMaxClientsDeadline = "max_clients_deadline"
 
+	// Per-client limits.
+	cfgRateLimitsDefault   = "rate_limits.default"
+	cfgRateLimitsSourceIP  = "rate_limits.source_ip"
+	cfgRateLimitsOverrides = "rate_limits.overrides"
+
+	// Proxies trusted to pass the client address.
+	cfgTrustedProxies = "trusted_proxies"
+
+	// Access log.
+	cfgAccessLogEnabled     = "access_log.enabled"
+	cfgAccessLogOutput      = "access_log.output"
+	cfgAccessLogMaxSize     = "access_log.max_size"
+	cfgAccessLogMaxBackups  = "access_log.max_backups"
+	cfgAccessLogMaxAge      = "access_log.max_age"
+	cfgAccessLogCompress    = "access_log.compress"
+	cfgAccessLogSampleRatio = "access_log.sample_ratio"
+	cfgAccessLogRedact      = "access_log.redact"
+
+	// Admin API.
+	cfgAdminAddress = "admin.address"
+	cfgAdminKeys    = "admin.keys"
+
+	// Bucket quotas.
+	cfgQuotaAdminKeys         = "quota.admin_keys"
+	cfgQuotaReconcileInterval = "quota.reconcile_interval"
+
 	// gRPC.
 	cfgGRPCVerbose = "verbose"
 
+	// Storage backend.
+	cfgBackend = "backend"
+
 	// Metrics / Profiler / Web.
 	cfgEnableMetrics  = "metrics"
+	cfgBucketMetrics  = "metrics_per_bucket"
 	cfgEnableProfiler = "pprof"
 	cfgListenAddress  = "listen_address"
 	cfgListenDomains  = "listen_domains"
@@ -79,6 +156,9 @@ const ( // Settings.
 	// Peers.
 	cfgPeers = "peers"
 
+	// Healthcheck.
+	cfgHealthcheckInterval = "healthcheck.interval"
+
 	// Application.
 	cfgApplicationName      = "app.name"
 	cfgApplicationVersion   = "app.version"
@@ -101,14 +181,16 @@ var ignore = map[string]struct{}{
 	cfgApplicationVersion:   {},
 	cfgApplicationBuildTime: {},
 
-	cfgPeers: {},
+	cfgPeers:               {},
+	cfgListeners:           {},
+	cfgRateLimitsOverrides: {},
 
 	cmdHelp:    {},
 	cmdVersion: {},
 }
 
-func fetchPeers(l *zap.Logger, v *viper.Viper) *pool.Builder {
-	pb := new(pool.Builder)
+func fetchPeers(l *zap.Logger, v *viper.Viper) []peer {
+	var peers []peer
 
 	for i := 0; ; i++ {
 		key := cfgPeers + "." + strconv.Itoa(i) + "."
@@ -122,14 +204,75 @@ func fetchPeers(l *zap.Logger, v *viper.Viper) *pool.Builder {
 		if weight <= 0 { // unspecified or wrong
 			weight = 1
 		}
-		pb.AddNode(address, weight)
+		peers = append(peers, peer{Address: address, Weight: weight})
 
 		l.Info("added connection peer",
 			zap.String("address", address),
 			zap.Float64("weight", weight))
 	}
 
-	return pb
+	return peers
+}
+
+func fetchPeerAddresses(v *viper.Viper) []string {
+	var res []string
+	for i := 0; ; i++ {
+		address := v.GetString(cfgPeers + "." + strconv.Itoa(i) + ".address")
+		if address == "" {
+			break
+		}
+
+		res = append(res, address)
+	}
+
+	return res
+}
+
+func fetchServers(v *viper.Viper) []serverInfo {
+	var servers []serverInfo
+
+	for i := 0; ; i++ {
+		key := cfgListeners + "." + strconv.Itoa(i) + "."
+		address := v.GetString(key + "address")
+		if address == "" {
+			break
+		}
+
+		srv := serverInfo{
+			Address:      address,
+			ClientCAFile: v.GetString(key + "client_ca_file"),
+		}
+
+		for j := 0; ; j++ {
+			certKey := key + "certificates." + strconv.Itoa(j) + "."
+			cert := tlsConfig{
+				CertFile: v.GetString(certKey + "cert_file"),
+				KeyFile:  v.GetString(certKey + "key_file"),
+			}
+			if cert.CertFile == "" || cert.KeyFile == "" {
+				break
+			}
+
+			srv.Certificates = append(srv.Certificates, cert)
+		}
+
+		servers = append(servers, srv)
+	}
+
+	if len(servers) > 0 {
+		return servers
+	}
+
+	// fallback to a single listener
+	srv := serverInfo{Address: v.GetString(cfgListenAddress)}
+	if v.IsSet(cfgTLSKeyFile) && v.IsSet(cfgTLSCertFile) {
+		srv.Certificates = []tlsConfig{{
+			KeyFile:  v.GetString(cfgTLSKeyFile),
+			CertFile: v.GetString(cfgTLSCertFile),
+		}}
+	}
+
+	return []serverInfo{srv}
 }
 
 func fetchDomains(v *viper.Viper) []string {
@@ -186,6 +329,7 @@ func newSettings() *viper.Viper {
 	flags.String(cfgTLSKeyFile, "", "TLS key file to use")
 
 	peers := flags.StringArrayP(cfgPeers, "p", nil, "set NeoFS nodes")
+	flags.String(cfgBackend, backendNeoFS, "storage backend: neofs or memory (in-process storage for development and tests)")
 
 	domains := flags.StringArrayP(cfgListenDomains, "d", nil, "set domains to be listened")
 
@@ -204,6 +348,44 @@ func newSettings() *viper.Viper {
 	v.SetDefault(cfgLoggerSamplingInitial, 1000)
 	v.SetDefault(cfgLoggerSamplingThereafter, 1000)
 
+	// healthcheck:
+	v.SetDefault(cfgHealthcheckInterval, defaultHealthcheckInterval)
+
+	// tls:
+	v.SetDefault(cfgTLSReloadInterval, defaultCertReloadInterval)
+
+	// metrics:
+	v.SetDefault(cfgBucketMetrics, false)
+
+	// access log:
+	v.SetDefault(cfgAccessLogEnabled, false)
+	v.SetDefault(cfgAccessLogOutput, accessLogStdout)
+	v.SetDefault(cfgAccessLogMaxSize, 100)
+	v.SetDefault(cfgAccessLogSampleRatio, 1.0)
+
+	// quota:
+	v.SetDefault(cfgQuotaReconcileInterval, defaultQuotaReconcileInterval)
+
+	// signature v2:
+	v.SetDefault(cfgSignatureV2Enabled, false)
+
+	// authz:
+	v.SetDefault(cfgAuthzTimeout, defaultAuthzTimeout)
+	v.SetDefault(cfgAuthzCacheTTL, defaultAuthzCacheTTL)
+	v.SetDefault(cfgAuthzCacheSize, api.DefaultAuthzCacheSize)
+	v.SetDefault(cfgAuthzFailOpen, false)
+
+	// audit:
+	v.SetDefault(cfgAuditSealInterval, defaultAuditSealInterval)
+
+	// container session:
+	v.SetDefault(cfgContainerSessionFallback, string(layer.SessionFallbackGateway))
+
+	// tracing:
+	v.SetDefault(cfgTracingEnabled, false)
+	v.SetDefault(cfgTracingExporter, tracing.ExporterOTLP)
+	v.SetDefault(cfgTracingSampleRatio, 1.0)
+
 	if err := v.BindPFlags(flags); err != nil {
 		panic(err)
 	}
@@ -254,6 +436,15 @@ func newSettings() *viper.Viper {
 		fmt.Printf("%s_%s_[N]_ADDRESS = string\n", envPrefix, strings.ToUpper(cfgPeers))
 		fmt.Printf("%s_%s_[N]_WEIGHT = 0..1 (float)\n", envPrefix, strings.ToUpper(cfgPeers))
 
+		fmt.Println()
+		fmt.Println("Listeners preset:")
+		fmt.Println()
+
+		fmt.Printf("%s_%s_[N]_ADDRESS = string\n", envPrefix, strings.ToUpper(cfgListeners))
+		fmt.Printf("%s_%s_[N]_CERTIFICATES_[M]_CERT_FILE = string\n", envPrefix, strings.ToUpper(cfgListeners))
+		fmt.Printf("%s_%s_[N]_CERTIFICATES_[M]_KEY_FILE = string\n", envPrefix, strings.ToUpper(cfgListeners))
+		fmt.Printf("%s_%s_[N]_CLIENT_CA_FILE = string\n", envPrefix, strings.ToUpper(cfgListeners))
+
 		os.Exit(0)
 	case versionFlag != nil && *versionFlag:
 		fmt.Printf("NeoFS S3 gateway %s\n", version.Version)
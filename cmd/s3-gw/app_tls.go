@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+type (
+	// serverInfo describes a listener of the gateway.
+	serverInfo struct {
+		Address      string
+		Certificates []tlsConfig
+		ClientCAFile string
+	}
+
+	// certStore keeps TLS certificates of a listener and reloads them
+	// when files are changed on disk.
+	certStore struct {
+		log   *zap.Logger
+		files []tlsConfig
+
+		mu       sync.RWMutex
+		certs    []*tls.Certificate
+		modTimes []time.Time
+	}
+)
+
+// TLS reports whether the listener serves HTTPS.
+func (s serverInfo) TLS() bool {
+	return len(s.Certificates) > 0
+}
+
+func newCertStore(l *zap.Logger, files []tlsConfig) (*certStore, error) {
+	s := &certStore{
+		log:      l,
+		files:    files,
+		certs:    make([]*tls.Certificate, len(files)),
+		modTimes: make([]time.Time, len(files)),
+	}
+
+	for i := range files {
+		cert, modTime, err := loadCertificate(files[i])
+		if err != nil {
+			return nil, err
+		}
+		s.certs[i], s.modTimes[i] = cert, modTime
+	}
+
+	return s, nil
+}
+
+func loadCertificate(files tlsConfig) (*tls.Certificate, time.Time, error) {
+	modTime, err := lastModified(files.CertFile, files.KeyFile)
+	if err != nil {
+		return nil, modTime, err
+	}
+
+	cert, err := tls.LoadX509KeyPair(files.CertFile, files.KeyFile)
+	if err != nil {
+		return nil, modTime, fmt.Errorf("could not load key pair %s: %w", files.CertFile, err)
+	}
+
+	if cert.Leaf, err = x509.ParseCertificate(cert.Certificate[0]); err != nil {
+		return nil, modTime, fmt.Errorf("could not parse certificate %s: %w", files.CertFile, err)
+	}
+
+	return &cert, modTime, nil
+}
+
+func lastModified(files ...string) (time.Time, error) {
+	var res time.Time
+	for _, file := range files {
+		info, err := os.Stat(file)
+		if err != nil {
+			return res, err
+		}
+		if info.ModTime().After(res) {
+			res = info.ModTime()
+		}
+	}
+
+	return res, nil
+}
+
+// Start checks certificate files with the given interval and reloads
+// the changed ones until the context is done.
+func (s *certStore) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.reload()
+		}
+	}
+}
+
+func (s *certStore) reload() {
+	for i, files := range s.files {
+		modTime, err := lastModified(files.CertFile, files.KeyFile)
+		if err != nil {
+			s.log.Error("could not check certificate files", zap.Error(err))
+			continue
+		}
+
+		s.mu.RLock()
+		changed := modTime.After(s.modTimes[i])
+		s.mu.RUnlock()
+		if !changed {
+			continue
+		}
+
+		// previous certificate is used until the new one is loaded successfully
+		cert, modTime, err := loadCertificate(files)
+		if err != nil {
+			s.log.Error("could not reload certificate", zap.Error(err))
+			continue
+		}
+
+		s.mu.Lock()
+		s.certs[i], s.modTimes[i] = cert, modTime
+		s.mu.Unlock()
+
+		s.log.Info("certificate reloaded",
+			zap.String("cert", files.CertFile),
+			zap.Time("not_after", cert.Leaf.NotAfter))
+	}
+}
+
+// GetCertificate returns the certificate valid for the server name requested
+// by the client (SNI). The first certificate is used if there is no such one.
+func (s *certStore) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if hello.ServerName != "" {
+		for _, cert := range s.certs {
+			if cert.Leaf.VerifyHostname(hello.ServerName) == nil {
+				return cert, nil
+			}
+		}
+	}
+
+	return s.certs[0], nil
+}
+
+// Covers reports whether any of the certificates is valid for the domain.
+func (s *certStore) Covers(domain string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, cert := range s.certs {
+		if cert.Leaf.VerifyHostname(domain) == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *certStore) tlsConfig(clientCAFile string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: s.GetCertificate,
+	}
+
+	if clientCAFile == "" {
+		return cfg, nil
+	}
+
+	data, err := ioutil.ReadFile(clientCAFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read client CA file: %w", err)
+	}
+
+	cfg.ClientCAs = x509.NewCertPool()
+	if !cfg.ClientCAs.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in client CA file %s", clientCAFile)
+	}
+	cfg.ClientAuth = tls.VerifyClientCertIfGiven
+
+	return cfg, nil
+}
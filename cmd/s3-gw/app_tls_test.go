@@ -0,0 +1,132 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func writeCertificate(t *testing.T, dir, name string, serial int64, domains ...string) tlsConfig {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(serial),
+		Subject:      pkix.Name{CommonName: domains[0]},
+		DNSNames:     domains,
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	files := tlsConfig{
+		CertFile: filepath.Join(dir, name+".crt"),
+		KeyFile:  filepath.Join(dir, name+".key"),
+	}
+	require.NoError(t, ioutil.WriteFile(files.CertFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, ioutil.WriteFile(files.KeyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+
+	return files
+}
+
+func TestCertStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "s3-gw-tls")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	first := writeCertificate(t, dir, "first", 1, "s3.first.io", "*.s3.first.io")
+	second := writeCertificate(t, dir, "second", 2, "s3.second.io", "*.s3.second.io")
+
+	store, err := newCertStore(zap.NewNop(), []tlsConfig{first, second})
+	require.NoError(t, err)
+
+	serial := func(serverName string) int64 {
+		cert, err := store.GetCertificate(&tls.ClientHelloInfo{ServerName: serverName})
+		require.NoError(t, err)
+		return cert.Leaf.SerialNumber.Int64()
+	}
+
+	t.Run("sni", func(t *testing.T) {
+		require.Equal(t, int64(1), serial("bucket.s3.first.io"))
+		require.Equal(t, int64(2), serial("s3.second.io"))
+		require.Equal(t, int64(2), serial("bucket.s3.second.io"))
+		require.Equal(t, int64(1), serial("unknown.io"))
+		require.Equal(t, int64(1), serial(""))
+
+		require.True(t, store.Covers("s3.second.io"))
+		require.False(t, store.Covers("s3.third.io"))
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		writeCertificate(t, dir, "second", 3, "s3.second.io", "*.s3.second.io")
+		future := time.Now().Add(time.Minute)
+		require.NoError(t, os.Chtimes(second.CertFile, future, future))
+
+		store.reload()
+		require.Equal(t, int64(3), serial("s3.second.io"))
+	})
+
+	t.Run("broken file keeps old certificate", func(t *testing.T) {
+		require.NoError(t, ioutil.WriteFile(first.CertFile, []byte("garbage"), 0600))
+		future := time.Now().Add(2 * time.Minute)
+		require.NoError(t, os.Chtimes(first.CertFile, future, future))
+
+		store.reload()
+		require.Equal(t, int64(1), serial("s3.first.io"))
+	})
+}
+
+func TestFetchServers(t *testing.T) {
+	t.Run("fallback to listen address", func(t *testing.T) {
+		v := viper.New()
+		v.Set(cfgListenAddress, "0.0.0.0:8080")
+
+		servers := fetchServers(v)
+		require.Len(t, servers, 1)
+		require.Equal(t, "0.0.0.0:8080", servers[0].Address)
+		require.False(t, servers[0].TLS())
+	})
+
+	t.Run("listeners", func(t *testing.T) {
+		v := viper.New()
+		v.Set(cfgListenAddress, "0.0.0.0:8080")
+		v.Set(cfgListeners+".0.address", "127.0.0.1:8080")
+		v.Set(cfgListeners+".1.address", "0.0.0.0:8443")
+		v.Set(cfgListeners+".1.certificates.0.cert_file", "first.crt")
+		v.Set(cfgListeners+".1.certificates.0.key_file", "first.key")
+		v.Set(cfgListeners+".1.certificates.1.cert_file", "second.crt")
+		v.Set(cfgListeners+".1.certificates.1.key_file", "second.key")
+		v.Set(cfgListeners+".1.client_ca_file", "ca.crt")
+
+		servers := fetchServers(v)
+		require.Equal(t, []serverInfo{
+			{Address: "127.0.0.1:8080"},
+			{
+				Address: "0.0.0.0:8443",
+				Certificates: []tlsConfig{
+					{CertFile: "first.crt", KeyFile: "first.key"},
+					{CertFile: "second.crt", KeyFile: "second.key"},
+				},
+				ClientCAFile: "ca.crt",
+			},
+		}, servers)
+	})
+}
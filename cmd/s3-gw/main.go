@@ -8,11 +8,49 @@ import (
 	"github.com/nspcc-dev/neofs-sdk-go/logger"
 	"github.com/spf13/viper"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
-func newLogger(v *viper.Viper) *zap.Logger {
+// atomicLevelCore filters log entries by the level which can be changed at runtime.
+type atomicLevelCore struct {
+	zapcore.Core
+	level zap.AtomicLevel
+}
+
+func (c *atomicLevelCore) Enabled(lvl zapcore.Level) bool {
+	return c.level.Enabled(lvl) && c.Core.Enabled(lvl)
+}
+
+func (c *atomicLevelCore) With(fields []zapcore.Field) zapcore.Core {
+	return &atomicLevelCore{Core: c.Core.With(fields), level: c.level}
+}
+
+func (c *atomicLevelCore) Check(e zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.level.Enabled(e.Level) {
+		return ce
+	}
+	return c.Core.Check(e, ce)
+}
+
+func parseLogLevel(s string) (zapcore.Level, error) {
+	var lvl zapcore.Level
+	err := lvl.UnmarshalText([]byte(s))
+	return lvl, err
+}
+
+func newLogger(v *viper.Viper) (*zap.Logger, zap.AtomicLevel) {
+	lvl, err := parseLogLevel(v.GetString(cfgLoggerLevel))
+	if err != nil {
+		lvl = zap.InfoLevel
+	}
+	level := zap.NewAtomicLevelAt(lvl)
+
 	options := []logger.Option{
-		logger.WithLevel(v.GetString(cfgLoggerLevel)),
+		// the level is controlled by atomicLevelCore, so it can be changed on reload
+		logger.WithLevel(zap.DebugLevel.String()),
+		logger.WithZapOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return &atomicLevelCore{Core: core, level: level}
+		})),
 		logger.WithTraceLevel(v.GetString(cfgLoggerTraceLevel)),
 
 		logger.WithFormat(v.GetString(cfgLoggerFormat)),
@@ -37,18 +75,19 @@ func newLogger(v *viper.Viper) *zap.Logger {
 		panic(err)
 	}
 
-	return l
+	return l, level
 }
 
 func main() {
 	var (
-		v    = newSettings()
-		l    = newLogger(v)
-		g, _ = signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
-		a    = newApp(g, l, v)
+		v        = newSettings()
+		l, level = newLogger(v)
+		g, _     = signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+		a        = newApp(g, l, level, v)
 	)
 
 	go a.Server(g)
+	go a.ReloadOnSignal(g)
 
 	a.Wait()
 }
@@ -7,6 +7,7 @@ import (
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 
@@ -19,10 +20,17 @@ import (
 	"google.golang.org/protobuf/proto"
 )
 
+// ErrNoGateData is returned when the box is not encrypted for any of the
+// given keys.
+var ErrNoGateData = errors.New("no gate data was found")
+
 // Box represents friendly AccessBox.
 type Box struct {
 	Gate     *GateData
 	Policies []*ContainerPolicy
+	// Replaced is set if the requested box can't be decrypted and this box
+	// replacing it is returned instead.
+	Replaced bool
 }
 
 // ContainerPolicy represents friendly AccessBox_ContainerPolicy.
@@ -33,10 +41,12 @@ type ContainerPolicy struct {
 
 // GateData represents gate tokens in AccessBox.
 type GateData struct {
-	AccessKey    string
-	BearerToken  *token.BearerToken
-	SessionToken *session.Token
-	GateKey      *keys.PublicKey
+	AccessKey   string
+	BearerToken *token.BearerToken
+	// SessionTokens are container session tokens, every token allows one
+	// operation, so there can be a token for each of them.
+	SessionTokens []*session.Token
+	GateKey       *keys.PublicKey
 }
 
 // NewGateData returns GateData from provided bearer token and public gate key.
@@ -44,6 +54,30 @@ func NewGateData(gateKey *keys.PublicKey, bearerTkn *token.BearerToken) *GateDat
 	return &GateData{GateKey: gateKey, BearerToken: bearerTkn}
 }
 
+// SessionTokenForPut returns the first session token allowing to create containers.
+func (g *GateData) SessionTokenForPut() *session.Token {
+	return g.sessionToken((*session.ContainerContext).IsForPut)
+}
+
+// SessionTokenForDelete returns the first session token allowing to delete containers.
+func (g *GateData) SessionTokenForDelete() *session.Token {
+	return g.sessionToken((*session.ContainerContext).IsForDelete)
+}
+
+// SessionTokenForSetEACL returns the first session token allowing to set eACL of containers.
+func (g *GateData) SessionTokenForSetEACL() *session.Token {
+	return g.sessionToken((*session.ContainerContext).IsForSetEACL)
+}
+
+func (g *GateData) sessionToken(allowed func(*session.ContainerContext) bool) *session.Token {
+	for _, tkn := range g.SessionTokens {
+		if sessionCtx, ok := tkn.Context().(*session.ContainerContext); ok && sessionCtx != nil && allowed(sessionCtx) {
+			return tkn
+		}
+	}
+	return nil
+}
+
 // Secrets represents AccessKey and key to encrypt gate tokens.
 type Secrets struct {
 	AccessKey    string
@@ -60,9 +94,29 @@ func (x *AccessBox) Unmarshal(data []byte) error {
 	return proto.Unmarshal(data, x)
 }
 
-// PackTokens adds a bearer and session tokens to BearerTokens and SessionToken lists respectively.
-// Session token can be nil.
+// PackTokens adds a bearer and session tokens to BearerTokens and SessionTokens lists respectively.
+// Session tokens can be empty.
 func PackTokens(gatesData []*GateData) (*AccessBox, *Secrets, error) {
+	secret, err := generateSecret()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to generate accessKey as hex: %w", err)
+	}
+
+	return packTokens(gatesData, secret)
+}
+
+// PackTokensWithAccessKey is the same as PackTokens, but keeps the given
+// hex-encoded secret access key.
+func PackTokensWithAccessKey(gatesData []*GateData, accessKey string) (*AccessBox, *Secrets, error) {
+	secret, err := hex.DecodeString(accessKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to decode accessKey: %w", err)
+	}
+
+	return packTokens(gatesData, secret)
+}
+
+func packTokens(gatesData []*GateData, secret []byte) (*AccessBox, *Secrets, error) {
 	box := &AccessBox{}
 	ephemeralKey, err := keys.NewPrivateKey()
 	if err != nil {
@@ -70,11 +124,6 @@ func PackTokens(gatesData []*GateData) (*AccessBox, *Secrets, error) {
 	}
 	box.OwnerPublicKey = ephemeralKey.PublicKey().Bytes()
 
-	secret, err := generateSecret()
-	if err != nil {
-		return nil, nil, fmt.Errorf("failed to generate accessKey as hex: %w", err)
-	}
-
 	if err := box.addTokens(gatesData, ephemeralKey, secret); err != nil {
 		return nil, nil, fmt.Errorf("failed to add tokens to accessbox: %w", err)
 	}
@@ -82,26 +131,31 @@ func PackTokens(gatesData []*GateData) (*AccessBox, *Secrets, error) {
 	return box, &Secrets{hex.EncodeToString(secret), ephemeralKey}, err
 }
 
-// GetTokens returns gate tokens from AccessBox.
-func (x *AccessBox) GetTokens(owner *keys.PrivateKey) (*GateData, error) {
+// GetTokens returns gate tokens from AccessBox. Retired keys are tried if
+// there is no gate for the owner key, GateKey of the result is the key
+// the tokens were decrypted with.
+func (x *AccessBox) GetTokens(owner *keys.PrivateKey, retired ...*keys.PrivateKey) (*GateData, error) {
 	sender, err := keys.NewPublicKeyFromBytes(x.OwnerPublicKey, elliptic.P256())
 	if err != nil {
 		return nil, fmt.Errorf("couldn't unmarshal OwnerPublicKey: %w", err)
 	}
-	ownerKey := owner.PublicKey().Bytes()
-	for _, gate := range x.Gates {
-		if !bytes.Equal(gate.GatePublicKey, ownerKey) {
-			continue
-		}
 
-		gateData, err := decodeGate(gate, owner, sender)
-		if err != nil {
-			return nil, fmt.Errorf("failed to decode gate: %w", err)
+	for _, key := range append([]*keys.PrivateKey{owner}, retired...) {
+		ownerKey := key.PublicKey().Bytes()
+		for _, gate := range x.Gates {
+			if !bytes.Equal(gate.GatePublicKey, ownerKey) {
+				continue
+			}
+
+			gateData, err := decodeGate(gate, key, sender)
+			if err != nil {
+				return nil, fmt.Errorf("failed to decode gate: %w", err)
+			}
+			return gateData, nil
 		}
-		return gateData, nil
 	}
 
-	return nil, fmt.Errorf("no gate data for key  %x was found", ownerKey)
+	return nil, fmt.Errorf("%w for key %x", ErrNoGateData, owner.PublicKey().Bytes())
 }
 
 // GetPlacementPolicy returns ContainerPolicy from AccessBox.
@@ -122,9 +176,9 @@ func (x *AccessBox) GetPlacementPolicy() ([]*ContainerPolicy, error) {
 	return result, nil
 }
 
-// GetBox parse AccessBox to Box.
-func (x *AccessBox) GetBox(owner *keys.PrivateKey) (*Box, error) {
-	tokens, err := x.GetTokens(owner)
+// GetBox parse AccessBox to Box, retired keys are tried the same way GetTokens does.
+func (x *AccessBox) GetBox(owner *keys.PrivateKey, retired ...*keys.PrivateKey) (*Box, error) {
+	tokens, err := x.GetTokens(owner, retired...)
 	if err != nil {
 		return nil, err
 	}
@@ -146,18 +200,19 @@ func (x *AccessBox) addTokens(gatesData []*GateData, ephemeralKey *keys.PrivateK
 		if err != nil {
 			return fmt.Errorf("%w, sender = %d", err, i)
 		}
-		var encSession []byte
-		if gate.SessionToken != nil {
-			encSession, err = gate.SessionToken.Marshal()
+		encSessions := make([][]byte, 0, len(gate.SessionTokens))
+		for _, sessionTkn := range gate.SessionTokens {
+			encSession, err := sessionTkn.Marshal()
 			if err != nil {
 				return fmt.Errorf("%w, sender = %d", err, i)
 			}
+			encSessions = append(encSessions, encSession)
 		}
 
 		tokens := new(Tokens)
 		tokens.AccessKey = secret
 		tokens.BearerToken = encBearer
-		tokens.SessionToken = encSession
+		tokens.SessionTokens = encSessions
 
 		boxGate, err := encodeGate(ephemeralKey, gate.GateKey, tokens)
 		if err != nil {
@@ -199,14 +254,20 @@ func decodeGate(gate *AccessBox_Gate, owner *keys.PrivateKey, sender *keys.Publi
 	if err := bearerTkn.Unmarshal(tokens.BearerToken); err != nil {
 		return nil, err
 	}
-	sessionTkn := session.NewToken()
-	if err := sessionTkn.Unmarshal(tokens.SessionToken); err != nil {
-		return nil, err
-	}
 
 	gateData := NewGateData(owner.PublicKey(), bearerTkn)
-	gateData.SessionToken = sessionTkn
 	gateData.AccessKey = hex.EncodeToString(tokens.AccessKey)
+
+	// session tokens are optional, boxes issued before there could be
+	// several of them have the only token at the same field
+	for _, encSession := range tokens.SessionTokens {
+		sessionTkn := session.NewToken()
+		if err := sessionTkn.Unmarshal(encSession); err != nil {
+			return nil, err
+		}
+		gateData.SessionTokens = append(gateData.SessionTokens, sessionTkn)
+	}
+
 	return gateData, nil
 }
 
@@ -94,7 +94,7 @@ func Test_session_token_in_access_box(t *testing.T) {
 	require.NoError(t, tkn.Sign(&sec.PrivateKey))
 
 	gate := NewGateData(cred.PublicKey(), token.NewBearerToken())
-	gate.SessionToken = tkn
+	gate.SessionTokens = []*session.Token{tkn}
 	box, _, err = PackTokens([]*GateData{gate})
 	require.NoError(t, err)
 
@@ -107,7 +107,35 @@ func Test_session_token_in_access_box(t *testing.T) {
 	tkns, err := box2.GetTokens(cred)
 	require.NoError(t, err)
 
-	require.Equal(t, tkn, tkns.SessionToken)
+	require.Equal(t, []*session.Token{tkn}, tkns.SessionTokens)
+}
+
+func Test_session_tokens_for_operations(t *testing.T) {
+	cred, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	newToken := func(verb func(*session.ContainerContext)) *session.Token {
+		sessionCtx := session.NewContainerContext()
+		verb(sessionCtx)
+		tkn := session.NewToken()
+		tkn.SetContext(sessionCtx)
+		return tkn
+	}
+
+	gate := NewGateData(cred.PublicKey(), token.NewBearerToken())
+	gate.SessionTokens = []*session.Token{
+		newToken((*session.ContainerContext).ForPut),
+		newToken((*session.ContainerContext).ForSetEACL),
+	}
+	box, _, err := PackTokens([]*GateData{gate})
+	require.NoError(t, err)
+
+	tkns, err := box.GetTokens(cred)
+	require.NoError(t, err)
+	require.Len(t, tkns.SessionTokens, 2)
+	require.Equal(t, tkns.SessionTokens[0], tkns.SessionTokenForPut())
+	require.Equal(t, tkns.SessionTokens[1], tkns.SessionTokenForSetEACL())
+	require.Nil(t, tkns.SessionTokenForDelete())
 }
 
 func Test_accessbox_multiple_keys(t *testing.T) {
@@ -168,5 +196,49 @@ func Test_unknown_key(t *testing.T) {
 	require.NoError(t, err)
 
 	_, err = box.GetTokens(wrongCred)
+	require.ErrorIs(t, err, ErrNoGateData)
+}
+
+func Test_retired_keys(t *testing.T) {
+	current, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	retired, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	tkn := token.NewBearerToken()
+	tkn.SetEACLTable(eacl.NewTable())
+
+	box, secrets, err := PackTokens([]*GateData{NewGateData(retired.PublicKey(), tkn)})
+	require.NoError(t, err)
+
+	_, err = box.GetTokens(current)
 	require.Error(t, err)
+
+	tkns, err := box.GetTokens(current, retired)
+	require.NoError(t, err)
+	require.Equal(t, retired.PublicKey(), tkns.GateKey)
+	require.Equal(t, secrets.AccessKey, tkns.AccessKey)
+
+	// the secret is kept on re-encryption
+	box, _, err = PackTokensWithAccessKey([]*GateData{NewGateData(current.PublicKey(), tkn)}, secrets.AccessKey)
+	require.NoError(t, err)
+
+	tkns, err = box.GetTokens(current, retired)
+	require.NoError(t, err)
+	require.Equal(t, current.PublicKey(), tkns.GateKey)
+	require.Equal(t, secrets.AccessKey, tkns.AccessKey)
+}
+
+func Test_accessbox_without_session_token(t *testing.T) {
+	cred, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	box, _, err := PackTokens([]*GateData{NewGateData(cred.PublicKey(), token.NewBearerToken())})
+	require.NoError(t, err)
+
+	tkns, err := box.GetTokens(cred)
+	require.NoError(t, err)
+	require.Empty(t, tkns.SessionTokens)
+	require.Nil(t, tkns.SessionTokenForPut())
 }
@@ -4,10 +4,12 @@ import (
 	"bytes"
 	"context"
 	"errors"
+	"fmt"
 	"strconv"
 	"sync"
 	"time"
 
+	"github.com/bluele/gcache"
 	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
 	"github.com/nspcc-dev/neofs-api-go/pkg/client"
 	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
@@ -21,12 +23,18 @@ type (
 	// Credentials is a bearer token get/put interface.
 	Credentials interface {
 		GetBox(context.Context, *object.Address) (*accessbox.Box, error)
+		GetAccessBox(context.Context, *object.Address) (*accessbox.AccessBox, error)
 		Put(context.Context, *cid.ID, *owner.ID, *accessbox.AccessBox, ...*keys.PublicKey) (*object.Address, error)
+		PutReplacement(context.Context, *object.Address, *owner.ID, *accessbox.AccessBox, ...*keys.PublicKey) (*object.Address, error)
 	}
 
 	cred struct {
-		key  *keys.PrivateKey
-		pool pool.Pool
+		key     *keys.PrivateKey
+		retired []*keys.PrivateKey
+		pool    pool.Pool
+		// replacements maps addresses of boxes which can't be decrypted to
+		// the addresses of their replacements, nil if there is no replacement.
+		replacements gcache.Cache
 	}
 )
 
@@ -37,6 +45,26 @@ var (
 	ErrEmptyBearerToken = errors.New("Bearer token could not be empty")
 )
 
+// AttributeReplaces is an attribute of the access box put instead of another
+// one, its value is the ID of the replaced box object. The access key ID of the
+// replaced box is resolved to the replacement when the gate can't decrypt the
+// replaced box.
+const AttributeReplaces = "S3-Access-Box-Replaces"
+
+const (
+	// maxReplacements limits the chain of replacements followed to resolve a box.
+	maxReplacements = 8
+
+	// replacementsCacheSize limits the number of boxes the resolved replacements
+	// are kept for.
+	replacementsCacheSize = 1024
+	// replacementLifetime is the lifetime of the resolved replacement.
+	replacementLifetime = 10 * time.Minute
+	// noReplacementLifetime is the lifetime of the result of the search which
+	// found no replacement, a box re-encrypted later is found after it.
+	noReplacementLifetime = time.Minute
+)
+
 var bufferPool = sync.Pool{
 	New: func() interface{} {
 		return new(bytes.Buffer)
@@ -45,9 +73,15 @@ var bufferPool = sync.Pool{
 
 var _ = New
 
-// New creates new Credentials instance using given cli and key.
-func New(conns pool.Pool, key *keys.PrivateKey) Credentials {
-	return &cred{pool: conns, key: key}
+// New creates new Credentials instance using given cli and key. Retired keys
+// are used to decrypt boxes which have no gate for the key.
+func New(conns pool.Pool, key *keys.PrivateKey, retired ...*keys.PrivateKey) Credentials {
+	return &cred{
+		pool:         conns,
+		key:          key,
+		retired:      retired,
+		replacements: gcache.New(replacementsCacheSize).LRU().Build(),
+	}
 }
 
 func (c *cred) acquireBuffer() *bytes.Buffer {
@@ -60,24 +94,112 @@ func (c *cred) releaseBuffer(buf *bytes.Buffer) {
 }
 
 func (c *cred) GetTokens(ctx context.Context, address *object.Address) (*accessbox.GateData, error) {
-	box, err := c.getAccessBox(ctx, address)
+	box, err := c.GetAccessBox(ctx, address)
 	if err != nil {
 		return nil, err
 	}
 
-	return box.GetTokens(c.key)
+	return box.GetTokens(c.key, c.retired...)
 }
 
 func (c *cred) GetBox(ctx context.Context, address *object.Address) (*accessbox.Box, error) {
-	box, err := c.getAccessBox(ctx, address)
+	if entry, err := c.replacements.Get(address.String()); err == nil {
+		replacement, _ := entry.(*object.Address)
+		if replacement == nil {
+			return nil, fmt.Errorf("%w: no replacement of %s", accessbox.ErrNoGateData, address)
+		}
+		return c.getReplacement(ctx, replacement)
+	}
+
+	box, obj, err := c.getAccessBox(ctx, address)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := box.GetBox(c.key, c.retired...)
+	if !errors.Is(err, accessbox.ErrNoGateData) {
+		return result, err
+	}
+
+	replacement, result, rerr := c.findReplacement(ctx, address, obj.OwnerID(), maxReplacements)
+	switch {
+	case rerr == nil:
+		_ = c.replacements.SetWithExpire(address.String(), replacement, replacementLifetime)
+		result.Replaced = true
+		return result, nil
+	case errors.Is(rerr, accessbox.ErrNoGateData):
+		_ = c.replacements.SetWithExpire(address.String(), (*object.Address)(nil), noReplacementLifetime)
+	}
+	return nil, err
+}
+
+// getReplacement returns the resolved replacement box.
+func (c *cred) getReplacement(ctx context.Context, address *object.Address) (*accessbox.Box, error) {
+	box, err := c.GetAccessBox(ctx, address)
 	if err != nil {
 		return nil, err
 	}
 
-	return box.GetBox(c.key)
+	result, err := box.GetBox(c.key, c.retired...)
+	if err != nil {
+		return nil, err
+	}
+	result.Replaced = true
+	return result, nil
 }
 
-func (c *cred) getAccessBox(ctx context.Context, address *object.Address) (*accessbox.AccessBox, error) {
+// findReplacement returns the box which replaces the box stored by the
+// address and is encrypted for the gate keys with its address. Only
+// replacements put by the owner of the replaced box are taken into account.
+// accessbox.ErrNoGateData is returned if there is no such replacement.
+func (c *cred) findReplacement(ctx context.Context, address *object.Address, issuer *owner.ID, depth int) (*object.Address, *accessbox.Box, error) {
+	var filters object.SearchFilters
+	filters.AddRootFilter()
+	filters.AddObjectOwnerIDFilter(object.MatchStringEqual, issuer)
+	filters.AddFilter(AttributeReplaces, address.ObjectID().String(), object.MatchStringEqual)
+
+	ids, err := c.pool.SearchObject(ctx, new(client.SearchObjectParams).
+		WithContainerID(address.ContainerID()).
+		WithSearchFilters(filters))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	addresses := make([]*object.Address, 0, len(ids))
+	for _, id := range ids {
+		replacement := object.NewAddress()
+		replacement.SetContainerID(address.ContainerID())
+		replacement.SetObjectID(id)
+
+		box, err := c.GetAccessBox(ctx, replacement)
+		if err != nil {
+			return nil, nil, err
+		}
+		if result, err := box.GetBox(c.key, c.retired...); err == nil {
+			return replacement, result, nil
+		}
+		addresses = append(addresses, replacement)
+	}
+
+	if depth > 1 {
+		for _, replacement := range addresses {
+			resolved, result, err := c.findReplacement(ctx, replacement, issuer, depth-1)
+			if !errors.Is(err, accessbox.ErrNoGateData) {
+				return resolved, result, err
+			}
+		}
+	}
+
+	return nil, nil, fmt.Errorf("%w: no replacement of %s", accessbox.ErrNoGateData, address)
+}
+
+// GetAccessBox returns encrypted AccessBox stored by the address.
+func (c *cred) GetAccessBox(ctx context.Context, address *object.Address) (*accessbox.AccessBox, error) {
+	box, _, err := c.getAccessBox(ctx, address)
+	return box, err
+}
+
+func (c *cred) getAccessBox(ctx context.Context, address *object.Address) (*accessbox.AccessBox, *object.Object, error) {
 	var (
 		box accessbox.AccessBox
 		buf = c.acquireBuffer()
@@ -86,21 +208,35 @@ func (c *cred) getAccessBox(ctx context.Context, address *object.Address) (*acce
 
 	ops := new(client.GetObjectParams).WithAddress(address).WithPayloadWriter(buf)
 
-	_, err := c.pool.GetObject(
+	obj, err := c.pool.GetObject(
 		ctx,
 		ops,
 	)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	if err = box.Unmarshal(buf.Bytes()); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	return &box, nil
+	return &box, obj, nil
 }
 
 func (c *cred) Put(ctx context.Context, cid *cid.ID, issuer *owner.ID, box *accessbox.AccessBox, keys ...*keys.PublicKey) (*object.Address, error) {
+	return c.put(ctx, cid, issuer, box, nil, keys...)
+}
+
+// PutReplacement puts the box next to the replaced one and marks it with
+// AttributeReplaces, so the access key ID of the replaced box keeps working.
+func (c *cred) PutReplacement(ctx context.Context, replaced *object.Address, issuer *owner.ID, box *accessbox.AccessBox, keys ...*keys.PublicKey) (*object.Address, error) {
+	replaces := object.NewAttribute()
+	replaces.SetKey(AttributeReplaces)
+	replaces.SetValue(replaced.ObjectID().String())
+
+	return c.put(ctx, replaced.ContainerID(), issuer, box, []*object.Attribute{replaces}, keys...)
+}
+
+func (c *cred) put(ctx context.Context, cid *cid.ID, issuer *owner.ID, box *accessbox.AccessBox, attrs []*object.Attribute, keys ...*keys.PublicKey) (*object.Address, error) {
 	var (
 		err     error
 		created = strconv.FormatInt(time.Now().Unix(), 10)
@@ -127,7 +263,7 @@ func (c *cred) Put(ctx context.Context, cid *cid.ID, issuer *owner.ID, box *acce
 	raw := object.NewRaw()
 	raw.SetContainerID(cid)
 	raw.SetOwnerID(issuer)
-	raw.SetAttributes(filename, timestamp)
+	raw.SetAttributes(append([]*object.Attribute{filename, timestamp}, attrs...)...)
 
 	ops := new(client.PutObjectParams).WithObject(raw.Object()).WithPayloadReader(bytes.NewBuffer(data))
 	oid, err := c.pool.PutObject(
@@ -0,0 +1,140 @@
+package tokens
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-api-go/pkg/acl/eacl"
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
+	"github.com/nspcc-dev/neofs-api-go/pkg/container"
+	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"github.com/nspcc-dev/neofs-api-go/pkg/owner"
+	"github.com/nspcc-dev/neofs-api-go/pkg/token"
+	"github.com/nspcc-dev/neofs-s3-gw/creds/accessbox"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/memory"
+	"github.com/stretchr/testify/require"
+)
+
+func newOwnerID(t *testing.T) *owner.ID {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	wallet, err := owner.NEO3WalletFromPublicKey((*ecdsa.PublicKey)(key.PublicKey()))
+	require.NoError(t, err)
+	return owner.NewIDFromNeo3Wallet(wallet)
+}
+
+func packBox(t *testing.T, gateKey *keys.PublicKey, accessKey string) (*accessbox.AccessBox, *accessbox.Secrets) {
+	tkn := token.NewBearerToken()
+	tkn.SetEACLTable(eacl.NewTable())
+
+	gates := []*accessbox.GateData{accessbox.NewGateData(gateKey, tkn)}
+	if accessKey == "" {
+		box, secrets, err := accessbox.PackTokens(gates)
+		require.NoError(t, err)
+		return box, secrets
+	}
+	box, secrets, err := accessbox.PackTokensWithAccessKey(gates, accessKey)
+	require.NoError(t, err)
+	return box, secrets
+}
+
+func TestGetBoxReplacement(t *testing.T) {
+	ctx := context.Background()
+
+	poolKey, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	p, err := memory.NewPool(poolKey)
+	require.NoError(t, err)
+
+	var id *cid.ID
+	id, err = p.PutContainer(ctx, container.New())
+	require.NoError(t, err)
+
+	oldKey, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	newKey, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	issuer, stranger := newOwnerID(t), newOwnerID(t)
+
+	box, secrets := packBox(t, oldKey.PublicKey(), "")
+	address, err := New(p, oldKey).Put(ctx, id, issuer, box, oldKey.PublicKey())
+	require.NoError(t, err)
+
+	_, err = New(p, newKey).GetBox(ctx, address)
+	require.ErrorIs(t, err, accessbox.ErrNoGateData)
+
+	t.Run("replacement of another owner is ignored", func(t *testing.T) {
+		foreign, _ := packBox(t, newKey.PublicKey(), "")
+		_, err = New(p, newKey).PutReplacement(ctx, address, stranger, foreign, newKey.PublicKey())
+		require.NoError(t, err)
+
+		_, err = New(p, newKey).GetBox(ctx, address)
+		require.ErrorIs(t, err, accessbox.ErrNoGateData)
+	})
+
+	// the gate which failed to find the replacement before it's put
+	cached := &searchCounter{Pool: p}
+	gate := New(cached, newKey)
+	_, err = gate.GetBox(ctx, address)
+	require.ErrorIs(t, err, accessbox.ErrNoGateData)
+
+	t.Run("old access key ID is resolved", func(t *testing.T) {
+		// the first replacement is encrypted for a key unknown to the gate, it's
+		// replaced again
+		otherKey, err := keys.NewPrivateKey()
+		require.NoError(t, err)
+		intermediate, _ := packBox(t, otherKey.PublicKey(), secrets.AccessKey)
+		intermediateAddress, err := gate.PutReplacement(ctx, address, issuer, intermediate, otherKey.PublicKey())
+		require.NoError(t, err)
+
+		replacement, _ := packBox(t, newKey.PublicKey(), secrets.AccessKey)
+		_, err = gate.PutReplacement(ctx, intermediateAddress, issuer, replacement, newKey.PublicKey())
+		require.NoError(t, err)
+
+		result, err := New(p, newKey).GetBox(ctx, address)
+		require.NoError(t, err)
+		require.Equal(t, secrets.AccessKey, result.Gate.AccessKey)
+		require.True(t, result.Replaced)
+
+		// the replaced box is used while the gate can decrypt it
+		result, err = New(p, oldKey).GetBox(ctx, address)
+		require.NoError(t, err)
+		require.Equal(t, secrets.AccessKey, result.Gate.AccessKey)
+		require.False(t, result.Replaced)
+	})
+
+	t.Run("results are cached", func(t *testing.T) {
+		searches := cached.searches
+		for i := 0; i < 3; i++ {
+			_, err = gate.GetBox(ctx, address)
+			require.ErrorIs(t, err, accessbox.ErrNoGateData)
+		}
+		require.Equal(t, searches, cached.searches)
+
+		cached.searches = 0
+		gate = New(cached, newKey)
+		for i := 0; i < 3; i++ {
+			result, err := gate.GetBox(ctx, address)
+			require.NoError(t, err)
+			require.Equal(t, secrets.AccessKey, result.Gate.AccessKey)
+			require.True(t, result.Replaced)
+		}
+		// the replacement of the replacement is searched for too
+		require.Equal(t, 2, cached.searches)
+	})
+}
+
+// searchCounter counts searches of objects.
+type searchCounter struct {
+	*memory.Pool
+	searches int
+}
+
+func (s *searchCounter) SearchObject(ctx context.Context, params *client.SearchObjectParams, opts ...client.CallOption) ([]*object.ID, error) {
+	s.searches++
+	return s.Pool.SearchObject(ctx, params, opts...)
+}
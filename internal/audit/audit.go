@@ -0,0 +1,351 @@
+package audit
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neo-go/pkg/util"
+	"go.uber.org/zap"
+)
+
+type (
+	// Event describes a mutation of the storage made by the gateway.
+	Event struct {
+		// Action is a mutation of the layer, one of Action* constants.
+		Action string `json:"action"`
+		// Operation is a name of S3 API call caused the mutation.
+		Operation   string `json:"operation,omitempty"`
+		RequestID   string `json:"request_id,omitempty"`
+		AccessKeyID string `json:"access_key_id,omitempty"`
+		Owner       string `json:"owner,omitempty"`
+		Bucket      string `json:"bucket,omitempty"`
+		Container   string `json:"container,omitempty"`
+		Object      string `json:"object,omitempty"`
+		ObjectID    string `json:"object_id,omitempty"`
+	}
+
+	// Record is an event in the hash chain. Hash covers all other fields
+	// including the hash of the previous record and is signed by the gateway.
+	Record struct {
+		// Instance identifies the chain among the chains of the gateway key.
+		Instance string    `json:"instance,omitempty"`
+		Seq      uint64    `json:"seq"`
+		Time     time.Time `json:"time"`
+		Event
+		PrevHash  string `json:"prev_hash"`
+		Hash      string `json:"hash"`
+		Signature string `json:"signature"`
+	}
+
+	// Sealer stores the records durably.
+	Sealer interface {
+		// Seal stores the records following the ones sealed before.
+		Seal(ctx context.Context, records []*Record) error
+		// Last returns sequence number and hash of the last sealed record,
+		// zero and empty hash are returned if there are no records.
+		Last(ctx context.Context) (uint64, string, error)
+	}
+
+	// Journal keeps the records which aren't sealed yet, so they aren't lost
+	// if the gateway stops before sealing them.
+	Journal interface {
+		// Records returns the kept records.
+		Records() ([]*Record, error)
+		// Append adds the records to the kept ones.
+		Append(records ...*Record) error
+		// Reset replaces the kept records.
+		Reset(records []*Record) error
+		// Close releases the resources of the journal.
+		Close() error
+	}
+
+	// Trail signs events, chains them and seals them periodically.
+	Trail struct {
+		key      *keys.PrivateKey
+		instance string
+		sealer   Sealer
+		journal  Journal
+		log      *zap.Logger
+
+		// sealMu serializes sealing, mu guards the chain state.
+		sealMu   sync.Mutex
+		mu       sync.Mutex
+		seq      uint64
+		lastHash string
+		pending  []*Record
+		// unjournaled are the records to be appended to the journal.
+		unjournaled []*Record
+
+		// journalMu serializes journal writes, journaled is the sequence
+		// number of the last record written to the journal.
+		journalMu sync.Mutex
+		journaled uint64
+	}
+)
+
+// Actions of the layer.
+const (
+	ActionObjectPut       = "object.put"
+	ActionObjectDelete    = "object.delete"
+	ActionSystemPut       = "system.put"
+	ActionSystemDelete    = "system.delete"
+	ActionContainerPut    = "container.put"
+	ActionContainerDelete = "container.delete"
+	ActionContainerEACL   = "container.seteacl"
+)
+
+// NewTrail creates Trail continuing the chain of the instance sealed before.
+// Records left in the journal after the last sealed one become pending, they
+// must continue the sealed chain.
+func NewTrail(ctx context.Context, key *keys.PrivateKey, instance string, sealer Sealer, journal Journal, log *zap.Logger) (*Trail, error) {
+	seq, hash, err := sealer.Last(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't get the last audit record: %w", err)
+	}
+
+	kept, err := journal.Records()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read audit journal: %w", err)
+	}
+
+	var pending []*Record
+	for _, rec := range kept {
+		if rec.Seq > seq {
+			pending = append(pending, rec)
+		}
+	}
+
+	if len(pending) != 0 {
+		if first := pending[0]; first.Seq != seq+1 || first.PrevHash != hash {
+			return nil, fmt.Errorf("audit journal record %d doesn't continue sealed record %d", first.Seq, seq)
+		}
+		if first := pending[0]; first.Instance != instance {
+			return nil, fmt.Errorf("audit journal belongs to instance %q, not %q", first.Instance, instance)
+		}
+		if err = Verify(pending, key.PublicKey()); err != nil {
+			return nil, fmt.Errorf("invalid audit journal: %w", err)
+		}
+		last := pending[len(pending)-1]
+		seq, hash = last.Seq, last.Hash
+		log.Info("unsealed audit records restored from journal", zap.Int("records", len(pending)))
+	}
+
+	if len(pending) != len(kept) {
+		if err = journal.Reset(pending); err != nil {
+			return nil, fmt.Errorf("couldn't reset audit journal: %w", err)
+		}
+	}
+
+	return &Trail{
+		key:       key,
+		instance:  instance,
+		sealer:    sealer,
+		journal:   journal,
+		log:       log,
+		seq:       seq,
+		lastHash:  hash,
+		pending:   pending,
+		journaled: seq,
+	}, nil
+}
+
+// Record adds the event to the chain and the journal, it's stored on the
+// next sealing. The record is numbered and signed under the lock, while the
+// journal is written outside of it: concurrent records are appended with
+// a single write in the order of their numbers.
+func (t *Trail) Record(ev Event) {
+	t.mu.Lock()
+	t.seq++
+	rec := &Record{
+		Instance: t.instance,
+		Seq:      t.seq,
+		Time:     time.Now().UTC(),
+		Event:    ev,
+		PrevHash: t.lastHash,
+	}
+
+	digest := rec.digest()
+	rec.Hash = hex.EncodeToString(digest[:])
+	rec.Signature = hex.EncodeToString(t.key.SignHash(digest))
+
+	t.lastHash = rec.Hash
+	t.pending = append(t.pending, rec)
+	t.unjournaled = append(t.unjournaled, rec)
+	t.mu.Unlock()
+
+	t.writeJournal(rec.Seq)
+}
+
+// writeJournal returns when the record with the sequence number is in the
+// journal. The caller writes all the records waiting for it, so the callers
+// which come while the journal is being written are served by the next one.
+func (t *Trail) writeJournal(seq uint64) {
+	t.journalMu.Lock()
+	defer t.journalMu.Unlock()
+
+	if t.journaled >= seq {
+		return
+	}
+
+	t.mu.Lock()
+	batch := t.unjournaled
+	t.unjournaled = nil
+	t.mu.Unlock()
+
+	if len(batch) == 0 {
+		return
+	}
+	t.journaled = batch[len(batch)-1].Seq
+
+	if err := t.journal.Append(batch...); err != nil {
+		t.log.Error("couldn't write audit records to journal",
+			zap.Uint64("first_seq", batch[0].Seq),
+			zap.Uint64("last_seq", t.journaled),
+			zap.Error(err))
+	}
+}
+
+// Seal stores the pending records. They're kept until the next sealing
+// if it fails.
+func (t *Trail) Seal(ctx context.Context) error {
+	t.sealMu.Lock()
+	defer t.sealMu.Unlock()
+
+	t.mu.Lock()
+	records := t.pending
+	t.pending = nil
+	t.mu.Unlock()
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	if err := t.sealer.Seal(ctx, records); err != nil {
+		t.mu.Lock()
+		t.pending = append(records, t.pending...)
+		t.mu.Unlock()
+		return err
+	}
+
+	// records added while sealing are kept in the journal, the ones added
+	// after the reset are appended to it
+	t.journalMu.Lock()
+	defer t.journalMu.Unlock()
+
+	t.mu.Lock()
+	kept := t.pending
+	t.unjournaled = nil
+	t.journaled = t.seq
+	t.mu.Unlock()
+
+	if err := t.journal.Reset(kept); err != nil {
+		t.log.Error("couldn't reset audit journal", zap.Error(err))
+	}
+
+	return nil
+}
+
+// Close releases the journal, the records must be sealed before.
+func (t *Trail) Close() error {
+	return t.journal.Close()
+}
+
+// Run seals the records every interval until the context is done. The rest
+// of the records must be sealed with Seal after the last mutation.
+func (t *Trail) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.Seal(ctx); err != nil {
+				t.log.Error("couldn't seal audit records", zap.Error(err))
+			}
+		}
+	}
+}
+
+// digest returns hash of all the fields of the record except the hash and
+// the signature.
+func (r *Record) digest() util.Uint256 {
+	unsigned := *r
+	unsigned.Hash, unsigned.Signature = "", ""
+	data, _ := json.Marshal(unsigned)
+	return sha256.Sum256(data)
+}
+
+// Verify checks that the records are a continuous part of the chain signed
+// by the key. The records must be ordered by sequence number and belong to
+// one instance. The first record of the chain must have no previous hash.
+func Verify(records []*Record, key *keys.PublicKey) error {
+	for i, rec := range records {
+		if i > 0 {
+			prev := records[i-1]
+			if rec.Instance != prev.Instance {
+				return fmt.Errorf("record %d belongs to instance %q, not %q", rec.Seq, rec.Instance, prev.Instance)
+			}
+			if rec.Seq != prev.Seq+1 {
+				return fmt.Errorf("record %d follows record %d, records are missing", rec.Seq, prev.Seq)
+			}
+			if rec.PrevHash != prev.Hash {
+				return fmt.Errorf("record %d: previous hash mismatch", rec.Seq)
+			}
+		} else if rec.Seq == 1 && rec.PrevHash != "" {
+			return fmt.Errorf("record 1: the first record has previous hash")
+		}
+
+		digest := rec.digest()
+		if rec.Hash != hex.EncodeToString(digest[:]) {
+			return fmt.Errorf("record %d: hash mismatch", rec.Seq)
+		}
+
+		sig, err := hex.DecodeString(rec.Signature)
+		if err != nil || !key.Verify(sig, digest[:]) {
+			return fmt.Errorf("record %d: invalid signature", rec.Seq)
+		}
+	}
+
+	return nil
+}
+
+// WriteRecords writes the records as JSON lines.
+func WriteRecords(w io.Writer, records []*Record) error {
+	enc := json.NewEncoder(w)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ReadRecords reads the records written by WriteRecords.
+func ReadRecords(r io.Reader) ([]*Record, error) {
+	var records []*Record
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		rec := new(Record)
+		if err := json.Unmarshal(scanner.Bytes(), rec); err != nil {
+			return nil, fmt.Errorf("couldn't parse record %d: %w", len(records)+1, err)
+		}
+		records = append(records, rec)
+	}
+
+	return records, scanner.Err()
+}
@@ -0,0 +1,254 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type sealerMock struct {
+	records []*Record
+	err     error
+}
+
+func (s *sealerMock) Seal(_ context.Context, records []*Record) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.records = append(s.records, records...)
+	return nil
+}
+
+func (s *sealerMock) Last(context.Context) (uint64, string, error) {
+	if len(s.records) == 0 {
+		return 0, "", nil
+	}
+	last := s.records[len(s.records)-1]
+	return last.Seq, last.Hash, nil
+}
+
+func newJournal(t *testing.T) Journal {
+	journal, err := NewFileJournal(filepath.Join(t.TempDir(), "audit.journal"))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, journal.Close()) })
+	return journal
+}
+
+func TestTrail(t *testing.T) {
+	ctx := context.Background()
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	sealer := &sealerMock{}
+	journal := newJournal(t)
+	trail, err := NewTrail(ctx, key, "gw1", sealer, journal, zap.NewNop())
+	require.NoError(t, err)
+
+	trail.Record(Event{Action: ActionContainerPut, Bucket: "bucket"})
+	trail.Record(Event{Action: ActionObjectPut, Bucket: "bucket", Object: "obj1"})
+
+	sealer.err = fmt.Errorf("network is down")
+	require.Error(t, trail.Seal(ctx))
+	require.Empty(t, sealer.records)
+
+	sealer.err = nil
+	trail.Record(Event{Action: ActionObjectDelete, Bucket: "bucket", Object: "obj1"})
+	require.NoError(t, trail.Seal(ctx))
+	require.Len(t, sealer.records, 3)
+
+	// restarted gateway continues the chain
+	trail, err = NewTrail(ctx, key, "gw1", sealer, journal, zap.NewNop())
+	require.NoError(t, err)
+	trail.Record(Event{Action: ActionContainerDelete, Bucket: "bucket"})
+	require.NoError(t, trail.Seal(ctx))
+	require.Len(t, sealer.records, 4)
+
+	require.NoError(t, Verify(sealer.records, key.PublicKey()))
+	require.NoError(t, Verify(sealer.records[2:], key.PublicKey()))
+
+	buf := new(bytes.Buffer)
+	require.NoError(t, WriteRecords(buf, sealer.records))
+	records, err := ReadRecords(buf)
+	require.NoError(t, err)
+	require.NoError(t, Verify(records, key.PublicKey()))
+}
+
+func TestTrailJournal(t *testing.T) {
+	ctx := context.Background()
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "audit.journal")
+	sealer := &sealerMock{}
+
+	journal, err := NewFileJournal(path)
+	require.NoError(t, err)
+	trail, err := NewTrail(ctx, key, "gw1", sealer, journal, zap.NewNop())
+	require.NoError(t, err)
+	trail.Record(Event{Action: ActionObjectPut, Object: "obj1"})
+	require.NoError(t, trail.Seal(ctx))
+	trail.Record(Event{Action: ActionObjectPut, Object: "obj2"})
+	trail.Record(Event{Action: ActionObjectDelete, Object: "obj1"})
+	// crash before sealing
+	require.NoError(t, journal.Close())
+
+	journal, err = NewFileJournal(path)
+	require.NoError(t, err)
+	defer func() { require.NoError(t, journal.Close()) }()
+	trail, err = NewTrail(ctx, key, "gw1", sealer, journal, zap.NewNop())
+	require.NoError(t, err)
+	trail.Record(Event{Action: ActionObjectDelete, Object: "obj2"})
+	require.NoError(t, trail.Seal(ctx))
+
+	require.Len(t, sealer.records, 4)
+	require.Equal(t, "obj2", sealer.records[1].Object)
+	require.NoError(t, Verify(sealer.records, key.PublicKey()))
+
+	kept, err := journal.Records()
+	require.NoError(t, err)
+	require.Empty(t, kept)
+
+	t.Run("doesn't continue sealed chain", func(t *testing.T) {
+		other, err := NewFileJournal(filepath.Join(t.TempDir(), "audit.journal"))
+		require.NoError(t, err)
+		defer func() { require.NoError(t, other.Close()) }()
+		trail, err := NewTrail(ctx, key, "gw1", &sealerMock{records: sealer.records}, other, zap.NewNop())
+		require.NoError(t, err)
+		trail.Record(Event{Action: ActionObjectPut, Object: "obj3"})
+
+		// the sealed records preceding the journal are missing
+		_, err = NewTrail(ctx, key, "gw1", &sealerMock{records: sealer.records[:2]}, other, zap.NewNop())
+		require.Error(t, err)
+	})
+}
+
+// slowJournal takes time to write like a disk sync.
+type slowJournal struct {
+	Journal
+	delay  time.Duration
+	writes int32
+}
+
+func (j *slowJournal) Append(records ...*Record) error {
+	atomic.AddInt32(&j.writes, 1)
+	time.Sleep(j.delay)
+	return j.Journal.Append(records...)
+}
+
+func TestTrailConcurrentRecords(t *testing.T) {
+	const workers, perWorker = 16, 20
+
+	ctx := context.Background()
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	sealer := &sealerMock{}
+	journal := &slowJournal{Journal: newJournal(t), delay: 5 * time.Millisecond}
+	trail, err := NewTrail(ctx, key, "gw1", sealer, journal, zap.NewNop())
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < perWorker; j++ {
+				trail.Record(Event{Action: ActionObjectPut, Object: fmt.Sprintf("obj%d-%d", i, j)})
+				if j == perWorker/2 && i%4 == 0 {
+					require.NoError(t, trail.Seal(ctx))
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// records waiting for the journal are written together
+	require.Less(t, int(atomic.LoadInt32(&journal.writes)), workers*perWorker)
+
+	// the journal keeps the unsealed records in order
+	kept, err := journal.Records()
+	require.NoError(t, err)
+	if len(sealer.records) != 0 && len(kept) != 0 {
+		require.Equal(t, sealer.records[len(sealer.records)-1].Seq+1, kept[0].Seq)
+	}
+	require.NoError(t, Verify(kept, key.PublicKey()))
+
+	require.NoError(t, trail.Seal(ctx))
+	require.Len(t, sealer.records, workers*perWorker)
+	require.NoError(t, Verify(sealer.records, key.PublicKey()))
+}
+
+func BenchmarkTrailRecord(b *testing.B) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(b, err)
+
+	journal, err := NewFileJournal(filepath.Join(b.TempDir(), "audit.journal"))
+	require.NoError(b, err)
+	defer journal.Close()
+
+	trail, err := NewTrail(context.Background(), key, "gw1", &sealerMock{}, journal, zap.NewNop())
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			trail.Record(Event{Action: ActionObjectPut, Bucket: "bucket", Object: "obj"})
+		}
+	})
+}
+
+func TestVerify(t *testing.T) {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+
+	generate := func() []*Record {
+		sealer := &sealerMock{}
+		trail, err := NewTrail(context.Background(), key, "gw1", sealer, newJournal(t), zap.NewNop())
+		require.NoError(t, err)
+		for i := 0; i < 3; i++ {
+			trail.Record(Event{Action: ActionObjectPut, Object: fmt.Sprintf("obj%d", i)})
+		}
+		require.NoError(t, trail.Seal(context.Background()))
+		return sealer.records
+	}
+
+	t.Run("tampered", func(t *testing.T) {
+		records := generate()
+		records[1].Object = "other"
+		require.Error(t, Verify(records, key.PublicKey()))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		records := generate()
+		require.Error(t, Verify([]*Record{records[0], records[2]}, key.PublicKey()))
+	})
+
+	t.Run("rehashed", func(t *testing.T) {
+		records := generate()
+		records[2].Object = "other"
+		digest := records[2].digest()
+		records[2].Hash = fmt.Sprintf("%x", digest[:])
+		require.Error(t, Verify(records, key.PublicKey()))
+	})
+
+	t.Run("other instance", func(t *testing.T) {
+		records := generate()
+		records[2].Instance = "gw2"
+		require.Error(t, Verify(records, key.PublicKey()))
+	})
+
+	t.Run("other key", func(t *testing.T) {
+		other, err := keys.NewPrivateKey()
+		require.NoError(t, err)
+		require.Error(t, Verify(generate(), other.PublicKey()))
+	})
+}
@@ -0,0 +1,75 @@
+package audit
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+type fileJournal struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+// NewFileJournal returns Journal keeping the records as JSON lines in the file.
+// The file is synced after every change, so the records survive a crash.
+func NewFileJournal(path string) (Journal, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't open audit journal: %w", err)
+	}
+	return &fileJournal{file: f}, nil
+}
+
+// Records implements Journal interface.
+func (j *fileJournal) Records() ([]*Record, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return ReadRecords(j.file)
+}
+
+// Append implements Journal interface.
+func (j *fileJournal) Append(records ...*Record) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if _, err := j.file.Seek(0, io.SeekEnd); err != nil {
+		return err
+	}
+	buf := new(bytes.Buffer)
+	if err := WriteRecords(buf, records); err != nil {
+		return err
+	}
+	if _, err := j.file.Write(buf.Bytes()); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// Reset implements Journal interface.
+func (j *fileJournal) Reset(records []*Record) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := j.file.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	if err := WriteRecords(j.file, records); err != nil {
+		return err
+	}
+	return j.file.Sync()
+}
+
+// Close implements Journal interface.
+func (j *fileJournal) Close() error {
+	return j.file.Close()
+}
@@ -0,0 +1,180 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
+	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+)
+
+// Attributes of the sealed objects.
+const (
+	AttributeGateway  = "AuditGateway"
+	AttributeInstance = "AuditInstance"
+	AttributeIndex    = "AuditIndex"
+	AttributeFirstSeq = "AuditFirstSeq"
+	AttributeLastSeq  = "AuditLastSeq"
+	AttributeLastHash = "AuditLastHash"
+)
+
+type neofsSealer struct {
+	pool     pool.Pool
+	cid      *cid.ID
+	gateway  string
+	instance string
+
+	// index is the number of the last sealed object of the chain, it's
+	// known after Last.
+	index      uint64
+	indexKnown bool
+}
+
+// NewNeoFSSealer returns Sealer putting the records as JSON lines into objects
+// of the container. Objects are marked with the gateway key and the instance,
+// so every chain is continued separately. Objects of the chain are numbered
+// with AttributeIndex, so the last one is found without reading all of them.
+func NewNeoFSSealer(conns pool.Pool, containerID *cid.ID, key *keys.PublicKey, instance string) Sealer {
+	return &neofsSealer{
+		pool:     conns,
+		cid:      containerID,
+		gateway:  hex.EncodeToString(key.Bytes()),
+		instance: instance,
+	}
+}
+
+// Seal implements Sealer interface.
+func (s *neofsSealer) Seal(ctx context.Context, records []*Record) error {
+	if !s.indexKnown {
+		if _, _, err := s.Last(ctx); err != nil {
+			return err
+		}
+	}
+
+	buf := new(bytes.Buffer)
+	if err := WriteRecords(buf, records); err != nil {
+		return err
+	}
+
+	first, last := records[0], records[len(records)-1]
+	attributes := map[string]string{
+		object.AttributeFileName:  fmt.Sprintf("audit-%s-%s-%d-%d.jsonl", s.gateway[:8], s.instance, first.Seq, last.Seq),
+		object.AttributeTimestamp: strconv.FormatInt(time.Now().UTC().Unix(), 10),
+		AttributeGateway:          s.gateway,
+		AttributeInstance:         s.instance,
+		AttributeIndex:            strconv.FormatUint(s.index+1, 10),
+		AttributeFirstSeq:         strconv.FormatUint(first.Seq, 10),
+		AttributeLastSeq:          strconv.FormatUint(last.Seq, 10),
+		AttributeLastHash:         last.Hash,
+	}
+
+	attrs := make([]*object.Attribute, 0, len(attributes))
+	for k, v := range attributes {
+		attr := object.NewAttribute()
+		attr.SetKey(k)
+		attr.SetValue(v)
+		attrs = append(attrs, attr)
+	}
+
+	raw := object.NewRaw()
+	raw.SetOwnerID(s.pool.OwnerID())
+	raw.SetContainerID(s.cid)
+	raw.SetAttributes(attrs...)
+
+	ops := new(client.PutObjectParams).WithObject(raw.Object()).WithPayloadReader(buf)
+	if _, err := s.pool.PutObject(ctx, ops); err != nil {
+		return fmt.Errorf("couldn't put audit object: %w", err)
+	}
+	s.index++
+
+	return nil
+}
+
+// Last implements Sealer interface. The objects are numbered without gaps, so
+// the last one is found with exponential and binary search of the index and
+// only the objects with the last index are read.
+func (s *neofsSealer) Last(ctx context.Context) (uint64, string, error) {
+	ids, err := s.search(ctx, 1)
+	if err != nil || len(ids) == 0 {
+		s.index, s.indexKnown = 0, err == nil
+		return 0, "", err
+	}
+
+	// objects with lo index exist, with hi index don't
+	lo, hi, lastIDs := uint64(1), uint64(2), ids
+	for {
+		if ids, err = s.search(ctx, hi); err != nil {
+			return 0, "", err
+		} else if len(ids) == 0 {
+			break
+		}
+		lo, hi, lastIDs = hi, hi*2, ids
+	}
+	for hi-lo > 1 {
+		mid := lo + (hi-lo)/2
+		if ids, err = s.search(ctx, mid); err != nil {
+			return 0, "", err
+		} else if len(ids) == 0 {
+			hi = mid
+		} else {
+			lo, lastIDs = mid, ids
+		}
+	}
+
+	// the same records may be sealed twice if the put is retried
+	var (
+		lastSeq  uint64
+		lastHash string
+	)
+	for _, id := range lastIDs {
+		address := object.NewAddress()
+		address.SetContainerID(s.cid)
+		address.SetObjectID(id)
+
+		obj, err := s.pool.GetObjectHeader(ctx, new(client.ObjectHeaderParams).WithAddress(address))
+		if err != nil {
+			return 0, "", fmt.Errorf("couldn't head audit object %s: %w", id, err)
+		}
+
+		var (
+			seq  uint64
+			hash string
+		)
+		for _, attr := range obj.Attributes() {
+			switch attr.Key() {
+			case AttributeLastSeq:
+				seq, _ = strconv.ParseUint(attr.Value(), 10, 64)
+			case AttributeLastHash:
+				hash = attr.Value()
+			}
+		}
+		if seq > lastSeq {
+			lastSeq, lastHash = seq, hash
+		}
+	}
+
+	s.index, s.indexKnown = lo, true
+	return lastSeq, lastHash, nil
+}
+
+// search returns the objects of the chain with the index.
+func (s *neofsSealer) search(ctx context.Context, index uint64) ([]*object.ID, error) {
+	var filters object.SearchFilters
+	filters.AddRootFilter()
+	filters.AddFilter(AttributeGateway, s.gateway, object.MatchStringEqual)
+	filters.AddFilter(AttributeInstance, s.instance, object.MatchStringEqual)
+	filters.AddFilter(AttributeIndex, strconv.FormatUint(index, 10), object.MatchStringEqual)
+
+	ids, err := s.pool.SearchObject(ctx, new(client.SearchObjectParams).WithContainerID(s.cid).WithSearchFilters(filters))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't search audit objects: %w", err)
+	}
+	return ids, nil
+}
@@ -0,0 +1,82 @@
+package audit
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
+	"github.com/nspcc-dev/neofs-api-go/pkg/container"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/memory"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// countingPool counts heads of the objects.
+type countingPool struct {
+	*memory.Pool
+	heads int32
+}
+
+func (p *countingPool) GetObjectHeader(ctx context.Context, params *client.ObjectHeaderParams, opts ...client.CallOption) (*object.Object, error) {
+	atomic.AddInt32(&p.heads, 1)
+	return p.Pool.GetObjectHeader(ctx, params, opts...)
+}
+
+func TestNeoFSSealer(t *testing.T) {
+	ctx := context.Background()
+
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	mem, err := memory.NewPool(key)
+	require.NoError(t, err)
+	p := &countingPool{Pool: mem}
+
+	id, err := p.PutContainer(ctx, container.New())
+	require.NoError(t, err)
+
+	sealer := NewNeoFSSealer(p, id, key.PublicKey(), "gw1")
+	seq, hash, err := sealer.Last(ctx)
+	require.NoError(t, err)
+	require.Zero(t, seq)
+	require.Empty(t, hash)
+
+	trail, err := NewTrail(ctx, key, "gw1", sealer, newJournal(t), zap.NewNop())
+	require.NoError(t, err)
+	for i := 0; i < 37; i++ {
+		trail.Record(Event{Action: ActionObjectPut})
+		require.NoError(t, trail.Seal(ctx))
+	}
+
+	// another instance with the same key has its own chain
+	otherSealer := NewNeoFSSealer(p, id, key.PublicKey(), "gw2")
+	other, err := NewTrail(ctx, key, "gw2", otherSealer, newJournal(t), zap.NewNop())
+	require.NoError(t, err)
+	other.Record(Event{Action: ActionObjectPut})
+	require.NoError(t, other.Seal(ctx))
+
+	atomic.StoreInt32(&p.heads, 0)
+	restarted := NewNeoFSSealer(p, id, key.PublicKey(), "gw1")
+	seq, hash, err = restarted.Last(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 37, seq)
+	require.Equal(t, trail.lastHash, hash)
+	// only the last object is read
+	require.EqualValues(t, 1, atomic.LoadInt32(&p.heads))
+
+	// the restarted gateway continues numbering of the objects
+	trail, err = NewTrail(ctx, key, "gw1", restarted, newJournal(t), zap.NewNop())
+	require.NoError(t, err)
+	trail.Record(Event{Action: ActionObjectDelete})
+	require.NoError(t, trail.Seal(ctx))
+
+	seq, _, err = NewNeoFSSealer(p, id, key.PublicKey(), "gw1").Last(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 38, seq)
+
+	seq, _, err = NewNeoFSSealer(p, id, key.PublicKey(), "gw2").Last(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, seq)
+}
@@ -0,0 +1,567 @@
+package memory
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-api-go/pkg"
+	"github.com/nspcc-dev/neofs-api-go/pkg/acl/eacl"
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
+	"github.com/nspcc-dev/neofs-api-go/pkg/container"
+	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/nspcc-dev/neofs-api-go/pkg/netmap"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"github.com/nspcc-dev/neofs-api-go/pkg/owner"
+	"github.com/nspcc-dev/neofs-api-go/pkg/session"
+	rpcclient "github.com/nspcc-dev/neofs-api-go/rpc/client"
+	v2object "github.com/nspcc-dev/neofs-api-go/v2/object"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+)
+
+type (
+	// Pool is an in-process pool.Pool keeping containers and objects in memory.
+	// It doesn't check signatures, tokens and access rules, objects are never
+	// split and every stored object starts a new epoch, so versions of an
+	// object created one after another have different creation epochs.
+	Pool struct {
+		owner *owner.ID
+
+		mu         sync.RWMutex
+		epoch      uint64
+		containers map[string]*container.Container
+		eacls      map[string]*eacl.Table
+		objects    map[string]*object.Object
+	}
+
+	// connection is a client of the pool returned by Pool.Connection. It
+	// supports object and container operations and NetworkInfo, the other
+	// methods of client.Client are nil and panic.
+	connection struct {
+		client.Accounting
+		client.Netmap
+		client.Session
+		client.Reputation
+		*Pool
+	}
+)
+
+var _ pool.Pool = (*Pool)(nil)
+
+var errEACLNotSigned = errors.New("extended ACL tables aren't signed, use GetEACLTable")
+
+// NewPool creates an empty Pool owned by the key.
+func NewPool(key *keys.PrivateKey) (*Pool, error) {
+	wallet, err := owner.NEO3WalletFromPublicKey((*ecdsa.PublicKey)(key.PublicKey()))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Pool{
+		owner:      owner.NewIDFromNeo3Wallet(wallet),
+		epoch:      1,
+		containers: make(map[string]*container.Container),
+		eacls:      make(map[string]*eacl.Table),
+		objects:    make(map[string]*object.Object),
+	}, nil
+}
+
+// PutObject implements client.Object interface.
+func (p *Pool) PutObject(_ context.Context, params *client.PutObjectParams, _ ...client.CallOption) (*object.ID, error) {
+	raw, err := copyObject(params.Object())
+	if err != nil {
+		return nil, err
+	}
+
+	var payload []byte
+	if params.PayloadReader() != nil {
+		if payload, err = io.ReadAll(params.PayloadReader()); err != nil {
+			return nil, err
+		}
+	}
+
+	checksum, err := randomChecksum()
+	if err != nil {
+		return nil, err
+	}
+	id := object.NewID()
+	id.SetSHA256(checksum)
+
+	payloadChecksum := pkg.NewChecksum()
+	payloadChecksum.SetSHA256(sha256.Sum256(payload))
+
+	raw.SetID(id)
+	raw.SetPayload(payload)
+	raw.SetPayloadSize(uint64(len(payload)))
+	raw.SetPayloadChecksum(payloadChecksum)
+	if raw.OwnerID() == nil {
+		raw.SetOwnerID(p.owner)
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if raw.ContainerID() == nil || p.containers[raw.ContainerID().String()] == nil {
+		return nil, fmt.Errorf("container not found")
+	}
+
+	raw.SetCreationEpoch(p.epoch)
+	p.epoch++
+
+	p.objects[newAddress(raw.ContainerID(), id).String()] = raw.Object()
+	return id, nil
+}
+
+// DeleteObject implements client.Object interface.
+func (p *Pool) DeleteObject(_ context.Context, params *client.DeleteObjectParams, _ ...client.CallOption) error {
+	p.mu.Lock()
+	delete(p.objects, params.Address().String())
+	p.mu.Unlock()
+
+	return nil
+}
+
+// GetObject implements client.Object interface.
+func (p *Pool) GetObject(_ context.Context, params *client.GetObjectParams, _ ...client.CallOption) (*object.Object, error) {
+	obj, err := p.object(params.Address())
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := copyObject(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.PayloadWriter() != nil {
+		if _, err = params.PayloadWriter().Write(raw.Payload()); err != nil {
+			return nil, err
+		}
+	}
+
+	return raw.Object(), nil
+}
+
+// GetObjectHeader implements client.Object interface.
+func (p *Pool) GetObjectHeader(_ context.Context, params *client.ObjectHeaderParams, _ ...client.CallOption) (*object.Object, error) {
+	obj, err := p.object(params.Address())
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := copyObject(obj)
+	if err != nil {
+		return nil, err
+	}
+	return raw.CutPayload().Object(), nil
+}
+
+// ObjectPayloadRangeData implements client.Object interface.
+func (p *Pool) ObjectPayloadRangeData(_ context.Context, params *client.RangeDataParams, _ ...client.CallOption) ([]byte, error) {
+	obj, err := p.object(params.Address())
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := payloadRange(obj, params.Range())
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]byte, len(data))
+	copy(res, data)
+
+	if params.DataWriter() != nil {
+		if _, err = params.DataWriter().Write(res); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// ObjectPayloadRangeSHA256 implements client.Object interface.
+func (p *Pool) ObjectPayloadRangeSHA256(_ context.Context, params *client.RangeChecksumParams, _ ...client.CallOption) ([][sha256.Size]byte, error) {
+	obj, err := p.object(params.Address())
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([][sha256.Size]byte, 0, len(params.RangeList()))
+	for _, rng := range params.RangeList() {
+		data, err := payloadRange(obj, rng)
+		if err != nil {
+			return nil, err
+		}
+		if salt := params.Salt(); len(salt) > 0 {
+			data = saltPayload(data, salt)
+		}
+		res = append(res, sha256.Sum256(data))
+	}
+
+	return res, nil
+}
+
+// ObjectPayloadRangeTZ implements client.Object interface. Homomorphic hashes
+// aren't supported.
+func (p *Pool) ObjectPayloadRangeTZ(context.Context, *client.RangeChecksumParams, ...client.CallOption) ([][64]byte, error) {
+	return nil, fmt.Errorf("homomorphic hashes are not supported by memory backend")
+}
+
+// SearchObject implements client.Object interface.
+func (p *Pool) SearchObject(_ context.Context, params *client.SearchObjectParams, _ ...client.CallOption) ([]*object.ID, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if params.ContainerID() == nil || p.containers[params.ContainerID().String()] == nil {
+		return nil, fmt.Errorf("container not found")
+	}
+
+	var res []*object.ID
+	for _, obj := range p.objects {
+		if obj.ContainerID().Equal(params.ContainerID()) && matchFilters(obj, params.SearchFilters()) {
+			res = append(res, obj.ID())
+		}
+	}
+
+	return res, nil
+}
+
+// PutContainer implements client.Container interface.
+func (p *Pool) PutContainer(_ context.Context, cnr *container.Container, _ ...client.CallOption) (*cid.ID, error) {
+	data, err := cnr.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	stored := container.New()
+	if err = stored.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	if stored.OwnerID() == nil {
+		stored.SetOwnerID(p.owner)
+	}
+
+	checksum, err := randomChecksum()
+	if err != nil {
+		return nil, err
+	}
+	id := cid.New()
+	id.SetSHA256(checksum)
+
+	p.mu.Lock()
+	p.containers[id.String()] = stored
+	p.mu.Unlock()
+
+	return id, nil
+}
+
+// GetContainer implements client.Container interface.
+func (p *Pool) GetContainer(_ context.Context, id *cid.ID, _ ...client.CallOption) (*container.Container, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	cnr, ok := p.containers[id.String()]
+	if !ok {
+		return nil, fmt.Errorf("container not found")
+	}
+
+	return copyContainer(cnr)
+}
+
+// ListContainers implements client.Container interface.
+func (p *Pool) ListContainers(_ context.Context, id *owner.ID, _ ...client.CallOption) ([]*cid.ID, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	var res []*cid.ID
+	for key, cnr := range p.containers {
+		if id != nil && !id.Equal(cnr.OwnerID()) {
+			continue
+		}
+		containerID := cid.New()
+		if err := containerID.Parse(key); err != nil {
+			return nil, err
+		}
+		res = append(res, containerID)
+	}
+
+	return res, nil
+}
+
+// DeleteContainer implements client.Container interface. Objects of
+// the container are deleted too.
+func (p *Pool) DeleteContainer(_ context.Context, id *cid.ID, _ ...client.CallOption) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.containers[id.String()]; !ok {
+		return fmt.Errorf("container not found")
+	}
+
+	delete(p.containers, id.String())
+	delete(p.eacls, id.String())
+	for key, obj := range p.objects {
+		if obj.ContainerID().Equal(id) {
+			delete(p.objects, key)
+		}
+	}
+
+	return nil
+}
+
+// GetEACL implements client.Container interface. Tables stored in the pool
+// aren't signed, so they are returned by GetEACLTable only.
+func (p *Pool) GetEACL(context.Context, *cid.ID, ...client.CallOption) (*client.EACLWithSignature, error) {
+	return nil, errEACLNotSigned
+}
+
+// GetEACLTable returns a copy of the extended ACL table of the container.
+func (p *Pool) GetEACLTable(_ context.Context, id *cid.ID, _ ...client.CallOption) (*eacl.Table, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if _, ok := p.containers[id.String()]; !ok {
+		return nil, fmt.Errorf("container not found")
+	}
+
+	table, ok := p.eacls[id.String()]
+	if !ok {
+		return nil, fmt.Errorf("extended ACL is not set for this container")
+	}
+
+	return copyEACL(table)
+}
+
+// SetEACL implements client.Container interface.
+func (p *Pool) SetEACL(_ context.Context, table *eacl.Table, _ ...client.CallOption) error {
+	stored, err := copyEACL(table)
+	if err != nil {
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if table.CID() == nil || p.containers[table.CID().String()] == nil {
+		return fmt.Errorf("container not found")
+	}
+	p.eacls[table.CID().String()] = stored
+
+	return nil
+}
+
+// AnnounceContainerUsedSpace implements client.Container interface.
+func (p *Pool) AnnounceContainerUsedSpace(context.Context, []container.UsedSpaceAnnouncement, ...client.CallOption) error {
+	return nil
+}
+
+// Connection implements pool.Pool interface. The returned client supports
+// NetworkInfo only.
+func (p *Pool) Connection() (client.Client, *session.Token, error) {
+	return &connection{Pool: p}, nil, nil
+}
+
+// OwnerID implements pool.Pool interface.
+func (p *Pool) OwnerID() *owner.ID {
+	return p.owner
+}
+
+// WaitForContainerPresence implements pool.Pool interface.
+func (p *Pool) WaitForContainerPresence(_ context.Context, id *cid.ID, _ *pool.ContainerPollingParams) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if _, ok := p.containers[id.String()]; !ok {
+		return fmt.Errorf("container not found")
+	}
+
+	return nil
+}
+
+// NetworkInfo returns the current epoch of the pool.
+func (p *Pool) NetworkInfo(context.Context, ...client.CallOption) (*netmap.NetworkInfo, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	info := netmap.NewNetworkInfo()
+	info.SetCurrentEpoch(p.epoch)
+
+	return info, nil
+}
+
+// NetworkInfo implements client.Netmap interface.
+func (c *connection) NetworkInfo(ctx context.Context, opts ...client.CallOption) (*netmap.NetworkInfo, error) {
+	return c.Pool.NetworkInfo(ctx, opts...)
+}
+
+// Raw implements client.Client interface, there is no underlying client.
+func (c *connection) Raw() *rpcclient.Client {
+	return nil
+}
+
+// Conn implements client.Client interface, there is no connection.
+func (c *connection) Conn() io.Closer {
+	return nil
+}
+
+func (p *Pool) object(address *object.Address) (*object.Object, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if obj, ok := p.objects[address.String()]; ok {
+		return obj, nil
+	}
+
+	return nil, fmt.Errorf("object not found")
+}
+
+func randomChecksum() ([sha256.Size]byte, error) {
+	var res [sha256.Size]byte
+	_, err := rand.Read(res[:])
+	return res, err
+}
+
+func newAddress(containerID *cid.ID, id *object.ID) *object.Address {
+	address := object.NewAddress()
+	address.SetContainerID(containerID)
+	address.SetObjectID(id)
+	return address
+}
+
+// copyObject returns a deep copy of the object, so the stored objects aren't
+// changed by callers.
+func copyObject(obj *object.Object) (*object.RawObject, error) {
+	data, err := obj.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	raw := object.NewRaw()
+	if err = raw.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return raw, nil
+}
+
+// copyContainer returns a deep copy of the container.
+func copyContainer(cnr *container.Container) (*container.Container, error) {
+	data, err := cnr.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	res := container.New()
+	if err = res.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// copyEACL returns a deep copy of the extended ACL table.
+func copyEACL(table *eacl.Table) (*eacl.Table, error) {
+	data, err := table.Marshal()
+	if err != nil {
+		return nil, err
+	}
+
+	res := eacl.NewTable()
+	if err = res.Unmarshal(data); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func payloadRange(obj *object.Object, rng *object.Range) ([]byte, error) {
+	payload := obj.Payload()
+	if rng == nil {
+		return payload, nil
+	}
+
+	from, length := rng.GetOffset(), rng.GetLength()
+	if from > uint64(len(payload)) || length > uint64(len(payload))-from {
+		return nil, fmt.Errorf("payload range is out of bounds")
+	}
+
+	return payload[from : from+length], nil
+}
+
+// saltPayload XORs the data with the salt repeated like NeoFS nodes do.
+func saltPayload(data, salt []byte) []byte {
+	res := make([]byte, len(data))
+	for i := range data {
+		res[i] = data[i] ^ salt[i%len(salt)]
+	}
+	return res
+}
+
+func matchFilters(obj *object.Object, filters object.SearchFilters) bool {
+	for _, filter := range filters {
+		switch filter.Header() {
+		case v2object.FilterPropertyRoot, v2object.FilterPropertyPhy:
+			// objects are never split, so all of them are root and physical
+			continue
+		}
+
+		value, ok := headerValue(obj, filter.Header())
+		if !matchFilter(filter, value, ok) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchFilter(filter object.SearchFilter, value string, present bool) bool {
+	switch filter.Operation() {
+	case object.MatchStringEqual:
+		return present && value == filter.Value()
+	case object.MatchStringNotEqual:
+		return present && value != filter.Value()
+	case object.MatchCommonPrefix:
+		return present && strings.HasPrefix(value, filter.Value())
+	case object.MatchNotPresent:
+		return !present
+	default:
+		return false
+	}
+}
+
+// headerValue returns the value of the attribute or of the well-known header
+// in the format used by search filters.
+func headerValue(obj *object.Object, header string) (string, bool) {
+	if !strings.HasPrefix(header, v2object.ReservedFilterPrefix) {
+		for _, attr := range obj.Attributes() {
+			if attr.Key() == header {
+				return attr.Value(), true
+			}
+		}
+		return "", false
+	}
+
+	switch header {
+	case v2object.FilterHeaderObjectID:
+		return obj.ID().String(), true
+	case v2object.FilterHeaderContainerID:
+		return obj.ContainerID().String(), true
+	case v2object.FilterHeaderOwnerID:
+		return obj.OwnerID().String(), true
+	case v2object.FilterHeaderCreationEpoch:
+		return strconv.FormatUint(obj.CreationEpoch(), 10), true
+	case v2object.FilterHeaderPayloadLength:
+		return strconv.FormatUint(obj.PayloadSize(), 10), true
+	case v2object.FilterHeaderPayloadHash:
+		return obj.PayloadChecksum().String(), true
+	case v2object.FilterHeaderObjectType:
+		return obj.Type().String(), true
+	}
+
+	return "", false
+}
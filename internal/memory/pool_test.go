@@ -0,0 +1,188 @@
+package memory
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-api-go/pkg/acl/eacl"
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
+	"github.com/nspcc-dev/neofs-api-go/pkg/container"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestPool(t *testing.T) *Pool {
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	p, err := NewPool(key)
+	require.NoError(t, err)
+	return p
+}
+
+func TestContainers(t *testing.T) {
+	ctx := context.Background()
+	p := newTestPool(t)
+
+	id, err := p.PutContainer(ctx, container.New(container.WithAttribute(container.AttributeName, "bucket")))
+	require.NoError(t, err)
+	require.NoError(t, p.WaitForContainerPresence(ctx, id, nil))
+
+	cnr, err := p.GetContainer(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, p.OwnerID(), cnr.OwnerID())
+
+	// the stored container isn't changed by callers
+	cnr.SetOwnerID(nil)
+	cnr, err = p.GetContainer(ctx, id)
+	require.NoError(t, err)
+	require.Equal(t, p.OwnerID(), cnr.OwnerID())
+
+	ids, err := p.ListContainers(ctx, p.OwnerID())
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+	require.True(t, ids[0].Equal(id))
+
+	_, err = p.GetEACLTable(ctx, id)
+	require.Error(t, err)
+
+	table := eacl.NewTable()
+	table.SetCID(id)
+	record := eacl.NewRecord()
+	record.SetOperation(eacl.OperationGet)
+	record.SetAction(eacl.ActionDeny)
+	eacl.AddFormedTarget(record, eacl.RoleOthers)
+	table.AddRecord(record)
+	require.NoError(t, p.SetEACL(ctx, table))
+
+	_, err = p.GetEACL(ctx, id)
+	require.ErrorIs(t, err, errEACLNotSigned)
+
+	stored, err := p.GetEACLTable(ctx, id)
+	require.NoError(t, err)
+	expected, err := table.Marshal()
+	require.NoError(t, err)
+	actual, err := stored.Marshal()
+	require.NoError(t, err)
+	require.Equal(t, expected, actual)
+
+	obj := object.NewRaw()
+	obj.SetContainerID(id)
+	_, err = p.PutObject(ctx, new(client.PutObjectParams).WithObject(obj.Object()))
+	require.NoError(t, err)
+
+	require.NoError(t, p.DeleteContainer(ctx, id))
+	_, err = p.GetContainer(ctx, id)
+	require.Error(t, err)
+	require.Empty(t, p.objects)
+	require.Empty(t, p.eacls)
+
+	_, err = p.PutObject(ctx, new(client.PutObjectParams).WithObject(obj.Object()))
+	require.Error(t, err)
+}
+
+func TestObjects(t *testing.T) {
+	ctx := context.Background()
+	p := newTestPool(t)
+
+	cnrID, err := p.PutContainer(ctx, container.New())
+	require.NoError(t, err)
+
+	put := func(name string, payload []byte) *object.Address {
+		attr := object.NewAttribute()
+		attr.SetKey(object.AttributeFileName)
+		attr.SetValue(name)
+
+		obj := object.NewRaw()
+		obj.SetContainerID(cnrID)
+		obj.SetAttributes(attr)
+
+		id, err := p.PutObject(ctx, new(client.PutObjectParams).WithObject(obj.Object()).WithPayloadReader(bytes.NewReader(payload)))
+		require.NoError(t, err)
+		return newAddress(cnrID, id)
+	}
+
+	addr1 := put("dir/obj1", []byte("content"))
+	addr2 := put("obj2", []byte("other content"))
+
+	head, err := p.GetObjectHeader(ctx, new(client.ObjectHeaderParams).WithAddress(addr1))
+	require.NoError(t, err)
+	require.Empty(t, head.Payload())
+	require.EqualValues(t, 7, head.PayloadSize())
+	require.Equal(t, p.OwnerID(), head.OwnerID())
+	require.NotNil(t, head.PayloadChecksum())
+
+	head2, err := p.GetObjectHeader(ctx, new(client.ObjectHeaderParams).WithAddress(addr2))
+	require.NoError(t, err)
+	require.Greater(t, head2.CreationEpoch(), head.CreationEpoch())
+
+	info, err := p.NetworkInfo(ctx)
+	require.NoError(t, err)
+	require.Greater(t, info.CurrentEpoch(), head2.CreationEpoch())
+
+	buf := new(bytes.Buffer)
+	obj, err := p.GetObject(ctx, new(client.GetObjectParams).WithAddress(addr1).WithPayloadWriter(buf))
+	require.NoError(t, err)
+	require.Equal(t, "content", buf.String())
+	require.Equal(t, "content", string(obj.Payload()))
+
+	// the stored object isn't changed by callers
+	object.NewRawFrom(obj).SetAttributes()
+	obj, err = p.GetObject(ctx, new(client.GetObjectParams).WithAddress(addr1))
+	require.NoError(t, err)
+	require.Len(t, obj.Attributes(), 1)
+
+	rng := object.NewRange()
+	rng.SetOffset(2)
+	rng.SetLength(3)
+	data, err := p.ObjectPayloadRangeData(ctx, new(client.RangeDataParams).WithAddress(addr1).WithRange(rng))
+	require.NoError(t, err)
+	require.Equal(t, "nte", string(data))
+
+	rng.SetLength(10)
+	_, err = p.ObjectPayloadRangeData(ctx, new(client.RangeDataParams).WithAddress(addr1).WithRange(rng))
+	require.Error(t, err)
+
+	search := func(filters object.SearchFilters) int {
+		ids, err := p.SearchObject(ctx, new(client.SearchObjectParams).WithContainerID(cnrID).WithSearchFilters(filters))
+		require.NoError(t, err)
+		return len(ids)
+	}
+
+	var filters object.SearchFilters
+	filters.AddRootFilter()
+	require.Equal(t, 2, search(filters))
+	filters.AddFilter(object.AttributeFileName, "dir/", object.MatchCommonPrefix)
+	require.Equal(t, 1, search(filters))
+
+	filters = nil
+	filters.AddObjectOwnerIDFilter(object.MatchStringEqual, p.OwnerID())
+	require.Equal(t, 2, search(filters))
+	filters.AddFilter(object.AttributeTimestamp, "", object.MatchNotPresent)
+	require.Equal(t, 2, search(filters))
+	filters.AddFilter(object.AttributeFileName, "obj2", object.MatchStringNotEqual)
+	require.Equal(t, 1, search(filters))
+
+	require.NoError(t, p.DeleteObject(ctx, new(client.DeleteObjectParams).WithAddress(addr1)))
+	_, err = p.GetObject(ctx, new(client.GetObjectParams).WithAddress(addr1))
+	require.Error(t, err)
+}
+
+func TestConnection(t *testing.T) {
+	ctx := context.Background()
+	p := newTestPool(t)
+
+	conn, _, err := p.Connection()
+	require.NoError(t, err)
+	require.Nil(t, conn.Conn())
+
+	id, err := conn.PutContainer(ctx, container.New())
+	require.NoError(t, err)
+	_, err = p.GetContainer(ctx, id)
+	require.NoError(t, err)
+
+	info, err := conn.NetworkInfo(ctx)
+	require.NoError(t, err)
+	require.EqualValues(t, 1, info.CurrentEpoch())
+}
@@ -0,0 +1,29 @@
+package neofs
+
+import (
+	"context"
+
+	"github.com/nspcc-dev/neofs-api-go/pkg/acl/eacl"
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
+	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+)
+
+// EACLSource is implemented by pools which return eACL tables without
+// signatures, e.g. the ones which don't sign tables at all.
+type EACLSource interface {
+	GetEACLTable(context.Context, *cid.ID, ...client.CallOption) (*eacl.Table, error)
+}
+
+// GetEACL returns the eACL table of the container with the pool.
+func GetEACL(ctx context.Context, p pool.Pool, id *cid.ID, opts ...client.CallOption) (*eacl.Table, error) {
+	if src, ok := p.(EACLSource); ok {
+		return src.GetEACLTable(ctx, id, opts...)
+	}
+
+	signed, err := p.GetEACL(ctx, id, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return signed.EACL(), nil
+}
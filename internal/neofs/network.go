@@ -0,0 +1,30 @@
+// Package neofs contains helpers for NeoFS connection pools.
+package neofs
+
+import (
+	"context"
+
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
+	"github.com/nspcc-dev/neofs-api-go/pkg/netmap"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+)
+
+// NetworkInfoSource is implemented by pools which request the network info
+// themselves, so the request is made like other calls of the pool.
+type NetworkInfoSource interface {
+	NetworkInfo(context.Context, ...client.CallOption) (*netmap.NetworkInfo, error)
+}
+
+// NetworkInfo requests the network info with the pool. If the pool can't do
+// it, a connection of the pool is used.
+func NetworkInfo(ctx context.Context, p pool.Pool, opts ...client.CallOption) (*netmap.NetworkInfo, error) {
+	if src, ok := p.(NetworkInfoSource); ok {
+		return src.NetworkInfo(ctx, opts...)
+	}
+
+	conn, _, err := p.Connection()
+	if err != nil {
+		return nil, err
+	}
+	return conn.NetworkInfo(ctx, opts...)
+}
@@ -0,0 +1,199 @@
+package tracing
+
+import (
+	"context"
+	"crypto/sha256"
+	"io"
+
+	"github.com/nspcc-dev/neofs-api-go/pkg/acl/eacl"
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
+	"github.com/nspcc-dev/neofs-api-go/pkg/container"
+	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/nspcc-dev/neofs-api-go/pkg/netmap"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"github.com/nspcc-dev/neofs-api-go/pkg/owner"
+	"github.com/nspcc-dev/neofs-api-go/pkg/session"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/neofs"
+	"github.com/nspcc-dev/neofs-sdk-go/pool"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+// tracedPool is a pool.Pool which makes a span for every NeoFS call. The calls
+// are made with the methods of the wrapped pool, so its behaviour is kept.
+type tracedPool struct {
+	pool pool.Pool
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// WrapPool returns pool.Pool which traces calls to p.
+func WrapPool(p pool.Pool) pool.Pool {
+	return &tracedPool{pool: p}
+}
+
+func (t *tracedPool) PutObject(ctx context.Context, params *client.PutObjectParams, opts ...client.CallOption) (*object.ID, error) {
+	var attrs []attribute.KeyValue
+	if obj := params.Object(); obj != nil {
+		attrs = append(attrs, Container(obj.ContainerID()))
+	}
+
+	ctx, span := StartSpan(ctx, "neofs.PutObject", attrs...)
+
+	reader := &countingReader{r: params.PayloadReader()}
+	if reader.r != nil {
+		params.WithPayloadReader(reader)
+	}
+
+	id, err := t.pool.PutObject(ctx, params, opts...)
+	span.SetAttributes(AttrBytes.Int64(reader.n), Object(id))
+	EndSpan(span, err)
+	return id, err
+}
+
+func (t *tracedPool) DeleteObject(ctx context.Context, params *client.DeleteObjectParams, opts ...client.CallOption) error {
+	ctx, span := StartSpan(ctx, "neofs.DeleteObject", Address(params.Address())...)
+	err := t.pool.DeleteObject(ctx, params, opts...)
+	EndSpan(span, err)
+	return err
+}
+
+func (t *tracedPool) GetObject(ctx context.Context, params *client.GetObjectParams, opts ...client.CallOption) (*object.Object, error) {
+	ctx, span := StartSpan(ctx, "neofs.GetObject", Address(params.Address())...)
+	obj, err := t.pool.GetObject(ctx, params, opts...)
+	if obj != nil {
+		span.SetAttributes(AttrBytes.Int64(int64(obj.PayloadSize())))
+	}
+	EndSpan(span, err)
+	return obj, err
+}
+
+func (t *tracedPool) GetObjectHeader(ctx context.Context, params *client.ObjectHeaderParams, opts ...client.CallOption) (*object.Object, error) {
+	ctx, span := StartSpan(ctx, "neofs.GetObjectHeader", Address(params.Address())...)
+	obj, err := t.pool.GetObjectHeader(ctx, params, opts...)
+	EndSpan(span, err)
+	return obj, err
+}
+
+func (t *tracedPool) ObjectPayloadRangeData(ctx context.Context, params *client.RangeDataParams, opts ...client.CallOption) ([]byte, error) {
+	attrs := Address(params.Address())
+	if rng := params.Range(); rng != nil {
+		attrs = append(attrs, AttrBytes.Int64(int64(rng.GetLength())))
+	}
+
+	ctx, span := StartSpan(ctx, "neofs.ObjectPayloadRangeData", attrs...)
+	data, err := t.pool.ObjectPayloadRangeData(ctx, params, opts...)
+	EndSpan(span, err)
+	return data, err
+}
+
+func (t *tracedPool) ObjectPayloadRangeSHA256(ctx context.Context, params *client.RangeChecksumParams, opts ...client.CallOption) ([][sha256.Size]byte, error) {
+	ctx, span := StartSpan(ctx, "neofs.ObjectPayloadRangeSHA256", Address(params.Address())...)
+	res, err := t.pool.ObjectPayloadRangeSHA256(ctx, params, opts...)
+	EndSpan(span, err)
+	return res, err
+}
+
+func (t *tracedPool) ObjectPayloadRangeTZ(ctx context.Context, params *client.RangeChecksumParams, opts ...client.CallOption) ([][client.TZSize]byte, error) {
+	ctx, span := StartSpan(ctx, "neofs.ObjectPayloadRangeTZ", Address(params.Address())...)
+	res, err := t.pool.ObjectPayloadRangeTZ(ctx, params, opts...)
+	EndSpan(span, err)
+	return res, err
+}
+
+func (t *tracedPool) SearchObject(ctx context.Context, params *client.SearchObjectParams, opts ...client.CallOption) ([]*object.ID, error) {
+	ctx, span := StartSpan(ctx, "neofs.SearchObject", Container(params.ContainerID()))
+	ids, err := t.pool.SearchObject(ctx, params, opts...)
+	span.SetAttributes(attribute.Int("neofs.found", len(ids)))
+	EndSpan(span, err)
+	return ids, err
+}
+
+func (t *tracedPool) PutContainer(ctx context.Context, cnr *container.Container, opts ...client.CallOption) (*cid.ID, error) {
+	ctx, span := StartSpan(ctx, "neofs.PutContainer")
+	id, err := t.pool.PutContainer(ctx, cnr, opts...)
+	span.SetAttributes(Container(id))
+	EndSpan(span, err)
+	return id, err
+}
+
+func (t *tracedPool) GetContainer(ctx context.Context, id *cid.ID, opts ...client.CallOption) (*container.Container, error) {
+	ctx, span := StartSpan(ctx, "neofs.GetContainer", Container(id))
+	cnr, err := t.pool.GetContainer(ctx, id, opts...)
+	EndSpan(span, err)
+	return cnr, err
+}
+
+func (t *tracedPool) ListContainers(ctx context.Context, ownerID *owner.ID, opts ...client.CallOption) ([]*cid.ID, error) {
+	ctx, span := StartSpan(ctx, "neofs.ListContainers")
+	ids, err := t.pool.ListContainers(ctx, ownerID, opts...)
+	EndSpan(span, err)
+	return ids, err
+}
+
+func (t *tracedPool) DeleteContainer(ctx context.Context, id *cid.ID, opts ...client.CallOption) error {
+	ctx, span := StartSpan(ctx, "neofs.DeleteContainer", Container(id))
+	err := t.pool.DeleteContainer(ctx, id, opts...)
+	EndSpan(span, err)
+	return err
+}
+
+func (t *tracedPool) GetEACL(ctx context.Context, id *cid.ID, opts ...client.CallOption) (*client.EACLWithSignature, error) {
+	ctx, span := StartSpan(ctx, "neofs.GetEACL", Container(id))
+	table, err := t.pool.GetEACL(ctx, id, opts...)
+	EndSpan(span, err)
+	return table, err
+}
+
+// GetEACLTable requests the eACL table with the wrapped pool.
+func (t *tracedPool) GetEACLTable(ctx context.Context, id *cid.ID, opts ...client.CallOption) (*eacl.Table, error) {
+	ctx, span := StartSpan(ctx, "neofs.GetEACL", Container(id))
+	table, err := neofs.GetEACL(ctx, t.pool, id, opts...)
+	EndSpan(span, err)
+	return table, err
+}
+
+func (t *tracedPool) SetEACL(ctx context.Context, table *eacl.Table, opts ...client.CallOption) error {
+	ctx, span := StartSpan(ctx, "neofs.SetEACL", Container(table.CID()))
+	err := t.pool.SetEACL(ctx, table, opts...)
+	EndSpan(span, err)
+	return err
+}
+
+func (t *tracedPool) AnnounceContainerUsedSpace(ctx context.Context, announce []container.UsedSpaceAnnouncement, opts ...client.CallOption) error {
+	ctx, span := StartSpan(ctx, "neofs.AnnounceContainerUsedSpace")
+	err := t.pool.AnnounceContainerUsedSpace(ctx, announce, opts...)
+	EndSpan(span, err)
+	return err
+}
+
+// NetworkInfo requests the network info with the wrapped pool.
+func (t *tracedPool) NetworkInfo(ctx context.Context, opts ...client.CallOption) (*netmap.NetworkInfo, error) {
+	ctx, span := StartSpan(ctx, "neofs.NetworkInfo")
+	info, err := neofs.NetworkInfo(ctx, t.pool, opts...)
+	EndSpan(span, err)
+	return info, err
+}
+
+func (t *tracedPool) Connection() (client.Client, *session.Token, error) {
+	return t.pool.Connection()
+}
+
+func (t *tracedPool) OwnerID() *owner.ID {
+	return t.pool.OwnerID()
+}
+
+func (t *tracedPool) WaitForContainerPresence(ctx context.Context, id *cid.ID, params *pool.ContainerPollingParams) error {
+	ctx, span := StartSpan(ctx, "neofs.WaitForContainerPresence", Container(id))
+	err := t.pool.WaitForContainerPresence(ctx, id, params)
+	EndSpan(span, err)
+	return err
+}
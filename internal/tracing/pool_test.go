@@ -0,0 +1,59 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nspcc-dev/neo-go/pkg/crypto/keys"
+	"github.com/nspcc-dev/neofs-api-go/pkg/client"
+	"github.com/nspcc-dev/neofs-api-go/pkg/container"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"github.com/nspcc-dev/neofs-api-go/pkg/session"
+	"github.com/nspcc-dev/neofs-s3-gw/internal/memory"
+	"github.com/stretchr/testify/require"
+)
+
+// noConnectionPool serves calls with its own methods only.
+type noConnectionPool struct {
+	*memory.Pool
+}
+
+func (noConnectionPool) Connection() (client.Client, *session.Token, error) {
+	return nil, nil, errors.New("no connection")
+}
+
+func TestWrapPoolCallsPool(t *testing.T) {
+	ctx := context.Background()
+
+	key, err := keys.NewPrivateKey()
+	require.NoError(t, err)
+	mem, err := memory.NewPool(key)
+	require.NoError(t, err)
+
+	p := WrapPool(noConnectionPool{Pool: mem})
+
+	id, err := p.PutContainer(ctx, container.New())
+	require.NoError(t, err)
+
+	raw := object.NewRaw()
+	raw.SetContainerID(id)
+	oid, err := p.PutObject(ctx, new(client.PutObjectParams).
+		WithObject(raw.Object()).
+		WithPayloadReader(bytes.NewReader([]byte("payload"))))
+	require.NoError(t, err)
+
+	address := object.NewAddress()
+	address.SetContainerID(id)
+	address.SetObjectID(oid)
+
+	buf := new(bytes.Buffer)
+	_, err = p.GetObject(ctx, new(client.GetObjectParams).WithAddress(address).WithPayloadWriter(buf))
+	require.NoError(t, err)
+	require.Equal(t, "payload", buf.String())
+
+	ids, err := p.SearchObject(ctx, new(client.SearchObjectParams).WithContainerID(id))
+	require.NoError(t, err)
+	require.Len(t, ids, 1)
+}
@@ -0,0 +1,70 @@
+package tracing
+
+import (
+	"context"
+
+	cid "github.com/nspcc-dev/neofs-api-go/pkg/container/id"
+	"github.com/nspcc-dev/neofs-api-go/pkg/object"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/nspcc-dev/neofs-s3-gw"
+
+// Span attributes of NeoFS calls.
+const (
+	AttrContainerID = attribute.Key("neofs.container_id")
+	AttrObjectID    = attribute.Key("neofs.object_id")
+	AttrBytes       = attribute.Key("neofs.bytes")
+
+	AttrBucket    = attribute.Key("s3.bucket")
+	AttrObject    = attribute.Key("s3.object")
+	AttrVersionID = attribute.Key("s3.version_id")
+)
+
+// StartSpan starts a new internal span using the global tracer provider.
+// If tracing is disabled, the span is a no-op.
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// StartServerSpan starts a span of incoming request.
+func StartServerSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(attrs...))
+}
+
+// EndSpan records an error if any and ends the span.
+func EndSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Container returns container ID attribute.
+func Container(id *cid.ID) attribute.KeyValue {
+	if id == nil {
+		return AttrContainerID.String("")
+	}
+	return AttrContainerID.String(id.String())
+}
+
+// Object returns object ID attribute.
+func Object(id *object.ID) attribute.KeyValue {
+	if id == nil {
+		return AttrObjectID.String("")
+	}
+	return AttrObjectID.String(id.String())
+}
+
+// Address returns container and object ID attributes.
+func Address(addr *object.Address) []attribute.KeyValue {
+	if addr == nil {
+		return nil
+	}
+	return []attribute.KeyValue{Container(addr.ContainerID()), Object(addr.ObjectID())}
+}